@@ -2,29 +2,29 @@ package app
 
 import (
 	"encoding/json"
-	"fmt"
 	"os"
 
 	"github.com/corestario/marketplace/common"
 	"github.com/corestario/marketplace/x/marketplace"
-	"github.com/corestario/marketplace/x/marketplace/config"
 	bam "github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/crisis"
 	distr "github.com/cosmos/cosmos-sdk/x/distribution"
 	"github.com/cosmos/cosmos-sdk/x/genutil"
+	"github.com/cosmos/cosmos-sdk/x/gov"
 	"github.com/cosmos/cosmos-sdk/x/ibc"
 	ibctransfer "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer"
 	"github.com/cosmos/cosmos-sdk/x/mint"
 	"github.com/cosmos/cosmos-sdk/x/params"
+	paramsclient "github.com/cosmos/cosmos-sdk/x/params/client"
 	"github.com/cosmos/cosmos-sdk/x/slashing"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/supply"
 	"github.com/cosmos/modules/incubator/nft"
-	"github.com/spf13/viper"
 	abci "github.com/tendermint/tendermint/abci/types"
 	cmn "github.com/tendermint/tendermint/libs/common"
 	"github.com/tendermint/tendermint/libs/log"
@@ -50,7 +50,9 @@ var (
 		staking.AppModuleBasic{},
 		distr.AppModuleBasic{},
 		slashing.AppModuleBasic{},
+		gov.NewAppModuleBasic(paramsclient.ProposalHandler),
 		supply.AppModuleBasic{},
+		crisis.AppModuleBasic{},
 		nft.AppModuleBasic{},
 		ibc.AppModuleBasic{},
 
@@ -83,6 +85,7 @@ type marketplaceApp struct {
 	keyParams   *sdk.KVStoreKey
 	tkeyParams  *sdk.TransientStoreKey
 	keySlashing *sdk.KVStoreKey
+	keyGov      *sdk.KVStoreKey
 	keyIBC      *sdk.KVStoreKey
 
 	// Keepers
@@ -92,7 +95,9 @@ type marketplaceApp struct {
 	stakingKeeper  staking.Keeper
 	slashingKeeper slashing.Keeper
 	distrKeeper    distr.Keeper
+	govKeeper      gov.Keeper
 	paramsKeeper   params.Keeper
+	crisisKeeper   crisis.Keeper
 	nftKeeper      *nft.Keeper
 	ibcKeeper      ibc.Keeper
 
@@ -102,13 +107,15 @@ type marketplaceApp struct {
 	keyRegisterCurrency *sdk.KVStoreKey
 	keyAuction          *sdk.KVStoreKey
 	keyDeletedNFT       *sdk.KVStoreKey
+	keyOffer            *sdk.KVStoreKey
 
 	// Module Manager
 	mm *module.Manager
 }
 
-// NewMarketplaceApp is a constructor function for marketplaceApp
-func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam.BaseApp)) *marketplaceApp {
+// NewMarketplaceApp is a constructor function for marketplaceApp. The registered invariants
+// are asserted every invCheckPeriod blocks, never if it is 0.
+func NewMarketplaceApp(logger log.Logger, db dbm.DB, invCheckPeriod uint, baseAppOptions ...func(*bam.BaseApp)) *marketplaceApp {
 
 	// First define the top level codec that will be shared by the different modules
 	cdc := MakeCodec()
@@ -132,12 +139,14 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		keyParams:   sdk.NewKVStoreKey(params.StoreKey),
 		tkeyParams:  sdk.NewTransientStoreKey(params.TStoreKey),
 		keySlashing: sdk.NewKVStoreKey(slashing.StoreKey),
+		keyGov:      sdk.NewKVStoreKey(gov.StoreKey),
 		keyIBC:      sdk.NewKVStoreKey(ibc.StoreKey),
 
 		keyMP:               sdk.NewKVStoreKey(marketplace.StoreKey),
 		keyRegisterCurrency: sdk.NewKVStoreKey(marketplace.RegisterCurrencyKey),
 		keyAuction:          sdk.NewKVStoreKey(marketplace.AuctionKey),
 		keyDeletedNFT:       sdk.NewKVStoreKey(marketplace.DeletedNFTKey),
+		keyOffer:            sdk.NewKVStoreKey(marketplace.OfferKey),
 	}
 
 	// The ParamsKeeper handles parameter storage for the application
@@ -148,6 +157,9 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 	stakingSubspace := app.paramsKeeper.Subspace(staking.DefaultParamspace)
 	distrSubspace := app.paramsKeeper.Subspace(distr.DefaultParamspace)
 	slashingSubspace := app.paramsKeeper.Subspace(slashing.DefaultParamspace)
+	govSubspace := app.paramsKeeper.Subspace(gov.DefaultParamspace).WithKeyTable(gov.ParamKeyTable())
+	crisisSubspace := app.paramsKeeper.Subspace(crisis.DefaultParamspace)
+	marketplaceSubspace := app.paramsKeeper.Subspace(marketplace.DefaultParamspace)
 
 	// The AccountKeeper handles address -> account lookups
 	app.accountKeeper = auth.NewAccountKeeper(
@@ -162,7 +174,8 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		app.accountKeeper,
 		bankSubspace,
 		bank.DefaultCodespace,
-		nil, // TODO: maybe we should do something about those blacklisted addresses.
+		// coins sent directly to the escrow account would not belong to any bid or offer
+		map[string]bool{supply.NewModuleAddress(marketplace.ModuleName).String(): true},
 	)
 
 	maccPerms := map[string][]string{
@@ -173,11 +186,15 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		staking.BondedPoolName:             {supply.Burner, supply.Staking},
 		staking.NotBondedPoolName:          {supply.Burner, supply.Staking},
 		bank.ModuleName:                    {supply.Minter, supply.Burner, supply.Staking},
+		gov.ModuleName:                     {supply.Burner},
 		ibctransfer.GetModuleAccountName(): {supply.Minter, supply.Burner},
+		marketplace.ModuleName:             nil,
 	}
 	app.supplyKeeper = supply.NewKeeper(app.cdc, app.keySupply, app.accountKeeper,
 		app.bankKeeper, maccPerms)
 
+	app.crisisKeeper = crisis.NewKeeper(crisisSubspace, invCheckPeriod, app.supplyKeeper, auth.FeeCollectorName)
+
 	// The staking keeper
 	stakingKeeper := staking.NewKeeper(
 		app.cdc,
@@ -226,9 +243,6 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 			app.slashingKeeper.Hooks()),
 	)
 
-	srvCfg := ReadSrvConfig()
-	fmt.Printf("Server Config: \n %+v \n", srvCfg)
-
 	app.mpKeeper = marketplace.NewKeeper(
 		app.bankKeeper,
 		app.stakingKeeper,
@@ -237,8 +251,9 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		app.keyRegisterCurrency,
 		app.keyAuction,
 		app.keyDeletedNFT,
+		app.keyOffer,
 		app.cdc,
-		srvCfg,
+		marketplaceSubspace,
 		common.NewPrometheusMsgMetrics("marketplace"),
 		app.nftKeeper,
 		&app.supplyKeeper,
@@ -246,6 +261,21 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		&app.ibcKeeper,
 	)
 
+	// marketplace params can only be changed through parameter change proposals,
+	// which are rejected if they leave the marketplace params invalid
+	govRouter := gov.NewRouter()
+	govRouter.AddRoute(gov.RouterKey, gov.ProposalHandler).
+		AddRoute(params.RouterKey, marketplace.NewParamChangeProposalHandler(app.mpKeeper, app.paramsKeeper))
+	app.govKeeper = gov.NewKeeper(
+		app.cdc,
+		app.keyGov,
+		govSubspace,
+		app.supplyKeeper,
+		&stakingKeeper,
+		gov.DefaultCodespace,
+		govRouter,
+	)
+
 	overriddenNFTModule := marketplace.NewNFTModuleMarketplace(nftModule, app.nftKeeper, app.mpKeeper)
 	overriddenIBCModule := marketplace.NewIBCModuleMarketplace(ibcModule, &app.ibcKeeper, app.mpKeeper)
 
@@ -257,6 +287,8 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		distr.NewAppModule(app.distrKeeper, app.supplyKeeper),
 		slashing.NewAppModule(app.slashingKeeper, app.stakingKeeper),
 		staking.NewAppModule(app.stakingKeeper, app.accountKeeper, app.supplyKeeper),
+		gov.NewAppModule(app.govKeeper, app.supplyKeeper),
+		crisis.NewAppModule(&app.crisisKeeper),
 
 		marketplace.NewAppModule(app.mpKeeper, app.bankKeeper, app.nftKeeper),
 		overriddenNFTModule,
@@ -264,7 +296,7 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 	)
 
 	app.mm.SetOrderBeginBlockers(distr.ModuleName, slashing.ModuleName)
-	app.mm.SetOrderEndBlockers(staking.ModuleName)
+	app.mm.SetOrderEndBlockers(gov.ModuleName, staking.ModuleName, marketplace.ModuleName, crisis.ModuleName)
 
 	// Sets the order of Genesis - Order matters, genutil is to always come last
 	app.mm.SetOrderInitGenesis(
@@ -273,13 +305,19 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		auth.ModuleName,
 		bank.ModuleName,
 		slashing.ModuleName,
+		gov.ModuleName,
+		supply.ModuleName,
 		nft.ModuleName,
 
 		marketplace.ModuleName,
 
+		// the crisis module asserts the invariants once the state of the other modules is initialized
+		crisis.ModuleName,
 		genutil.ModuleName,
 	)
 
+	// register the invariants of all modules, the crisis module asserts them
+	app.mm.RegisterInvariants(&app.crisisKeeper)
 	// register all module routes and module queriers
 	app.mm.RegisterRoutes(app.Router(), app.QueryRouter())
 
@@ -303,6 +341,7 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		app.keyDistr,
 		app.tkeyDistr,
 		app.keySlashing,
+		app.keyGov,
 		app.keyNFT,
 		app.keyParams,
 		app.tkeyParams,
@@ -312,6 +351,7 @@ func NewMarketplaceApp(logger log.Logger, db dbm.DB, baseAppOptions ...func(*bam
 		app.keyAuction,
 		app.keyIBC,
 		app.keyDeletedNFT,
+		app.keyOffer,
 	)
 
 	err := app.LoadLatestVersion(app.keyMain)
@@ -347,7 +387,6 @@ func (app *marketplaceApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBl
 	return app.mm.BeginBlock(ctx, req)
 }
 func (app *marketplaceApp) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
-	app.mpKeeper.CheckFinishedAuctions(ctx)
 	return app.mm.EndBlock(ctx, req)
 }
 func (app *marketplaceApp) LoadHeight(height int64) error {
@@ -372,22 +411,3 @@ func (app *marketplaceApp) ExportAppStateAndValidators(forZeroHeight bool, jailW
 
 	return appState, validators, nil
 }
-
-func ReadSrvConfig() *config.MPServerConfig {
-	var cfg *config.MPServerConfig
-	vCfg := viper.New()
-	vCfg.SetConfigName("server")
-	vCfg.AddConfigPath(DefaultNodeHome + "/config")
-	err := vCfg.ReadInConfig()
-	if err != nil {
-		fmt.Println("ERROR: server config file not found, error:", err)
-		return config.DefaultMPServerConfig()
-	}
-	fmt.Println(vCfg.GetString("maximum_beneficiary_commission"))
-	err = vCfg.Unmarshal(&cfg)
-	if err != nil {
-		fmt.Println("ERROR: could not unmarshal server config file, error:", err)
-		return config.DefaultMPServerConfig()
-	}
-	return cfg
-}
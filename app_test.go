@@ -0,0 +1,177 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authexported "github.com/cosmos/cosmos-sdk/x/auth/exported"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const testChainID = "marketplace-test"
+
+type testAccount struct {
+	key  crypto.PrivKey
+	addr sdk.AccAddress
+	seq  uint64
+}
+
+func newTestAccount(seed string) *testAccount {
+	key := secp256k1.GenPrivKeySecp256k1([]byte(seed))
+	return &testAccount{key: key, addr: sdk.AccAddress(key.PubKey().Address())}
+}
+
+type testBlock struct {
+	time time.Time
+	txs  [][]byte
+}
+
+// testNode is a single validator's copy of the application state machine.
+type testNode struct {
+	app *marketplaceApp
+}
+
+func newTestNode(t *testing.T, accounts []*testAccount, coins sdk.Coins) *testNode {
+	// the invariants of all modules are asserted at the end of every block
+	app := NewMarketplaceApp(log.NewNopLogger(), dbm.NewMemDB(), 1)
+
+	var genAccounts authexported.GenesisAccounts
+	for i, acc := range accounts {
+		genAccounts = append(genAccounts, auth.NewBaseAccount(acc.addr, coins, nil, uint64(i), 0))
+	}
+	genesisState := NewDefaultGenesisState()
+	genesisState[auth.ModuleName] = app.cdc.MustMarshalJSON(auth.NewGenesisState(auth.DefaultParams(), genAccounts))
+	mpGenesis := marketplace.DefaultGenesisState()
+	// auctions in these tests last less than the default minimum duration
+	mpGenesis.Params.MinAuctionDuration = 0
+	genesisState[marketplace.ModuleName] = app.cdc.MustMarshalJSON(mpGenesis)
+	stateBytes, err := app.cdc.MarshalJSONIndent(genesisState, "", " ")
+	require.Nil(t, err)
+
+	app.InitChain(abci.RequestInitChain{ChainId: testChainID, AppStateBytes: stateBytes})
+	return &testNode{app: app}
+}
+
+func (n *testNode) signTx(acc *testAccount, msgs ...sdk.Msg) []byte {
+	ctx := n.app.NewContext(false, abci.Header{ChainID: testChainID})
+	accNum := n.app.accountKeeper.GetAccount(ctx, acc.addr).GetAccountNumber()
+
+	fee := auth.NewStdFee(1000000, sdk.Coins{})
+	sig, err := acc.key.Sign(auth.StdSignBytes(testChainID, accNum, acc.seq, fee, msgs, ""))
+	if err != nil {
+		panic(err)
+	}
+	acc.seq++
+
+	tx := auth.NewStdTx(msgs, fee, []auth.StdSignature{{PubKey: acc.key.PubKey(), Signature: sig}}, "")
+	return n.app.cdc.MustMarshalBinaryLengthPrefixed(tx)
+}
+
+// run executes the blocks and returns the app hash and the tx result codes of every block.
+func (n *testNode) run(blocks []testBlock) (hashes [][]byte, codes [][]uint32) {
+	for i, block := range blocks {
+		height := int64(i + 1)
+		n.app.BeginBlock(abci.RequestBeginBlock{
+			Header: abci.Header{ChainID: testChainID, Height: height, Time: block.time},
+		})
+		var blockCodes []uint32
+		for _, tx := range block.txs {
+			blockCodes = append(blockCodes, n.app.DeliverTx(abci.RequestDeliverTx{Tx: tx}).Code)
+		}
+		n.app.EndBlock(abci.RequestEndBlock{Height: height})
+		hashes = append(hashes, n.app.Commit().Data)
+		codes = append(codes, blockCodes)
+	}
+	return hashes, codes
+}
+
+// TestAppHashIndependentOfLocalClock replays the same blocks on two nodes whose local
+// clocks lie on different sides of an auction deadline and checks that they agree on
+// every tx result and app hash.
+func TestAppHashIndependentOfLocalClock(t *testing.T) {
+	const localClockSkew = time.Second
+
+	seller, bidder := newTestAccount("seller"), newTestAccount("bidder")
+	accounts := []*testAccount{seller, bidder}
+	coins := sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(1000)))
+
+	nodeA := newTestNode(t, accounts, coins)
+	nodeB := newTestNode(t, accounts, coins)
+
+	blockTime := time.Now().UTC()
+	// the deadline is still ahead on the node A clock and already passed on the node B clock
+	expirationTime := blockTime.Add(localClockSkew / 2)
+	tokenID := "clock-skew-token"
+	price := sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(100)))
+	bid := sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(500)))
+
+	blocks := []testBlock{
+		{
+			time: blockTime,
+			txs: [][]byte{
+				nodeA.signTx(seller, nft.NewMsgMintNFT(seller.addr, seller.addr, tokenID, "clock", "")),
+				nodeA.signTx(seller, types.NewMsgPutNFTOnAuction(seller.addr, seller.addr, tokenID,
+					price, sdk.Coins{}, expirationTime)),
+			},
+		},
+		{
+			time: blockTime.Add(time.Millisecond),
+			txs: [][]byte{
+				nodeA.signTx(bidder, types.NewMsgMakeBidOnAuction(bidder.addr, bidder.addr, tokenID, bid,
+					types.DefaultParams().BeneficiariesCommission)),
+			},
+		},
+		{
+			time: expirationTime.Add(time.Minute),
+		},
+	}
+
+	hashesA, codesA := nodeA.run(blocks)
+	time.Sleep(time.Until(blockTime.Add(localClockSkew)))
+	hashesB, codesB := nodeB.run(blocks)
+
+	for _, blockCodes := range codesA {
+		for _, code := range blockCodes {
+			require.Equal(t, uint32(0), code)
+		}
+	}
+	require.Equal(t, codesA, codesB)
+	require.Equal(t, hashesA, hashesB)
+
+	for _, node := range []*testNode{nodeA, nodeB} {
+		token, err := node.app.mpKeeper.GetNFT(node.app.NewContext(true, abci.Header{}), tokenID)
+		require.Nil(t, err)
+		require.True(t, token.Owner.Equals(bidder.addr))
+		require.True(t, token.TimeCreated.Equal(blockTime))
+	}
+}
+
+// TestGovernanceChangesMarketplaceParams executes a parameter change proposal the way the gov
+// module does once the proposal passes.
+func TestGovernanceChangesMarketplaceParams(t *testing.T) {
+	node := newTestNode(t, nil, nil)
+	ctx := node.app.NewContext(false, abci.Header{ChainID: testChainID})
+	expected := node.app.mpKeeper.GetParams(ctx)
+
+	proposal := params.NewParameterChangeProposal("marketplace", "raise commissions", []params.ParamChange{
+		params.NewParamChange(marketplace.DefaultParamspace, string(types.KeyMaxBeneficiaryCommission), `"0.100000000000000000"`),
+		params.NewParamChange(marketplace.DefaultParamspace, string(types.KeyMaxOffersPerNFT), `"10"`),
+	})
+	require.True(t, node.app.govKeeper.Router().HasRoute(proposal.ProposalRoute()))
+	require.Nil(t, node.app.govKeeper.Router().GetRoute(proposal.ProposalRoute())(ctx, proposal))
+
+	expected.MaxBeneficiaryCommission = sdk.NewDecWithPrec(1, 1)
+	expected.MaxOffersPerNFT = 10
+	require.Equal(t, expected, node.app.mpKeeper.GetParams(ctx))
+}
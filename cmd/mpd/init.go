@@ -9,9 +9,10 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/genutil"
-	mpcfg "github.com/corestario/marketplace/x/marketplace/config"
+	"github.com/corestario/marketplace/x/marketplace"
 	mptypes "github.com/corestario/marketplace/x/marketplace/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -73,12 +74,6 @@ func InitCmd(ctx *server.Context, cdc *codec.Codec, mbm module.BasicManager,
 				chainID = fmt.Sprintf("test-chain-%v", common.RandStr(6))
 			}
 
-			srvConfig := mpcfg.DefaultMPServerConfig()
-			bMaxCommission := viper.GetFloat64(mptypes.FlagMaxCommission)
-			if bMaxCommission > 0 {
-				srvConfig.MaximumBeneficiaryCommission = bMaxCommission
-			}
-
 			nodeID, _, err := genutil.InitializeNodeValidatorFiles(config)
 			if err != nil {
 				return err
@@ -90,7 +85,20 @@ func InitCmd(ctx *server.Context, cdc *codec.Codec, mbm module.BasicManager,
 			if !viper.GetBool(flagOverwrite) && common.FileExists(genFile) {
 				return fmt.Errorf("genesis.json file already exists: %v", genFile)
 			}
-			appState, err := codec.MarshalJSONIndent(cdc, mbm.DefaultGenesis())
+			genState := mbm.DefaultGenesis()
+			if maxCommission := viper.GetString(mptypes.FlagMaxCommission); maxCommission != "" {
+				var mpGenState marketplace.GenesisState
+				cdc.MustUnmarshalJSON(genState[mptypes.ModuleName], &mpGenState)
+				mpGenState.Params.MaxBeneficiaryCommission, err = sdk.NewDecFromStr(maxCommission)
+				if err != nil {
+					return fmt.Errorf("failed to parse maximum beneficiary commission: %v", err)
+				}
+				if err := marketplace.ValidateGenesis(mpGenState); err != nil {
+					return err
+				}
+				genState[mptypes.ModuleName] = cdc.MustMarshalJSON(mpGenState)
+			}
+			appState, err := codec.MarshalJSONIndent(cdc, genState)
 			if err != nil {
 				return err
 			}
@@ -115,8 +123,6 @@ func InitCmd(ctx *server.Context, cdc *codec.Codec, mbm module.BasicManager,
 			}
 
 			toPrint := newPrintInfo(config.Moniker, chainID, nodeID, "", appState)
-			mpcfg.WriteConfigFile(filepath.Join(config.RootDir, "config", "server.toml"), srvConfig)
-
 			cfg.WriteConfigFile(filepath.Join(config.RootDir, "config", "config.toml"), config)
 			return displayInfo(cdc, toPrint)
 		},
@@ -125,8 +131,8 @@ func InitCmd(ctx *server.Context, cdc *codec.Codec, mbm module.BasicManager,
 	cmd.Flags().String(cli.HomeFlag, defaultNodeHome, "node's home directory")
 	cmd.Flags().BoolP(flagOverwrite, "o", false, "overwrite the genesis.json file")
 	cmd.Flags().String(client.FlagChainID, "", "genesis file chain-id, if left blank will be randomly created")
-	cmd.Flags().Float64(mptypes.FlagMaxCommission, mpcfg.DefaultMPServerConfig().MaximumBeneficiaryCommission,
-		"maximum beneficiary fee, if left blank will be set to default")
+	cmd.Flags().String(mptypes.FlagMaxCommission, "",
+		"maximum beneficiary fee written to the genesis marketplace params, if left blank will be set to default")
 
 	return cmd
 }
@@ -59,6 +59,8 @@ func main() {
 	)
 
 	server.AddCommands(ctx, cdc, rootCmd, newApp, exportAppStateAndTMValidators)
+	rootCmd.PersistentFlags().UintVar(&invCheckPeriod, flagInvCheckPeriod,
+		0, "Assert registered invariants every N blocks")
 	// prepare and add flags
 	executor := cli.PrepareBaseCmd(rootCmd, "NS", app.DefaultNodeHome)
 	go func() {
@@ -74,7 +76,7 @@ func main() {
 }
 
 func newApp(logger log.Logger, db dbm.DB, traceStore io.Writer) abci.Application {
-	return app.NewMarketplaceApp(logger, db, baseapp.SetPruning(store.NewPruningOptionsFromString(viper.GetString("pruning"))))
+	return app.NewMarketplaceApp(logger, db, invCheckPeriod, baseapp.SetPruning(store.NewPruningOptionsFromString(viper.GetString("pruning"))))
 }
 
 func exportAppStateAndTMValidators(
@@ -82,7 +84,7 @@ func exportAppStateAndTMValidators(
 ) (json.RawMessage, []tmtypes.GenesisValidator, error) {
 
 	if height != -1 {
-		nsApp := app.NewMarketplaceApp(logger, db)
+		nsApp := app.NewMarketplaceApp(logger, db, uint(1))
 		err := nsApp.LoadHeight(height)
 		if err != nil {
 			return nil, nil, err
@@ -90,17 +92,20 @@ func exportAppStateAndTMValidators(
 		return nsApp.ExportAppStateAndValidators(forZeroHeight, jailWhiteList)
 	}
 
-	nsApp := app.NewMarketplaceApp(logger, db)
+	nsApp := app.NewMarketplaceApp(logger, db, uint(1))
 
 	return nsApp.ExportAppStateAndValidators(forZeroHeight, jailWhiteList)
 }
 
 const (
-	flagVestingStart = "vesting-start-time"
-	flagVestingEnd   = "vesting-end-time"
-	flagVestingAmt   = "vesting-amount"
+	flagInvCheckPeriod = "inv-check-period"
+	flagVestingStart   = "vesting-start-time"
+	flagVestingEnd     = "vesting-end-time"
+	flagVestingAmt     = "vesting-amount"
 )
 
+var invCheckPeriod uint
+
 // AddGenesisAccountCmd returns add-genesis-account cobra Command.
 func AddGenesisAccountCmd(
 	ctx *server.Context, cdc *codec.Codec, defaultNodeHome, defaultClientHome string,
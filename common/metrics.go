@@ -29,6 +29,10 @@ const (
 	PrometheusValueMsgFinishAuction            = "MsgFinishAuction"
 	PrometheusValueMsgMakeBidOnAuction         = "MsgMakeBidOnAuction"
 	PrometheusValueMsgBuyoutFromAuction        = "MsgBuyoutFromAuction"
+	PrometheusValueMsgCommitSealedBid          = "MsgCommitSealedBid"
+	PrometheusValueMsgRevealSealedBid          = "MsgRevealSealedBid"
+	PrometheusValueMsgSettleSealedAuction      = "MsgSettleSealedAuction"
+	PrometheusValueMsgRevealReservePrice       = "MsgRevealReservePrice"
 	PrometheusValueMsgBatchTransfer            = "MsgMsgBatchTransfer"
 	PrometheusValueMsgMsgBatchPutOnMarket      = "MsgMsgBatchPutOnMarket"
 	PrometheusValueMsgMsgBatchRemoveFromMarket = "MsgMsgBatchRemoveFromMarket"
@@ -36,6 +40,25 @@ const (
 	PrometheusValueMsgMakeOffer                = "MsgMakeOffer"
 	PrometheusValueMsgAcceptOffer              = "MsgAcceptOffer"
 	PrometheusValueMsgRemoveOffer              = "MsgRemoveOffer"
+	PrometheusValueMsgCreateBundle             = "MsgCreateBundle"
+	PrometheusValueMsgRemoveBundle             = "MsgRemoveBundle"
+	PrometheusValueMsgMakeCollectionOffer      = "MsgMakeCollectionOffer"
+	PrometheusValueMsgFillCollectionOffer      = "MsgFillCollectionOffer"
+	PrometheusValueMsgRemoveCollectionOffer    = "MsgRemoveCollectionOffer"
+	PrometheusValueMsgProposeSwap              = "MsgProposeSwap"
+	PrometheusValueMsgAcceptSwap               = "MsgAcceptSwap"
+	PrometheusValueMsgCancelSwap               = "MsgCancelSwap"
+	PrometheusValueMsgHashLockNFT              = "MsgHashLockNFT"
+	PrometheusValueMsgClaimHashLockedNFT       = "MsgClaimHashLockedNFT"
+	PrometheusValueMsgReclaimHashLockedNFT     = "MsgReclaimHashLockedNFT"
+	PrometheusValueMsgListNFTForRent           = "MsgListNFTForRent"
+	PrometheusValueMsgRemoveRentalListing      = "MsgRemoveRentalListing"
+	PrometheusValueMsgRentNFT                  = "MsgRentNFT"
+	PrometheusValueMsgRequestLoan              = "MsgRequestLoan"
+	PrometheusValueMsgCancelLoanRequest        = "MsgCancelLoanRequest"
+	PrometheusValueMsgFundLoan                 = "MsgFundLoan"
+	PrometheusValueMsgRepayLoan                = "MsgRepayLoan"
+	PrometheusValueMsgClaimLoanCollateral      = "MsgClaimLoanCollateral"
 )
 
 func NewPrometheusMsgMetrics(module string) *MsgMetrics {
@@ -47,7 +70,14 @@ func NewPrometheusMsgMetrics(module string) *MsgMetrics {
 	},
 		[]string{PrometheusLabelStatus, PrometheusLabelMsgType},
 	)
-	prometheus.MustRegister(numMsgs)
+	if err := prometheus.Register(numMsgs); err != nil {
+		// several apps in one process (e.g. in tests) share the same collector
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			panic(err)
+		}
+		numMsgs = are.ExistingCollector.(*prometheus.CounterVec)
+	}
 	return &MsgMetrics{
 		NumMsgs: numMsgs,
 	}
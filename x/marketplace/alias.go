@@ -5,14 +5,15 @@ import (
 )
 
 const (
-	ModuleName                 = types.ModuleName
-	RouterKey                  = types.RouterKey
-	StoreKey                   = types.StoreKey
-	RegisterCurrencyKey        = types.RegisterCurrency
-	AuctionKey                 = types.AuctionKey
-	DeletedNFTKey              = types.DeletedNFTKey
-	FungibleTokenCreationPrice = types.FungibleTokenCreationPrice
-	FungibleCommissionAddress  = types.FungibleCommissionAddress
+	ModuleName                = types.ModuleName
+	RouterKey                 = types.RouterKey
+	StoreKey                  = types.StoreKey
+	RegisterCurrencyKey       = types.RegisterCurrency
+	AuctionKey                = types.AuctionKey
+	DeletedNFTKey             = types.DeletedNFTKey
+	OfferKey                  = types.OfferKey
+	DefaultParamspace         = types.DefaultParamspace
+	FungibleCommissionAddress = types.FungibleCommissionAddress
 
 	MaxBeneficiaryCommission = types.FlagMaxCommission
 )
@@ -38,6 +39,10 @@ type (
 	MsgMakeBidOnAuction     = types.MsgMakeBidOnAuction
 	MsgFinishAuction        = types.MsgFinishAuction
 	MsgBuyoutOnAuction      = types.MsgBuyoutOnAuction
+	MsgCommitSealedBid      = types.MsgCommitSealedBid
+	MsgRevealSealedBid      = types.MsgRevealSealedBid
+	MsgSettleSealedAuction  = types.MsgSettleSealedAuction
+	MsgRevealReservePrice   = types.MsgRevealReservePrice
 
 	MsgCreateFungibleToken    = types.MsgCreateFungibleToken
 	MsgTransferFungibleTokens = types.MsgTransferFungibleTokens
@@ -50,4 +55,24 @@ type (
 	MsgAcceptOffer            = types.MsgAcceptOffer
 	MsgRemoveOffer            = types.MsgRemoveOffer
 	MsgTransferNFTByIBC       = types.MsgTransferNFTByIBC
+	MsgMintNFTWithRoyalty     = types.MsgMintNFTWithRoyalty
+	MsgCreateBundle           = types.MsgCreateBundle
+	MsgRemoveBundle           = types.MsgRemoveBundle
+	MsgMakeCollectionOffer    = types.MsgMakeCollectionOffer
+	MsgFillCollectionOffer    = types.MsgFillCollectionOffer
+	MsgRemoveCollectionOffer  = types.MsgRemoveCollectionOffer
+	MsgProposeSwap            = types.MsgProposeSwap
+	MsgAcceptSwap             = types.MsgAcceptSwap
+	MsgCancelSwap             = types.MsgCancelSwap
+	MsgHashLockNFT            = types.MsgHashLockNFT
+	MsgClaimHashLockedNFT     = types.MsgClaimHashLockedNFT
+	MsgReclaimHashLockedNFT   = types.MsgReclaimHashLockedNFT
+	MsgListNFTForRent         = types.MsgListNFTForRent
+	MsgRemoveRentalListing    = types.MsgRemoveRentalListing
+	MsgRentNFT                = types.MsgRentNFT
+	MsgRequestLoan            = types.MsgRequestLoan
+	MsgCancelLoanRequest      = types.MsgCancelLoanRequest
+	MsgFundLoan               = types.MsgFundLoan
+	MsgRepayLoan              = types.MsgRepayLoan
+	MsgClaimLoanCollateral    = types.MsgClaimLoanCollateral
 )
@@ -0,0 +1,112 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinNextBid(t *testing.T) {
+	lot := types.NewAuctionLot("token", coins(100), sdk.Coins{}, time.Now())
+	require.Equal(t, coins(100), lot.MinNextBid())
+
+	lot.SetLastBid(types.NewAuctionBid(nil, nil, coins(101), defaultCommission, time.Now()))
+	require.Equal(t, coins(101), lot.MinNextBid())
+
+	lot.MinBidIncrement = coins(5)
+	require.Equal(t, coins(106), lot.MinNextBid())
+
+	// a share of the last bid is rounded up
+	lot.MinBidIncrement = nil
+	lot.MinBidIncrementRate = sdk.NewDecWithPrec(5, 2)
+	require.Equal(t, coins(107), lot.MinNextBid())
+}
+
+func TestAuctionMinBidIncrementAndExtension(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, first, second := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	keeper := mpKeeperTest.marketKeeper
+
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+	require.True(t, result.IsOK())
+
+	start := mpKeeperTest.ctx.BlockHeader().Time
+	msg := types.NewMsgPutNFTOnAuction(owner, owner, mintMsg.ID, coins(100), sdk.Coins{}, start.Add(time.Hour))
+	msg.MinBidIncrementRate = sdk.NewDecWithPrec(1, 1)
+	msg.ExtensionWindow = 10 * time.Minute
+	require.Nil(t, msg.ValidateBasic())
+	result = handler(mpKeeperTest.ctx, *msg)
+	require.True(t, result.IsOK(), result.Log)
+
+	bid := func(ctx sdk.Context, bidder sdk.AccAddress, amount int64) sdk.Result {
+		return handler(ctx, *types.NewMsgMakeBidOnAuction(bidder, bidder, mintMsg.ID, coins(amount), defaultCommission))
+	}
+
+	// an early bid does not extend the auction
+	ctx := mpKeeperTest.ctx.WithBlockTime(start.Add(10 * time.Minute))
+	require.True(t, bid(ctx, first, 100).IsOK())
+	lot, err := keeper.GetAuctionLot(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.Equal(t, start.Add(time.Hour), lot.ExpirationTime)
+
+	// a late bid must raise the last one by 10% and extends the auction
+	ctx = ctx.WithBlockTime(start.Add(55 * time.Minute))
+	require.False(t, bid(ctx, second, 109).IsOK())
+	require.True(t, bid(ctx, second, 110).IsOK())
+	lot, err = keeper.GetAuctionLot(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.Equal(t, start.Add(65*time.Minute), lot.ExpirationTime)
+	require.Contains(t, lot.String(), "ExtensionWindow: 10m0s")
+
+	// the lot is settled at the new expiration time
+	ctx = ctx.WithBlockTime(start.Add(61 * time.Minute))
+	keeper.CheckFinishedAuctions(ctx)
+	_, err = keeper.GetAuctionLot(ctx, mintMsg.ID)
+	require.Nil(t, err)
+
+	ctx = ctx.WithBlockTime(start.Add(66 * time.Minute))
+	keeper.CheckFinishedAuctions(ctx)
+	_, err = keeper.GetAuctionLot(ctx, mintMsg.ID)
+	require.NotNil(t, err)
+	token, err := keeper.GetNFT(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(second))
+}
+
+func TestPutOnAuctionIncrementValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("owner"))
+	finish := time.Now().UTC().Add(time.Hour)
+
+	both := types.NewMsgPutNFTOnAuction(addr, addr, "token", coins(100), sdk.Coins{}, finish)
+	both.MinBidIncrement, both.MinBidIncrementRate = coins(10), sdk.NewDecWithPrec(1, 1)
+	negativeRate := types.NewMsgPutNFTOnAuction(addr, addr, "token", coins(100), sdk.Coins{}, finish)
+	negativeRate.MinBidIncrementRate = sdk.NewDecWithPrec(-1, 1)
+	negativeWindow := types.NewMsgPutNFTOnAuction(addr, addr, "token", coins(100), sdk.Coins{}, finish)
+	negativeWindow.ExtensionWindow = -time.Minute
+	dutch := types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), coins(10), 0, finish)
+	dutch.ExtensionWindow = time.Minute
+
+	for name, msg := range map[string]*types.MsgPutNFTOnAuction{
+		"absolute and rate": both,
+		"negative rate":     negativeRate,
+		"negative window":   negativeWindow,
+		"dutch extension":   dutch,
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+}
@@ -0,0 +1,243 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestFinishExpiredAuctionsInEndBlock(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+	_, err = mpKeeperTest.updateVoteInfos(1, coins)
+	require.Nil(t, err)
+
+	owner, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	expirationTime := time.Now().UTC().Add(time.Hour)
+
+	// one lot with a bid and one lot without bids
+	var ids []string
+	for i := 0; i < 2; i++ {
+		msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+		result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, msg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+		require.True(t, result.IsOK())
+
+		putOnAuctionMsg := types.NewMsgPutNFTOnAuction(owner, mpKeeperTest.addrs[2], msg.ID,
+			sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(100))), sdk.Coins{}, expirationTime)
+		result = handler(mpKeeperTest.ctx, *putOnAuctionMsg)
+		require.True(t, result.IsOK())
+		ids = append(ids, msg.ID)
+	}
+	soldID, unsoldID := ids[0], ids[1]
+
+	bid := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(500)))
+	bidMsg := types.NewMsgMakeBidOnAuction(bidder, mpKeeperTest.addrs[3], soldID, bid, defaultCommission)
+	result := handler(mpKeeperTest.ctx, *bidMsg)
+	require.True(t, result.IsOK())
+
+	module := marketplace.NewAppModule(mpKeeperTest.marketKeeper, mpKeeperTest.bankKeeper, mpKeeperTest.nftKeeper)
+
+	// nothing is settled before the lots expire
+	ctx := mpKeeperTest.ctx.WithBlockTime(expirationTime.Add(-time.Minute)).WithEventManager(sdk.NewEventManager())
+	module.EndBlock(ctx, abci.RequestEndBlock{})
+	for _, id := range ids {
+		_, err := mpKeeperTest.marketKeeper.GetAuctionLot(ctx, id)
+		require.Nil(t, err)
+	}
+
+	ctx = mpKeeperTest.ctx.WithBlockTime(expirationTime.Add(time.Minute)).WithEventManager(sdk.NewEventManager())
+	module.EndBlock(ctx, abci.RequestEndBlock{})
+
+	for _, id := range ids {
+		_, err := mpKeeperTest.marketKeeper.GetAuctionLot(ctx, id)
+		require.NotNil(t, err)
+	}
+
+	sold, err := mpKeeperTest.marketKeeper.GetNFT(ctx, soldID)
+	require.Nil(t, err)
+	require.True(t, sold.Owner.Equals(bidder))
+	require.Equal(t, types.NFTStatusDefault, sold.Status)
+	require.Equal(t, int64(500), mpKeeperTest.bankKeeper.GetCoins(ctx, bidder).AmountOf(denom).Int64())
+
+	unsold, err := mpKeeperTest.marketKeeper.GetNFT(ctx, unsoldID)
+	require.Nil(t, err)
+	require.True(t, unsold.Owner.Equals(owner))
+	require.Equal(t, types.NFTStatusDefault, unsold.Status)
+
+	var finished []string
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type != (types.MsgFinishAuction{}).Type() {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == types.AttributeKeyNFTID {
+				finished = append(finished, string(attr.Value))
+			}
+		}
+	}
+	require.ElementsMatch(t, ids, finished)
+}
+
+func TestFinishExpiredAuctionSettlementFails(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	owner, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	module := marketplace.NewAppModule(mpKeeperTest.marketKeeper, mpKeeperTest.bankKeeper, mpKeeperTest.nftKeeper)
+	expirationTime := time.Now().UTC().Add(time.Hour)
+
+	lotID := putNFTsOnAuction(t, mpKeeperTest, 1, expirationTime)[0]
+	result := handler(mpKeeperTest.ctx, *types.NewMsgMakeBidOnAuction(bidder, mpKeeperTest.addrs[3], lotID, coins(500), defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+
+	// the seller cannot be paid, the lot cannot be settled
+	mpKeeperTest.faultyBank.failSendTo = owner
+	ctx := mpKeeperTest.ctx.WithBlockTime(expirationTime.Add(time.Minute)).WithEventManager(sdk.NewEventManager())
+	module.EndBlock(ctx, abci.RequestEndBlock{})
+
+	// the bid is refunded, the NFT is returned to its owner and the lot leaves the expiry queue
+	require.Equal(t, []int64{1000}, getBalances(mpKeeperTest, bidder))
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusDefault, lotID)
+	_, err = mpKeeperTest.marketKeeper.GetAuctionLot(ctx, lotID)
+	require.NotNil(t, err)
+	require.Empty(t, getExpiredLotIDs(mpKeeperTest, expirationTime.Add(time.Hour)))
+
+	var failed []string
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type != types.EventTypeFailAuctionSettling {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == types.AttributeKeyNFTID {
+				failed = append(failed, string(attr.Value))
+			}
+		}
+	}
+	require.Equal(t, []string{lotID}, failed)
+
+	msgInv, broken := marketplace.EscrowInvariant(mpKeeperTest.marketKeeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func putNFTsOnAuction(t testing.TB, mp *marketplaceKeeperTest, count int, expirationTime time.Time) []string {
+	owner := mp.addrs[0]
+	ids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), types.DefaultTokenDenom, "")
+		result := marketplace.HandleMsgMintNFTMarketplace(mp.ctx, msg, mp.nftKeeper, mp.marketKeeper)
+		require.True(t, result.IsOK())
+
+		openingPrice := sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(100)))
+		require.Nil(t, mp.marketKeeper.PutNFTOnAuction(mp.ctx, msg.ID, owner, mp.addrs[2],
+			openingPrice, sdk.Coins{}, expirationTime))
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func getExpiredLotIDs(mp *marketplaceKeeperTest, endTime time.Time) []string {
+	var ids []string
+	iterator := mp.marketKeeper.GetExpiredAuctionLotsIterator(mp.ctx, endTime)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, string(iterator.Value()))
+	}
+	return ids
+}
+
+func TestAuctionExpiryQueue(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	now := time.Now().UTC()
+	early := putNFTsOnAuction(t, mpKeeperTest, 2, now.Add(time.Hour))
+	late := putNFTsOnAuction(t, mpKeeperTest, 2, now.Add(2*time.Hour))
+
+	require.Empty(t, getExpiredLotIDs(mpKeeperTest, now.Add(time.Hour)))
+	require.ElementsMatch(t, early, getExpiredLotIDs(mpKeeperTest, now.Add(time.Hour+time.Second)))
+	require.ElementsMatch(t, append(early, late...), getExpiredLotIDs(mpKeeperTest, now.Add(3*time.Hour)))
+
+	// moving the expiration time moves the lot in the queue
+	lot, err := mpKeeperTest.marketKeeper.GetAuctionLot(mpKeeperTest.ctx, late[0])
+	require.Nil(t, err)
+	lot.ExpirationTime = now.Add(30 * time.Minute)
+	require.Nil(t, mpKeeperTest.marketKeeper.UpdateAuctionLot(mpKeeperTest.ctx, lot))
+	require.Equal(t, []string{late[0]}, getExpiredLotIDs(mpKeeperTest, now.Add(time.Hour)))
+
+	// removing the lot removes it from the queue
+	require.Nil(t, mpKeeperTest.marketKeeper.RemoveNFTFromAuction(mpKeeperTest.ctx, late[0], mpKeeperTest.addrs[0]))
+	require.Empty(t, getExpiredLotIDs(mpKeeperTest, now.Add(time.Hour)))
+	require.ElementsMatch(t, append(early, late[1]), getExpiredLotIDs(mpKeeperTest, now.Add(3*time.Hour)))
+}
+
+// BenchmarkFindExpiredAuctionLots compares looking up due lots through the expiry queue
+// with scanning and decoding every lot in the auction store.
+func BenchmarkFindExpiredAuctionLots(b *testing.B) {
+	const (
+		liveLots    = 2000
+		expiredLots = 10
+	)
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(b, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(1000)))
+	require.Nil(b, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	now := time.Now().UTC()
+	putNFTsOnAuction(b, mpKeeperTest, expiredLots, now.Add(-time.Hour))
+	putNFTsOnAuction(b, mpKeeperTest, liveLots, now.Add(time.Hour))
+
+	b.Run("ExpiryQueue", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var found int
+			iterator := mpKeeperTest.marketKeeper.GetExpiredAuctionLotsIterator(mpKeeperTest.ctx, now)
+			for ; iterator.Valid(); iterator.Next() {
+				if _, err := mpKeeperTest.marketKeeper.GetAuctionLot(mpKeeperTest.ctx, string(iterator.Value())); err == nil {
+					found++
+				}
+			}
+			iterator.Close()
+			require.Equal(b, expiredLots, found)
+		}
+	})
+
+	b.Run("FullScan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var found int
+			iterator := mpKeeperTest.marketKeeper.GetAuctionLotsIterator(mpKeeperTest.ctx)
+			for ; iterator.Valid(); iterator.Next() {
+				var lot types.AuctionLot
+				types.ModuleCdc.MustUnmarshalJSON(iterator.Value(), &lot)
+				if lot.ExpirationTime.Before(now) {
+					found++
+				}
+			}
+			iterator.Close()
+			require.Equal(b, expiredLots, found)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestBidHistory(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, first, second := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+
+	params := keeper.GetParams(mpKeeperTest.ctx)
+	params.BidHistoryRetention = 10
+	keeper.SetParams(mpKeeperTest.ctx, params)
+
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+	require.True(t, result.IsOK())
+
+	start := mpKeeperTest.ctx.BlockHeader().Time
+	putOnAuction := func(ctx sdk.Context, seller sdk.AccAddress) {
+		msg := types.NewMsgPutNFTOnAuction(seller, seller, mintMsg.ID, coins(100), sdk.Coins{}, ctx.BlockHeader().Time.Add(time.Hour))
+		result := handler(ctx, *msg)
+		require.True(t, result.IsOK(), result.Log)
+	}
+	queryBids := func(ctx sdk.Context, auction ...string) types.QueryResAuctionBids {
+		path := append([]string{marketplace.QueryAuctionBids, mintMsg.ID}, auction...)
+		bz, err := querier(ctx, path, abci.RequestQuery{})
+		require.Nil(t, err)
+		var res types.QueryResAuctionBids
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		return res
+	}
+
+	ctx := mpKeeperTest.ctx.WithBlockHeight(1)
+	putOnAuction(ctx, owner)
+	for i, bidder := range []sdk.AccAddress{first, second, first} {
+		msg := types.NewMsgMakeBidOnAuction(bidder, bidder, mintMsg.ID, coins(int64(100+i*10)), defaultCommission)
+		require.True(t, handler(ctx, *msg).IsOK())
+	}
+
+	// outbid bids are kept in the history
+	res := queryBids(ctx)
+	require.Equal(t, uint64(1), res.Auction)
+	require.Len(t, res.Bids, 3)
+	for i, bidder := range []sdk.AccAddress{first, second, first} {
+		require.True(t, res.Bids[i].Bidder.Equals(bidder))
+		require.Equal(t, coins(int64(100+i*10)), res.Bids[i].Bid)
+	}
+
+	// the history of a finished auction is kept for BidHistoryRetention blocks
+	ctx = ctx.WithBlockHeight(5).WithBlockTime(start.Add(2 * time.Hour))
+	keeper.CheckFinishedAuctions(ctx)
+	_, err = keeper.GetAuctionLot(ctx, mintMsg.ID)
+	require.NotNil(t, err)
+
+	keeper.PruneBidHistories(ctx.WithBlockHeight(14))
+	require.Len(t, queryBids(ctx).Bids, 3)
+	keeper.PruneBidHistories(ctx.WithBlockHeight(15))
+	require.Empty(t, queryBids(ctx).Bids)
+
+	// a new auction of the NFT starts with an empty history, the history of the previous one
+	// is still selected by its number and pruned on its own schedule
+	ctx = ctx.WithBlockHeight(20)
+	putOnAuction(ctx, first)
+	require.True(t, handler(ctx, *types.NewMsgMakeBidOnAuction(second, second, mintMsg.ID, coins(100), defaultCommission)).IsOK())
+	require.True(t, handler(ctx, *types.NewMsgRemoveNFTFromAuction(first, mintMsg.ID)).IsOK())
+	require.Len(t, queryBids(ctx).Bids, 1)
+
+	ctx = ctx.WithBlockHeight(25)
+	putOnAuction(ctx, first)
+	res = queryBids(ctx)
+	require.Equal(t, uint64(3), res.Auction)
+	require.Empty(t, res.Bids)
+	require.Len(t, queryBids(ctx, "2").Bids, 1)
+	require.True(t, handler(ctx, *types.NewMsgMakeBidOnAuction(second, second, mintMsg.ID, coins(110), defaultCommission)).IsOK())
+
+	keeper.PruneBidHistories(ctx.WithBlockHeight(29))
+	require.Len(t, queryBids(ctx, "2").Bids, 1)
+	keeper.PruneBidHistories(ctx.WithBlockHeight(30))
+	require.Empty(t, queryBids(ctx, "2").Bids)
+	res = queryBids(ctx)
+	require.Len(t, res.Bids, 1)
+	require.Equal(t, coins(110), res.Bids[0].Bid)
+
+	_, err = querier(ctx, []string{marketplace.QueryAuctionBids, mintMsg.ID, "latest"}, abci.RequestQuery{})
+	require.NotNil(t, err)
+}
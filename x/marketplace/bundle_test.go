@@ -0,0 +1,144 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	creator, seller, buyer, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	nftHandler := marketplace.CustomNFTHandler(mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+
+	// two items of another creator with a 10% royalty and one of the seller
+	var items []string
+	for i := 0; i < 2; i++ {
+		mintMsg := types.NewMsgMintNFTWithRoyalty(creator, seller, uuid.New().String(), denom, "", sdk.NewDecWithPrec(1, 1))
+		require.True(t, handler(ctx, *mintMsg).IsOK())
+		items = append(items, mintMsg.ID)
+	}
+	mintMsg := nft.NewMsgMintNFT(seller, seller, uuid.New().String(), denom, "")
+	require.True(t, nftHandler(ctx, mintMsg).IsOK())
+	items = append(items, mintMsg.ID)
+
+	requireItems := func(owner sdk.AccAddress, status types.NFTStatus) {
+		for _, id := range items {
+			token, err := keeper.GetNFT(ctx, id)
+			require.Nil(t, err)
+			require.True(t, token.Owner.Equals(owner))
+			require.Equal(t, status, token.Status)
+			baseToken, err := mpKeeperTest.nftKeeper.GetNFT(ctx, denom, id)
+			require.Nil(t, err)
+			require.True(t, baseToken.GetOwner().Equals(owner))
+		}
+	}
+
+	// the bundle takes an unused ID and items of the sender only
+	require.False(t, handler(ctx, *types.NewMsgCreateBundle(seller, items[0], items[1:])).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgCreateBundle(buyer, "bundle", items)).IsOK())
+	result := handler(ctx, *types.NewMsgCreateBundle(seller, "bundle", items))
+	require.True(t, result.IsOK(), result.Log)
+	requireItems(seller, types.NFTStatusInBundle)
+	require.False(t, handler(ctx, *types.NewMsgCreateBundle(seller, "other", items[:2])).IsOK())
+
+	// the items cannot be traded on their own
+	require.False(t, handler(ctx, *types.NewMsgPutOnMarketNFT(seller, seller, items[0], coins(100))).IsOK())
+	require.False(t, nftHandler(ctx, nft.NewMsgTransferNFT(seller, buyer, denom, items[0])).IsOK())
+	require.False(t, nftHandler(ctx, nft.NewMsgBurnNFT(seller, items[0], denom)).IsOK())
+
+	// the bundle is queried like any NFT
+	res := queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{Owner: seller, Limit: types.MaxQueryNFTsLimit})
+	require.Len(t, res.NFTs, 4)
+
+	// sold on the market, every creator is paid the royalty on an equal share of the price
+	result = handler(ctx, *types.NewMsgPutOnMarketNFT(seller, seller, "bundle", coins(900)))
+	require.True(t, result.IsOK(), result.Log)
+	before := getBalances(mpKeeperTest, creator)
+	result = handler(ctx, *types.NewMsgBuyNFT(buyer, seller, "bundle", defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, before[0]+60, getBalances(mpKeeperTest, creator)[0])
+	requireItems(buyer, types.NFTStatusInBundle)
+
+	// sold on auction
+	finish := ctx.BlockHeader().Time.Add(time.Hour)
+	result = handler(ctx, *types.NewMsgPutNFTOnAuction(buyer, buyer, "bundle", coins(100), sdk.Coins{}, finish))
+	require.True(t, result.IsOK(), result.Log)
+	require.False(t, handler(ctx, *types.NewMsgRemoveBundle(buyer, "bundle")).IsOK())
+	result = handler(ctx, *types.NewMsgMakeBidOnAuction(bidder, buyer, "bundle", coins(200), defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	ctx = ctx.WithBlockTime(finish.Add(time.Second))
+	keeper.CheckFinishedAuctions(ctx)
+	requireItems(bidder, types.NFTStatusInBundle)
+
+	// removing the bundle releases the items
+	require.False(t, handler(ctx, *types.NewMsgRemoveBundle(buyer, "bundle")).IsOK())
+	result = handler(ctx, *types.NewMsgRemoveBundle(bidder, "bundle"))
+	require.True(t, result.IsOK(), result.Log)
+	requireItems(bidder, types.NFTStatusDefault)
+	_, err = keeper.GetNFT(ctx, "bundle")
+	require.NotNil(t, err)
+
+	msgInv, broken := marketplace.NFTIndexesInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+	msgInv, broken = marketplace.EscrowInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestCreateBundleValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("owner"))
+
+	require.Nil(t, types.NewMsgCreateBundle(addr, "bundle", []string{"a", "b"}).ValidateBasic())
+
+	for name, msg := range map[string]*types.MsgCreateBundle{
+		"no owner":       types.NewMsgCreateBundle(sdk.AccAddress{}, "bundle", []string{"a", "b"}),
+		"no bundle ID":   types.NewMsgCreateBundle(addr, " ", []string{"a", "b"}),
+		"single item":    types.NewMsgCreateBundle(addr, "bundle", []string{"a"}),
+		"duplicate item": types.NewMsgCreateBundle(addr, "bundle", []string{"a", "b", "a"}),
+		"bundle as item": types.NewMsgCreateBundle(addr, "bundle", []string{"a", "bundle"}),
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+}
+
+func TestBatchTransfer(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	owner, recipient := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	ctx := mpKeeperTest.ctx
+
+	free, onMarket := mintNFT(t, mpKeeperTest, owner), mintNFT(t, mpKeeperTest, owner)
+	require.True(t, handler(ctx, *types.NewMsgPutOnMarketNFT(owner, owner, onMarket, coins(100))).IsOK())
+
+	// a missing token fails the whole batch
+	result := handler(ctx, *types.NewMsgBatchTransfer(owner, recipient, []string{free, "missing"}))
+	require.False(t, result.IsOK())
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusDefault, free)
+
+	// a token that cannot be transferred is left to its owner in both the nft module and the marketplace
+	result = handler(ctx, *types.NewMsgBatchTransfer(owner, recipient, []string{free, onMarket}))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, recipient, types.NFTStatusDefault, free)
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusOnMarket, onMarket)
+}
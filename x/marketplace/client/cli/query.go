@@ -6,8 +6,11 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	mputils "github.com/corestario/marketplace/x/marketplace/client/utils"
 	"github.com/corestario/marketplace/x/marketplace/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func GetQueryCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
@@ -25,6 +28,20 @@ func GetQueryCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 		GetCmdFungibleTokens(storeKey, cdc),
 		GetCmdAuctionLot(storeKey, cdc),
 		GetCmdAuctionLots(storeKey, cdc),
+		GetCmdAuctionPrice(storeKey, cdc),
+		GetCmdAuctionBids(storeKey, cdc),
+		GetCmdOffers(storeKey, cdc),
+		GetCmdBuyerOffers(storeKey, cdc),
+		GetCmdCollectionOffers(storeKey, cdc),
+		GetCmdSwap(storeKey, cdc),
+		GetCmdSwaps(storeKey, cdc),
+		GetCmdHashLock(storeKey, cdc),
+		GetCmdRentalListing(storeKey, cdc),
+		GetCmdNFTUser(storeKey, cdc),
+		GetCmdRentalHistory(storeKey, cdc),
+		GetCmdLoan(storeKey, cdc),
+		GetCmdLoans(storeKey, cdc),
+		GetCmdParams(storeKey, cdc),
 	)...)
 	return marketplaceQueryCmd
 }
@@ -52,15 +69,20 @@ func GetCmdNFT(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	}
 }
 
-// GetCmdNFTs queries a list of all NFTs
+// GetCmdNFTs queries a page of NFTs matching the filters
 func GetCmdNFTs(queryRoute string, cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "nfts",
 		Short: "get NFTs list",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
 
-			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/nfts", queryRoute), nil)
+			params, err := mputils.ParseQueryNFTsParams(viper.GetString)
+			if err != nil {
+				return err
+			}
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/nfts", queryRoute), cdc.MustMarshalJSON(params))
 			if err != nil {
 				fmt.Printf("could not get query names: %v", err.Error())
 				return nil
@@ -71,6 +93,15 @@ func GetCmdNFTs(queryRoute string, cdc *codec.Codec) *cobra.Command {
 			return cliCtx.PrintOutput(out)
 		},
 	}
+	cmd.Flags().String(types.FlagPage, "", "page number, starting from 1")
+	cmd.Flags().String(types.FlagLimit, "", fmt.Sprintf("number of NFTs per page, %d by default", types.DefaultQueryNFTsLimit))
+	cmd.Flags().String(types.FlagOwner, "", "only show NFTs of the owner")
+	cmd.Flags().String(types.FlagStatus, "", "only show NFTs with the status: default, on_market or on_auction")
+	cmd.Flags().String(types.FlagDenom, "", "only show NFTs of the denom")
+	cmd.Flags().String(types.FlagMinPrice, "", "only show NFTs with a price of at least this")
+	cmd.Flags().String(types.FlagMaxPrice, "", "only show NFTs with a price of at most this")
+	cmd.Flags().String(types.FlagSortBy, "", "sort NFTs by price or time_created instead of ID")
+	return cmd
 }
 
 // GetCmdNFT queries information about an NFT.
@@ -117,6 +148,299 @@ func GetCmdFungibleTokens(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	}
 }
 
+// GetCmdAuctionBids queries the bid history of an auction of an NFT.
+func GetCmdAuctionBids(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "auction_bids [id] [auction]",
+		Short: "get all bids on the given auction of an NFT, by default the current or the last finished one, oldest first",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			name := args[0]
+
+			path := fmt.Sprintf("custom/%s/auction_bids/%s", queryRoute, name)
+			if len(args) > 1 {
+				path = fmt.Sprintf("%s/%s", path, args[1])
+			}
+			res, _, err := cliCtx.QueryWithData(path, nil)
+			if err != nil {
+				fmt.Printf("could not resolve name - %s \n", name)
+				return nil
+			}
+
+			var out types.QueryResAuctionBids
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdOffers queries the open offers for an NFT.
+func GetCmdOffers(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "offers [id]",
+		Short: "get all open offers for an NFT",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			name := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/offers/%s", queryRoute, name), nil)
+			if err != nil {
+				fmt.Printf("could not resolve name - %s \n", name)
+				return nil
+			}
+
+			var out types.QueryResOffers
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdBuyerOffers queries the open offers of a buyer.
+func GetCmdBuyerOffers(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "buyer_offers [address]",
+		Short: "get all open offers of a buyer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			buyer := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/buyer_offers/%s", queryRoute, buyer), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.QueryResOffers
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdCollectionOffers queries the collection offers for a denom.
+func GetCmdCollectionOffers(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "collection_offers [denom]",
+		Short: "get all collection offers for a denom, highest price first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			denom := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/collection_offers/%s", queryRoute, denom), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.QueryResCollectionOffers
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdSwap queries a swap by ID.
+func GetCmdSwap(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "swap [id]",
+		Short: "get a swap by ID",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			id := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/swap/%s", queryRoute, id), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.Swap
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdSwaps queries the swaps an address is the proposer or the counterparty of.
+func GetCmdSwaps(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "swaps [address]",
+		Short: "get all swaps proposed by or to an address",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/swaps/%s", queryRoute, address), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.QueryResSwaps
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdHashLock queries the hash lock of an NFT.
+func GetCmdHashLock(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "hash_lock [token_id]",
+		Short: "get the hash lock of a token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			id := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/hash_lock/%s", queryRoute, id), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.HashLock
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdRentalListing queries the rental listing of an NFT.
+func GetCmdRentalListing(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rental_listing [token_id]",
+		Short: "get the rental listing of a token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			id := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/rental_listing/%s", queryRoute, id), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.RentalListing
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdNFTUser queries the current user of an NFT.
+func GetCmdNFTUser(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "nft_user [token_id]",
+		Short: "get the current user of a rented token and when the rental expires",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			id := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/nft_user/%s", queryRoute, id), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.QueryResNFTUser
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdRentalHistory queries the rental history of an NFT.
+func GetCmdRentalHistory(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rental_history [token_id]",
+		Short: "get the rentals of a token in the order they were made",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			id := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/rental_history/%s", queryRoute, id), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.QueryResRentals
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdLoan queries a loan by ID.
+func GetCmdLoan(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "loan [loan_id]",
+		Short: "get a loan by ID",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			id := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/loan/%s", queryRoute, id), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.Loan
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdLoans queries the loans an address is the borrower or the lender of.
+func GetCmdLoans(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "loans [address]",
+		Short: "get all loans requested or funded by an address",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			address := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/loans/%s", queryRoute, address), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.QueryResLoans
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
+// GetCmdAuctionPrice queries the current price of an auction lot.
+func GetCmdAuctionPrice(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "auction_price [id]",
+		Short: "get the current price of an auction lot: the falling price of a Dutch auction, the last bid otherwise",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			name := args[0]
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/auction_price/%s", queryRoute, name), nil)
+			if err != nil {
+				fmt.Printf("could not resolve name - %s \n", name)
+				return nil
+			}
+
+			var out sdk.Coins
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
+
 // GetCmdAuctionLot queries information about an NFT lot on auction.
 func GetCmdAuctionLot(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
@@ -160,3 +484,24 @@ func GetCmdAuctionLots(queryRoute string, cdc *codec.Codec) *cobra.Command {
 		},
 	}
 }
+
+// GetCmdParams queries the marketplace module parameters
+func GetCmdParams(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "params",
+		Short: "get the current marketplace parameters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/params", queryRoute), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.Params
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
@@ -15,6 +15,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+	mputils "github.com/corestario/marketplace/x/marketplace/client/utils"
 	"github.com/corestario/marketplace/x/marketplace/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -42,6 +43,10 @@ func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 		GetCmdFinishAuction(cdc),
 		GetCmdMakeBidOnAuction(cdc),
 		GetCmdBuyoutFromAuction(cdc),
+		GetCmdCommitSealedBid(cdc),
+		GetCmdRevealSealedBid(cdc),
+		GetCmdSettleSealedAuction(cdc),
+		GetCmdRevealReservePrice(cdc),
 		GetCmdBurnFungibleTokens(cdc),
 		GetCmdMakeOffer(cdc),
 		GetCmdAcceptOffer(cdc),
@@ -49,7 +54,27 @@ func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 		GetCmdBatchPutOnMarket(cdc),
 		GetCmdBatchRemoveFromMarket(cdc),
 		GetCmdBatchBuyOnMarket(cdc),
+		GetCmdCreateBundle(cdc),
+		GetCmdRemoveBundle(cdc),
 		GetCmdRemoveOffer(cdc),
+		GetCmdMakeCollectionOffer(cdc),
+		GetCmdFillCollectionOffer(cdc),
+		GetCmdRemoveCollectionOffer(cdc),
+		GetCmdProposeSwap(cdc),
+		GetCmdAcceptSwap(cdc),
+		GetCmdCancelSwap(cdc),
+		GetCmdHashLockNFT(cdc),
+		GetCmdClaimHashLockedNFT(cdc),
+		GetCmdReclaimHashLockedNFT(cdc),
+		GetCmdListNFTForRent(cdc),
+		GetCmdRemoveRentalListing(cdc),
+		GetCmdRentNFT(cdc),
+		GetCmdRequestLoan(cdc),
+		GetCmdCancelLoanRequest(cdc),
+		GetCmdFundLoan(cdc),
+		GetCmdRepayLoan(cdc),
+		GetCmdClaimLoanCollateral(cdc),
+		GetCmdMintNFTWithRoyalty(cdc),
 		GetTransferNFTTxCmd(cdc),
 	)...)
 
@@ -117,7 +142,10 @@ func GetCmdBuyNFT(cdc *codec.Codec) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to parse beneficiary address: %v", err)
 			}
-			commission := viper.GetString(types.FlagBeneficiaryCommission)
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
 
 			msg := types.NewMsgBuyNFT(cliCtx.GetFromAddress(), beneficiary, args[0], commission)
 			if err := msg.ValidateBasic(); err != nil {
@@ -127,11 +155,43 @@ func GetCmdBuyNFT(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
 	return cmd
 }
 
+func GetCmdMintNFTWithRoyalty(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mint [denom] [token_id] [recipient] [royalty]",
+		Short: "mint an NFT and get the given share of the price on every resale, e.g. 0.05",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			recipient, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return fmt.Errorf("failed to parse recipient address: %v", err)
+			}
+			royalty, sdkErr := sdk.NewDecFromStr(args[3])
+			if sdkErr != nil {
+				return fmt.Errorf("failed to parse royalty: %v", sdkErr)
+			}
+
+			msg := types.NewMsgMintNFTWithRoyalty(cliCtx.GetFromAddress(), recipient, args[1], args[0],
+				viper.GetString(types.FlagParamTokenURI), royalty)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringP(types.FlagParamTokenURI, types.FlagParamTokenURIShort, "",
+		"URI for supplemental off-chain metadata")
+	return cmd
+}
+
 func GetCmdCreateFungibleToken(cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
 		Use:   "createFT [denom] [amount]",
@@ -229,7 +289,12 @@ func GetCmdPutNFTOnAuction(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "put_on_auction [token_id] [opening_price] [beneficiary] [duration]",
 		Short: "put on auction an NFT (token will be traded in specified time or returned to owner)",
-		Args:  cobra.ExactArgs(4),
+		Long: `Put an NFT on an English auction, where the highest bid at expiry wins,
+or, with --type dutch, on a Dutch auction, where the price falls from the opening price
+to the --floor price and the first buyer pays the current price (with the buyout command),
+or, with --type sealed, on a sealed-bid auction, where bids are committed until expiry
+with a --deposit and revealed during --reveal_duration, and the highest revealed bid wins.`,
+		Args: cobra.ExactArgs(4),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
@@ -256,6 +321,54 @@ func GetCmdPutNFTOnAuction(cdc *codec.Codec) *cobra.Command {
 			}
 
 			msg := types.NewMsgPutNFTOnAuction(cliCtx.GetFromAddress(), beneficiary, args[0], openingPrice, buyoutPrice, time.Now().UTC().Add(dur))
+			switch types.AuctionType(viper.GetString(types.FlagAuctionType)) {
+			case types.AuctionTypeDutch:
+				floorPrice, err := sdk.ParseCoins(viper.GetString(types.FlagFloorPrice))
+				if err != nil {
+					return fmt.Errorf("failed to parse floor price: %v", err)
+				}
+				priceStep, err := time.ParseDuration(viper.GetString(types.FlagPriceStep))
+				if err != nil {
+					return fmt.Errorf("failed to parse price step: %v", err)
+				}
+				msg = types.NewMsgPutNFTOnDutchAuction(cliCtx.GetFromAddress(), beneficiary, args[0], openingPrice,
+					floorPrice, priceStep, time.Now().UTC().Add(dur))
+			case types.AuctionTypeSealed:
+				deposit, err := sdk.ParseCoins(viper.GetString(types.FlagBidDeposit))
+				if err != nil {
+					return fmt.Errorf("failed to parse bid deposit: %v", err)
+				}
+				revealDuration, err := time.ParseDuration(viper.GetString(types.FlagRevealDuration))
+				if err != nil {
+					return fmt.Errorf("failed to parse reveal duration: %v", err)
+				}
+				msg = types.NewMsgPutNFTOnSealedAuction(cliCtx.GetFromAddress(), beneficiary, args[0], openingPrice,
+					deposit, viper.GetBool(types.FlagSecondPrice), time.Now().UTC().Add(dur), revealDuration)
+			}
+			msg.MinBidIncrement, msg.MinBidIncrementRate, err = mputils.ParseMinBidIncrement(viper.GetString(types.FlagMinBidIncrement))
+			if err != nil {
+				return err
+			}
+			msg.ExtensionWindow, err = time.ParseDuration(viper.GetString(types.FlagExtensionWindow))
+			if err != nil {
+				return fmt.Errorf("failed to parse extension window: %v", err)
+			}
+			reserve, err := sdk.ParseCoins(viper.GetString(types.FlagReservePrice))
+			if err != nil {
+				return fmt.Errorf("failed to parse reserve price: %v", err)
+			}
+			if !reserve.Empty() {
+				salt := viper.GetString(types.FlagReserveSalt)
+				if len(salt) == 0 {
+					return fmt.Errorf("reserve price needs a salt to be revealed with")
+				}
+				// only the commitment is broadcast, the reserve price stays secret until it is revealed
+				msg.ReserveHash = types.ReservePriceHash(reserve, salt)
+				msg.RevealDuration, err = time.ParseDuration(viper.GetString(types.FlagRevealDuration))
+				if err != nil {
+					return fmt.Errorf("failed to parse reveal duration: %v", err)
+				}
+			}
 			if err := msg.ValidateBasic(); err != nil {
 				return err
 			}
@@ -265,6 +378,21 @@ func GetCmdPutNFTOnAuction(cdc *codec.Codec) *cobra.Command {
 	}
 	cmd.Flags().StringP(types.FlagParamBuyoutPrice, types.FlagParamBuyoutPriceShort, "",
 		"buyout price for auction lot, if left blank will have no buyout price")
+	cmd.Flags().String(types.FlagAuctionType, string(types.AuctionTypeEnglish), "auction type: english, dutch or sealed")
+	cmd.Flags().String(types.FlagFloorPrice, "", "Dutch auction: the lowest price, reached at expiry")
+	cmd.Flags().String(types.FlagPriceStep, "0s",
+		"Dutch auction: how often the price falls, e.g. 1h, if 0s the price falls continuously")
+	cmd.Flags().String(types.FlagBidDeposit, "", "sealed-bid auction: the deposit locked with every bid")
+	cmd.Flags().String(types.FlagRevealDuration, "0s",
+		"sealed-bid auction or reserve price: how long bids or the reserve price are revealed after expiry")
+	cmd.Flags().Bool(types.FlagSecondPrice, false, "sealed-bid auction: the winner pays the second highest bid")
+	cmd.Flags().String(types.FlagMinBidIncrement, "",
+		"English auction: the minimum raise over the last bid, e.g. 10token or 5%, if left blank any raise is accepted")
+	cmd.Flags().String(types.FlagExtensionWindow, "0s",
+		"English auction: a bid this close to expiry, e.g. 10m, moves the expiry to the time of the bid plus the window")
+	cmd.Flags().String(types.FlagReservePrice, "",
+		"English auction: the lowest price the NFT is sold for, kept secret until revealed with reveal_reserve, if left blank the NFT goes to any bidder")
+	cmd.Flags().String(types.FlagReserveSalt, "", "English auction: the secret salt the reserve price is revealed with")
 	return cmd
 }
 
@@ -310,7 +438,9 @@ func GetCmdMakeBidOnAuction(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "bid [token_id] [beneficiary] [price]",
 		Short: "make a bid for an NFT on auction",
-		Args:  cobra.ExactArgs(3),
+		Long: `Make a bid for an NFT on auction. With --proxy the price is the maximum bid: the bid is raised
+automatically over competing bids up to it, and the rest is refunded when the auction is settled.`,
+		Args: cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
@@ -319,13 +449,59 @@ func GetCmdMakeBidOnAuction(cdc *codec.Codec) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to parse beneficiary address: %v", err)
 			}
-			commission := viper.GetString(types.FlagBeneficiaryCommission)
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
 			price, err := sdk.ParseCoins(args[2])
 			if err != nil {
 				return fmt.Errorf("failed to parse price: %v", err)
 			}
 
 			msg := types.NewMsgMakeBidOnAuction(cliCtx.GetFromAddress(), beneficiary, args[0], price, commission)
+			if viper.GetBool(types.FlagProxyBid) {
+				msg = types.NewMsgMakeProxyBidOnAuction(cliCtx.GetFromAddress(), beneficiary, args[0], price, commission)
+			}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
+		"beneficiary fee, if left blank will be set to default")
+	cmd.Flags().Bool(types.FlagProxyBid, false, "make a proxy bid with the price as the maximum bid")
+	return cmd
+}
+
+func GetCmdCommitSealedBid(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit_bid [token_id] [beneficiary] [price] [salt]",
+		Short: "commit to a bid for an NFT on a sealed-bid auction",
+		Long: `Commit to a bid for an NFT on a sealed-bid auction and lock the bid deposit of the lot.
+Only the hash of the bid and the salt is sent, reveal the bid with the same price and salt
+after bidding closes, otherwise a part of the deposit is forfeited.`,
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			beneficiary, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse beneficiary address: %v", err)
+			}
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
+			price, err := sdk.ParseCoins(args[2])
+			if err != nil {
+				return fmt.Errorf("failed to parse price: %v", err)
+			}
+
+			bidHash := types.SealedBidHash(cliCtx.GetFromAddress(), price, args[3])
+			msg := types.NewMsgCommitSealedBid(cliCtx.GetFromAddress(), beneficiary, args[0], bidHash, commission)
 			if err := msg.ValidateBasic(); err != nil {
 				return err
 			}
@@ -333,11 +509,78 @@ func GetCmdMakeBidOnAuction(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
 	return cmd
 }
 
+func GetCmdRevealSealedBid(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reveal_bid [token_id] [price] [salt]",
+		Short: "reveal a committed bid for an NFT on a sealed-bid auction",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			price, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse price: %v", err)
+			}
+
+			msg := types.NewMsgRevealSealedBid(cliCtx.GetFromAddress(), args[0], price, args[2])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdSettleSealedAuction(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "settle_auction [token_id]",
+		Short: "settle a sealed-bid auction after its reveal window",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			msg := types.NewMsgSettleSealedAuction(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdRevealReservePrice(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reveal_reserve [token_id] [reserve_price] [salt]",
+		Short: "reveal the reserve price of an NFT on auction and settle the auction",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			reserve, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse reserve price: %v", err)
+			}
+
+			msg := types.NewMsgRevealReservePrice(cliCtx.GetFromAddress(), args[0], reserve, args[2])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
 func GetCmdBuyoutFromAuction(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "buyout [token_id] [beneficiary]",
@@ -352,7 +595,10 @@ func GetCmdBuyoutFromAuction(cdc *codec.Codec) *cobra.Command {
 				return fmt.Errorf("failed to parse beneficiary address: %v", err)
 			}
 
-			commission := viper.GetString(types.FlagBeneficiaryCommission)
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
 			msg := types.NewMsgBuyOutOnAuction(cliCtx.GetFromAddress(), beneficiary, args[0], commission)
 			if err := msg.ValidateBasic(); err != nil {
 				return err
@@ -361,7 +607,7 @@ func GetCmdBuyoutFromAuction(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
 	return cmd
 }
@@ -409,8 +655,19 @@ func GetCmdMakeOffer(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			commission := viper.GetString(types.FlagBeneficiaryCommission)
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
 			msg := types.NewMsgMakeOffer(cliCtx.GetFromAddress(), beneficiary, price, args[0], commission)
+			if expiration := viper.GetString(types.FlagOfferExpiration); expiration != "" {
+				duration, err := time.ParseDuration(expiration)
+				if err != nil {
+					return fmt.Errorf("failed to parse expiration: %v", err)
+				}
+				msg.ExpirationTime = time.Now().UTC().Add(duration)
+			}
+			msg.ExpirationHeight = viper.GetInt64(types.FlagOfferExpirationHeight)
 			if err := msg.ValidateBasic(); err != nil {
 				return err
 			}
@@ -418,8 +675,12 @@ func GetCmdMakeOffer(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
+	cmd.Flags().String(types.FlagOfferExpiration, "",
+		"the offer is refunded after this duration, e.g. 24h, if left blank the offer does not expire")
+	cmd.Flags().Int64(types.FlagOfferExpirationHeight, 0,
+		"the offer is refunded at this block height, if 0 the offer does not expire")
 	return cmd
 }
 
@@ -439,7 +700,10 @@ func GetCmdAcceptOffer(cdc *codec.Codec) *cobra.Command {
 
 			tokenID, offerID := args[0], args[1]
 
-			commission := viper.GetString(types.FlagBeneficiaryCommission)
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
 			msg := types.NewMsgAcceptOffer(cliCtx.GetFromAddress(), beneficiary, tokenID, offerID, commission)
 			if err := msg.ValidateBasic(); err != nil {
 				return err
@@ -453,7 +717,7 @@ func GetCmdAcceptOffer(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
 	return cmd
 }
@@ -478,6 +742,444 @@ func GetCmdRemoveOffer(cdc *codec.Codec) *cobra.Command {
 	return cmd
 }
 
+func GetCmdMakeCollectionOffer(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "collection_offer [denom] [price] [beneficiary]",
+		Short: "offer a price for each of a number of NFTs of a denom, any owner of an NFT of the denom can fill the offer",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			beneficiary, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return fmt.Errorf("failed to parse beneficiary address: %v", err)
+			}
+
+			price, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
+			msg := types.NewMsgMakeCollectionOffer(cliCtx.GetFromAddress(), beneficiary, args[0], price,
+				viper.GetUint64(types.FlagQuantity), commission)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
+		"beneficiary fee, if left blank will be set to default")
+	cmd.Flags().Uint64(types.FlagQuantity, 1, "the number of NFTs wanted, the price is locked for each of them")
+	return cmd
+}
+
+func GetCmdFillCollectionOffer(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fill_collection_offer [token_id] [offer_id] [beneficiary]",
+		Short: "sell an NFT to a collection offer for its denom",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			beneficiary, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return fmt.Errorf("failed to parse beneficiary address: %v", err)
+			}
+
+			tokenID, offerID := args[0], args[1]
+
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
+			msg := types.NewMsgFillCollectionOffer(cliCtx.GetFromAddress(), beneficiary, tokenID, offerID, commission)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+			txBldr, err = utils.EnrichWithGas(txBldr, cliCtx, []sdk.Msg{msg})
+			if err != nil {
+				return err
+			}
+			txBldr = txBldr.WithGas(5 * txBldr.Gas())
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
+		"beneficiary fee, if left blank will be set to default")
+	return cmd
+}
+
+func GetCmdRemoveCollectionOffer(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove_collection_offer [offer_id]",
+		Short: "remove a collection offer and get back the coins locked for the NFTs still wanted",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgRemoveCollectionOffer(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdProposeSwap(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "propose_swap [counterparty]",
+		Short: "propose to swap your tokens and coins for tokens and coins of the counterparty",
+		Long: `Propose to swap tokens and coins given for tokens and coins wanted from the counterparty.
+The tokens and coins given are held by the marketplace until the counterparty accepts the swap,
+you cancel it or it expires.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			counterparty, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to parse counterparty address: %v", err)
+			}
+
+			giveCoins, err := sdk.ParseCoins(viper.GetString(types.FlagSwapGiveCoins))
+			if err != nil {
+				return err
+			}
+			wantCoins, err := sdk.ParseCoins(viper.GetString(types.FlagSwapWantCoins))
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgProposeSwap(cliCtx.GetFromAddress(), counterparty,
+				splitTokenIDs(viper.GetString(types.FlagSwapGiveNFTs)), giveCoins,
+				splitTokenIDs(viper.GetString(types.FlagSwapWantNFTs)), wantCoins)
+			if expiration := viper.GetString(types.FlagOfferExpiration); expiration != "" {
+				duration, err := time.ParseDuration(expiration)
+				if err != nil {
+					return fmt.Errorf("failed to parse expiration: %v", err)
+				}
+				msg.ExpirationTime = time.Now().UTC().Add(duration)
+			}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(types.FlagSwapGiveNFTs, "", "comma-separated IDs of your tokens to give")
+	cmd.Flags().String(types.FlagSwapGiveCoins, "", "coins to give")
+	cmd.Flags().String(types.FlagSwapWantNFTs, "", "comma-separated IDs of the tokens of the counterparty wanted")
+	cmd.Flags().String(types.FlagSwapWantCoins, "", "coins wanted from the counterparty")
+	cmd.Flags().String(types.FlagOfferExpiration, "",
+		"the swap is cancelled after this duration, e.g. 24h, if left blank the swap does not expire")
+	return cmd
+}
+
+func GetCmdAcceptSwap(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "accept_swap [swap_id]",
+		Short: "accept a swap proposed to you, both sides are exchanged at once",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgAcceptSwap(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdCancelSwap(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel_swap [swap_id]",
+		Short: "cancel a swap you proposed and get back the tokens and coins it holds",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgCancelSwap(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdHashLockNFT(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "hash_lock [token_id] [recipient] [hash_lock] [timeout]",
+		Short: "lock a token for the recipient against the hex encoded SHA-256 hash of a secret for a duration, e.g. 24h",
+		Long: `Lock a token for a hash time-locked transfer. The recipient gets the token by revealing the secret
+the hash lock is the SHA-256 hash of before the timeout, after the timeout you can reclaim the token.`,
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			recipient, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse recipient address: %v", err)
+			}
+
+			duration, err := time.ParseDuration(args[3])
+			if err != nil {
+				return fmt.Errorf("failed to parse timeout: %v", err)
+			}
+
+			msg := types.NewMsgHashLockNFT(cliCtx.GetFromAddress(), recipient, args[0], args[2],
+				time.Now().UTC().Add(duration))
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdClaimHashLockedNFT(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "claim_hash_lock [token_id] [preimage]",
+		Short: "get a token locked for you by revealing the hex encoded secret of its hash lock",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgClaimHashLockedNFT(cliCtx.GetFromAddress(), args[0], args[1])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdReclaimHashLockedNFT(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reclaim_hash_lock [token_id]",
+		Short: "unlock a hash-locked token that was not claimed before the timeout",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgReclaimHashLockedNFT(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdListNFTForRent(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list_for_rent [token_id] [price] [unit] [max_duration]",
+		Short: "offer the usage rights of a token for rent at a price per block or per hour for up to max_duration units",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			price, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse price: %v", err)
+			}
+
+			maxDuration, err := strconv.ParseUint(args[3], 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse max duration: %v", err)
+			}
+
+			msg := types.NewMsgListNFTForRent(cliCtx.GetFromAddress(), args[0], price, args[2], maxDuration)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdRemoveRentalListing(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove_rental_listing [token_id]",
+		Short: "stop offering a token for rent, a current rental is not affected",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgRemoveRentalListing(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdRentNFT(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rent [token_id] [duration]",
+		Short: "rent a token listed for rent for a duration in the unit of its listing",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			duration, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse duration: %v", err)
+			}
+
+			msg := types.NewMsgRentNFT(cliCtx.GetFromAddress(), args[0], duration)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdRequestLoan(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "request_loan [token_id] [principal] [interest] [duration]",
+		Short: "put a token up as collateral for a loan of the principal to be repaid with the interest within a duration, e.g. 720h",
+		Long: `Request a loan backed by a token. The token is held as collateral until the loan is repaid,
+once a lender funds the principal you must repay the principal and the interest within the duration,
+otherwise the lender can claim the token. Pass "" as the interest for a loan without interest.`,
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			principal, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse principal: %v", err)
+			}
+
+			interest, err := sdk.ParseCoins(args[2])
+			if err != nil {
+				return fmt.Errorf("failed to parse interest: %v", err)
+			}
+
+			duration, err := time.ParseDuration(args[3])
+			if err != nil {
+				return fmt.Errorf("failed to parse duration: %v", err)
+			}
+
+			msg := types.NewMsgRequestLoan(cliCtx.GetFromAddress(), args[0], principal, interest, duration)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdCancelLoanRequest(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel_loan_request [loan_id]",
+		Short: "cancel a loan request that has not been funded and get the token back",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgCancelLoanRequest(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdFundLoan(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "fund_loan [loan_id]",
+		Short: "pay the principal of a requested loan to the borrower",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgFundLoan(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdRepayLoan(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "repay_loan [loan_id]",
+		Short: "repay the principal and the interest of your loan and get the token back",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgRepayLoan(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdClaimLoanCollateral(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "claim_collateral [loan_id]",
+		Short: "get the token of a loan you funded that was not repaid by the deadline",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			msg := types.NewMsgClaimLoanCollateral(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// splitTokenIDs splits a comma-separated list of token IDs, an empty list gives no IDs.
+func splitTokenIDs(ids string) []string {
+	if strings.TrimSpace(ids) == "" {
+		return nil
+	}
+	return strings.Split(ids, ",")
+}
+
 func GetCmdBatchTransfer(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "batch_transfer [recipient] [tokenIDs]",
@@ -503,11 +1205,51 @@ func GetCmdBatchTransfer(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
 	return cmd
 }
 
+func GetCmdCreateBundle(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create_bundle [bundle_id] [tokenIDs]",
+		Short: "bundle several tokens into one that is sold as a single unit",
+		Long: `Bundle several tokens into a new token with the given ID. The bundle is put on the market
+or on auction like any other token, and the buyer gets all of its tokens.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			msg := types.NewMsgCreateBundle(cliCtx.GetFromAddress(), args[0], strings.Split(args[1], ","))
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func GetCmdRemoveBundle(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove_bundle [bundle_id]",
+		Short: "remove a bundle that is not on sale and get its tokens back",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			msg := types.NewMsgRemoveBundle(cliCtx.GetFromAddress(), args[0])
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
 func GetCmdBatchPutOnMarket(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "batch_put_on_market [beneficiary] [tokenPrices]",
@@ -597,7 +1339,10 @@ func GetCmdBatchBuyOnMarket(cdc *codec.Codec) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
-			commission := viper.GetString(types.FlagBeneficiaryCommission)
+			commission, err := mputils.ParseBeneficiaryCommission(cliCtx, viper.GetString(types.FlagBeneficiaryCommission))
+			if err != nil {
+				return err
+			}
 
 			beneficiary, err := sdk.AccAddressFromBech32(args[0])
 			if err != nil {
@@ -621,7 +1366,7 @@ func GetCmdBatchBuyOnMarket(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
-	cmd.Flags().Float64P(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, types.DefaultBeneficiariesCommission,
+	cmd.Flags().StringP(types.FlagBeneficiaryCommission, types.FlagBeneficiaryCommissionShort, "",
 		"beneficiary fee, if left blank will be set to default")
 	return cmd
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
 	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	"github.com/cosmos/modules/incubator/nft"
+	mputils "github.com/corestario/marketplace/x/marketplace/client/utils"
 	"github.com/corestario/marketplace/x/marketplace/types"
 
 	"github.com/gorilla/mux"
@@ -33,6 +34,22 @@ func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, storeName string)
 
 	r.HandleFunc(fmt.Sprintf("/%s/auction_lots", storeName), auctionLotsHandler(cliCtx, storeName)).Methods("GET")
 	r.HandleFunc(fmt.Sprintf("/%s/auction_lots/{%s}", storeName, restName), auctionLotHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/auction_lots/{%s}/price", storeName, restName), auctionPriceHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/auction_bids/{%s}", storeName, restName), auctionBidsHandler(cliCtx, storeName)).Methods("GET")
+
+	r.HandleFunc(fmt.Sprintf("/%s/offers/{%s}", storeName, restName), offersHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/buyer_offers/{%s}", storeName, restName), buyerOffersHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/collection_offers/{%s}", storeName, restName), collectionOffersHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/swap/{%s}", storeName, restName), swapHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/swaps/{%s}", storeName, restName), swapsHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/hash_lock/{%s}", storeName, restName), hashLockHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/rental_listing/{%s}", storeName, restName), rentalListingHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/nft_user/{%s}", storeName, restName), nftUserHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/rental_history/{%s}", storeName, restName), rentalHistoryHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/loan/{%s}", storeName, restName), loanHandler(cliCtx, storeName)).Methods("GET")
+	r.HandleFunc(fmt.Sprintf("/%s/loans/{%s}", storeName, restName), loansHandler(cliCtx, storeName)).Methods("GET")
+
+	r.HandleFunc(fmt.Sprintf("/%s/params", storeName), paramsHandler(cliCtx, storeName)).Methods("GET")
 
 	r.HandleFunc(fmt.Sprintf("/%s/mint", storeName), mintHandler(cliCtx)).Methods("PUT")
 	r.HandleFunc(fmt.Sprintf("/%s/transfer", storeName), transferHandler(cliCtx)).Methods("PUT")
@@ -328,8 +345,14 @@ func buyHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		commission, err := mputils.ParseBeneficiaryCommission(cliCtx, req.Commission)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// create the message
-		msg := types.NewMsgBuyNFT(owner, beneficiary, req.TokenID, req.Commission)
+		msg := types.NewMsgBuyNFT(owner, beneficiary, req.TokenID, commission)
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -619,6 +642,18 @@ type PutOnAuctionReq struct {
 	OpeningPrice string `json:"opening_price"`
 	BuyoutPrice  string `json:"buyout_price,omitempty"`
 	Duration     string `json:"duration"`
+	AuctionType  string `json:"auction_type,omitempty"`
+	FloorPrice   string `json:"floor_price,omitempty"`
+	PriceStep    string `json:"price_step,omitempty"`
+	// sealed-bid auction only
+	BidDeposit     string `json:"bid_deposit,omitempty"`
+	RevealDuration string `json:"reveal_duration,omitempty"`
+	SecondPrice    bool   `json:"second_price,omitempty"`
+	// English auction only, e.g. 10token or 5%
+	MinBidIncrement string `json:"min_bid_increment,omitempty"`
+	ExtensionWindow string `json:"extension_window,omitempty"`
+	// ReservePriceHash of the reserve price and a secret salt, revealed within reveal_duration after expiry
+	ReserveHash string `json:"reserve_hash,omitempty"`
 }
 
 func putOnAuctionHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -665,6 +700,53 @@ func putOnAuctionHandler(cliCtx context.CLIContext) http.HandlerFunc {
 		}
 		// create the message
 		msg := types.NewMsgPutNFTOnAuction(owner, beneficiary, req.TokenID, coins, buyout, time.Now().UTC().Add(dur))
+		switch types.AuctionType(req.AuctionType) {
+		case types.AuctionTypeDutch:
+			floor, err := sdk.ParseCoins(req.FloorPrice)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			var step time.Duration
+			if req.PriceStep != "" {
+				if step, err = time.ParseDuration(req.PriceStep); err != nil {
+					rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+					return
+				}
+			}
+			msg = types.NewMsgPutNFTOnDutchAuction(owner, beneficiary, req.TokenID, coins, floor, step, time.Now().UTC().Add(dur))
+		case types.AuctionTypeSealed:
+			deposit, err := sdk.ParseCoins(req.BidDeposit)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			revealDuration, err := time.ParseDuration(req.RevealDuration)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			msg = types.NewMsgPutNFTOnSealedAuction(owner, beneficiary, req.TokenID, coins, deposit, req.SecondPrice,
+				time.Now().UTC().Add(dur), revealDuration)
+		}
+		msg.MinBidIncrement, msg.MinBidIncrementRate, err = mputils.ParseMinBidIncrement(req.MinBidIncrement)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if req.ExtensionWindow != "" {
+			if msg.ExtensionWindow, err = time.ParseDuration(req.ExtensionWindow); err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if req.ReserveHash != "" {
+			msg.ReserveHash = req.ReserveHash
+			if msg.RevealDuration, err = time.ParseDuration(req.RevealDuration); err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -762,6 +844,8 @@ type BidOnAuctionReq struct {
 	Beneficiary string `json:"beneficiary"`
 	Bid         string `json:"bid"`
 	Commission  string `json:"commission,omitempty"`
+	// Proxy makes Bid the maximum bid of a proxy bid.
+	Proxy bool `json:"proxy,omitempty"`
 }
 
 func bidOnAuctionHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -796,8 +880,17 @@ func bidOnAuctionHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		commission, err := mputils.ParseBeneficiaryCommission(cliCtx, req.Commission)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// create the message
-		msg := types.NewMsgMakeBidOnAuction(owner, beneficiary, req.TokenID, bid, req.Commission)
+		msg := types.NewMsgMakeBidOnAuction(owner, beneficiary, req.TokenID, bid, commission)
+		if req.Proxy {
+			msg = types.NewMsgMakeProxyBidOnAuction(owner, beneficiary, req.TokenID, bid, commission)
+		}
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -849,8 +942,14 @@ func buyoutAuctionHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		commission, err := mputils.ParseBeneficiaryCommission(cliCtx, req.Commission)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// create the message
-		msg := types.NewMsgBuyOutOnAuction(owner, beneficiary, req.TokenID, req.Commission)
+		msg := types.NewMsgBuyOutOnAuction(owner, beneficiary, req.TokenID, commission)
 		err = msg.ValidateBasic()
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -869,7 +968,13 @@ func buyoutAuctionHandler(cliCtx context.CLIContext) http.HandlerFunc {
 
 func nftsHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/nfts", storeName), nil)
+		params, err := mputils.ParseQueryNFTsParams(r.URL.Query().Get)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/nfts", storeName), cliCtx.Codec.MustMarshalJSON(params))
 		if err != nil {
 			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
 			return
@@ -927,6 +1032,17 @@ func auctionLotsHandler(cliCtx context.CLIContext, storeName string) http.Handle
 	}
 }
 
+func paramsHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/params", storeName), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
 func auctionLotHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -940,3 +1056,190 @@ func auctionLotHandler(cliCtx context.CLIContext, storeName string) http.Handler
 		rest.PostProcessResponse(w, cliCtx, res)
 	}
 }
+
+func auctionBidsHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		path := fmt.Sprintf("custom/%s/auction_bids/%s", storeName, nftID)
+		// the latest auction of the NFT is queried unless another one is selected
+		if auction := r.URL.Query().Get("auction"); auction != "" {
+			path = fmt.Sprintf("%s/%s", path, auction)
+		}
+		res, _, err := cliCtx.QueryWithData(path, nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func offersHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/offers/%s", storeName, nftID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func buyerOffersHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		buyer := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/buyer_offers/%s", storeName, buyer), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func collectionOffersHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		denom := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/collection_offers/%s", storeName, denom), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func swapHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		swapID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/swap/%s", storeName, swapID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func swapsHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/swaps/%s", storeName, address), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func hashLockHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/hash_lock/%s", storeName, nftID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func rentalListingHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/rental_listing/%s", storeName, nftID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func nftUserHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/nft_user/%s", storeName, nftID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func rentalHistoryHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/rental_history/%s", storeName, nftID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func loanHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		loanID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/loan/%s", storeName, loanID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func loansHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		address := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/loans/%s", storeName, address), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func auctionPriceHandler(cliCtx context.CLIContext, storeName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		nftID := vars[restName]
+		res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/auction_price/%s", storeName, nftID), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ParseBeneficiaryCommission parses a beneficiary commission given by the user.
+// A blank commission is replaced with the BeneficiariesCommission param of the chain.
+func ParseBeneficiaryCommission(cliCtx context.CLIContext, commission string) (sdk.Dec, error) {
+	if commission != "" {
+		parsed, err := sdk.NewDecFromStr(commission)
+		if err != nil {
+			return sdk.Dec{}, fmt.Errorf("failed to parse beneficiary commission: %v", err)
+		}
+		return parsed, nil
+	}
+
+	res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/params", types.ModuleName), nil)
+	if err != nil {
+		return sdk.Dec{}, fmt.Errorf("failed to query default beneficiary commission: %v", err)
+	}
+	var params types.Params
+	if err := cliCtx.Codec.UnmarshalJSON(res, &params); err != nil {
+		return sdk.Dec{}, err
+	}
+	return params.BeneficiariesCommission, nil
+}
+
+// ParseQueryNFTsParams parses the filters of the NFTs query, get returns the value given by the user
+// for a filter flag or an empty string. Blank page and limit are set to defaults by the querier.
+func ParseQueryNFTsParams(get func(key string) string) (types.QueryNFTsParams, error) {
+	var (
+		params types.QueryNFTsParams
+		err    error
+	)
+	if page := get(types.FlagPage); page != "" {
+		if params.Page, err = strconv.Atoi(page); err != nil {
+			return params, fmt.Errorf("failed to parse page: %v", err)
+		}
+	}
+	if limit := get(types.FlagLimit); limit != "" {
+		if params.Limit, err = strconv.Atoi(limit); err != nil {
+			return params, fmt.Errorf("failed to parse limit: %v", err)
+		}
+	}
+	if owner := get(types.FlagOwner); owner != "" {
+		if params.Owner, err = sdk.AccAddressFromBech32(owner); err != nil {
+			return params, fmt.Errorf("failed to parse owner address: %v", err)
+		}
+	}
+	if minPrice := get(types.FlagMinPrice); minPrice != "" {
+		if params.MinPrice, err = sdk.ParseCoins(minPrice); err != nil {
+			return params, fmt.Errorf("failed to parse minimum price: %v", err)
+		}
+	}
+	if maxPrice := get(types.FlagMaxPrice); maxPrice != "" {
+		if params.MaxPrice, err = sdk.ParseCoins(maxPrice); err != nil {
+			return params, fmt.Errorf("failed to parse maximum price: %v", err)
+		}
+	}
+	params.Status = get(types.FlagStatus)
+	params.Denom = get(types.FlagDenom)
+	params.SortBy = get(types.FlagSortBy)
+	return params, nil
+}
+
+// ParseMinBidIncrement parses the minimum bid increment of an auction lot given by the user,
+// either coins or a percentage of the last bid such as "5%". A blank increment is no increment.
+func ParseMinBidIncrement(increment string) (sdk.Coins, sdk.Dec, error) {
+	if !strings.HasSuffix(increment, "%") {
+		coins, err := sdk.ParseCoins(increment)
+		if err != nil {
+			return nil, sdk.Dec{}, fmt.Errorf("failed to parse minimum bid increment: %v", err)
+		}
+		return coins, sdk.ZeroDec(), nil
+	}
+	percent, err := sdk.NewDecFromStr(strings.TrimSuffix(increment, "%"))
+	if err != nil {
+		return nil, sdk.Dec{}, fmt.Errorf("failed to parse minimum bid increment: %v", err)
+	}
+	return nil, percent.QuoInt64(100), nil
+}
@@ -0,0 +1,116 @@
+package marketplace_test
+
+import (
+	"testing"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestCollectionOffer(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	creator, seller, buyer, other := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	nftHandler := marketplace.CustomNFTHandler(mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+
+	// three swords of the seller, all with a 10% royalty to the creator, and a shield
+	var swords []string
+	for i := 0; i < 3; i++ {
+		mintMsg := types.NewMsgMintNFTWithRoyalty(creator, seller, uuid.New().String(), "swords", "", sdk.NewDecWithPrec(1, 1))
+		require.True(t, handler(ctx, *mintMsg).IsOK())
+		swords = append(swords, mintMsg.ID)
+	}
+	shieldMsg := nft.NewMsgMintNFT(seller, seller, uuid.New().String(), "shields", "")
+	require.True(t, nftHandler(ctx, shieldMsg).IsOK())
+
+	before := getBalances(mpKeeperTest, creator, buyer, other)
+	offer := func(buyer sdk.AccAddress, price int64, quantity uint64) string {
+		result := handler(ctx, *types.NewMsgMakeCollectionOffer(buyer, seller, "swords", coins(price), quantity, defaultCommission))
+		require.True(t, result.IsOK(), result.Log)
+		return string(result.Data)
+	}
+	fill := func(tokenID, offerID string) sdk.Result {
+		return handler(ctx, *types.NewMsgFillCollectionOffer(seller, seller, tokenID, offerID, defaultCommission))
+	}
+	requireOffers := func(expected map[string]uint64, order ...string) {
+		bz, sdkErr := querier(ctx, []string{marketplace.QueryCollectionOffers, "swords"}, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		var res types.QueryResCollectionOffers
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		var ids []string
+		for _, offer := range res.Offers {
+			ids = append(ids, offer.ID)
+			require.Equal(t, expected[offer.ID], offer.Quantity)
+		}
+		require.Equal(t, order, ids)
+
+		msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+		require.False(t, broken, msgInv)
+	}
+
+	// the price is locked for every NFT wanted, offers are listed highest price first
+	floorOffer := offer(buyer, 50, 2)
+	require.Equal(t, before[1]-100, getBalances(mpKeeperTest, buyer)[0])
+	highOffer := offer(other, 70, 0)
+	requireOffers(map[string]uint64{floorOffer: 2, highOffer: 1}, highOffer, floorOffer)
+
+	// only an owner of an NFT of the denom that is not on sale fills the offer
+	require.False(t, fill(shieldMsg.ID, floorOffer).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgFillCollectionOffer(buyer, buyer, swords[0], floorOffer, defaultCommission)).IsOK())
+	result := handler(ctx, *types.NewMsgPutOnMarketNFT(seller, seller, swords[0], coins(100)))
+	require.True(t, result.IsOK(), result.Log)
+	require.False(t, fill(swords[0], floorOffer).IsOK())
+
+	// every fill pays the royalty and hands the NFT over until the quantity is used up
+	for _, id := range swords[1:] {
+		result = fill(id, floorOffer)
+		require.True(t, result.IsOK(), result.Log)
+		token, err := keeper.GetNFT(ctx, id)
+		require.Nil(t, err)
+		require.True(t, token.Owner.Equals(buyer))
+	}
+	require.Equal(t, before[0]+10, getBalances(mpKeeperTest, creator)[0])
+	require.Equal(t, before[1]-100, getBalances(mpKeeperTest, buyer)[0])
+	requireOffers(map[string]uint64{highOffer: 1}, highOffer)
+	require.False(t, fill(swords[1], floorOffer).IsOK())
+
+	// only the buyer removes the offer and gets the locked coins back
+	require.False(t, handler(ctx, *types.NewMsgRemoveCollectionOffer(buyer, highOffer)).IsOK())
+	result = handler(ctx, *types.NewMsgRemoveCollectionOffer(other, highOffer))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, before[2], getBalances(mpKeeperTest, other)[0])
+	requireOffers(nil)
+}
+
+func TestMakeCollectionOfferValidateBasic(t *testing.T) {
+	denom := types.DefaultTokenDenom
+	addr := sdk.AccAddress([]byte("buyer"))
+	price := sdk.NewCoins(sdk.NewInt64Coin(denom, 50))
+
+	require.Nil(t, types.NewMsgMakeCollectionOffer(addr, addr, "swords", price, 0, defaultCommission).ValidateBasic())
+	require.Equal(t, uint64(1), types.NewMsgMakeCollectionOffer(addr, addr, "swords", price, 0, defaultCommission).GetQuantity())
+
+	for name, msg := range map[string]*types.MsgMakeCollectionOffer{
+		"no buyer":      types.NewMsgMakeCollectionOffer(sdk.AccAddress{}, addr, "swords", price, 1, defaultCommission),
+		"no denom":      types.NewMsgMakeCollectionOffer(addr, addr, " ", price, 1, defaultCommission),
+		"no price":      types.NewMsgMakeCollectionOffer(addr, addr, "swords", sdk.Coins{}, 1, defaultCommission),
+		"zero price":    types.NewMsgMakeCollectionOffer(addr, addr, "swords", sdk.Coins{sdk.NewInt64Coin(denom, 0)}, 1, defaultCommission),
+		"many NFTs":     types.NewMsgMakeCollectionOffer(addr, addr, "swords", price, types.MaxOfferQuantity+1, defaultCommission),
+		"no commission": types.NewMsgMakeCollectionOffer(addr, addr, "swords", price, 1, sdk.Dec{}),
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+}
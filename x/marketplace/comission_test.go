@@ -10,7 +10,6 @@ import (
 	app "github.com/corestario/marketplace"
 	"github.com/corestario/marketplace/common"
 	"github.com/corestario/marketplace/x/marketplace"
-	"github.com/corestario/marketplace/x/marketplace/config"
 	"github.com/corestario/marketplace/x/marketplace/types"
 	"github.com/cosmos/cosmos-sdk/store"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -36,15 +35,20 @@ import (
 	tmTypes "github.com/tendermint/tendermint/types"
 )
 
+// defaultCommission is the beneficiary commission used by the tests that do not check commissions
+var defaultCommission = types.DefaultParams().BeneficiariesCommission
+
 type marketplaceKeeperTest struct {
 	ctx sdk.Context
 
 	accountKeeper  auth.AccountKeeper
 	bankKeeper     bank.Keeper
+	faultyBank     *faultyBankKeeper
 	stakingKeeper  staking.Keeper
 	distrKeeper    distr.Keeper
 	slashingKeeper slashing.Keeper
 	supplyKeeper   supply.Keeper
+	paramsKeeper   params.Keeper
 	ms             store.CommitMultiStore
 	marketKeeper   *marketplace.Keeper
 	nftKeeper      *nft.Keeper
@@ -85,11 +89,13 @@ func createMarketplaceKeeperTest() (*marketplaceKeeperTest, error) {
 	keySupply := sdk.NewKVStoreKey(supply.StoreKey)
 	keyAuctionStore := sdk.NewKVStoreKey(marketplace.AuctionKey)
 	keyDeletedNFT := sdk.NewKVStoreKey(marketplace.DeletedNFTKey)
+	keyOffer := sdk.NewKVStoreKey(marketplace.OfferKey)
 	keyNFT := sdk.NewKVStoreKey(nft.StoreKey)
 	keyRegisterCurrency := sdk.NewKVStoreKey(marketplace.RegisterCurrencyKey)
 	keyIBC := sdk.NewKVStoreKey(ibc.StoreKey)
 
 	paramsKeeper := params.NewKeeper(cdc, keyParams, tkeyParams, params.DefaultCodespace)
+	mpKeeperTest.paramsKeeper = paramsKeeper
 
 	authSubspace := paramsKeeper.Subspace(auth.DefaultParamspace)
 	bankSupspace := paramsKeeper.Subspace(bank.DefaultParamspace)
@@ -110,6 +116,8 @@ func createMarketplaceKeeperTest() (*marketplaceKeeperTest, error) {
 		bank.DefaultCodespace,
 		nil,
 	)
+	// the marketplace and the supply keepers pay through a bank keeper whose payments can be made to fail
+	mpKeeperTest.faultyBank = &faultyBankKeeper{Keeper: mpKeeperTest.bankKeeper}
 
 	maccPerms := map[string][]string{
 		auth.FeeCollectorName:              nil,
@@ -120,10 +128,11 @@ func createMarketplaceKeeperTest() (*marketplaceKeeperTest, error) {
 		staking.NotBondedPoolName:          {supply.Burner, supply.Staking},
 		bank.ModuleName:                    {supply.Minter, supply.Burner, supply.Staking},
 		ibctransfer.GetModuleAccountName(): {supply.Minter, supply.Burner},
+		marketplace.ModuleName:             nil,
 	}
 
 	mpKeeperTest.supplyKeeper = supply.NewKeeper(cdc, keySupply, mpKeeperTest.accountKeeper,
-		mpKeeperTest.bankKeeper, maccPerms)
+		mpKeeperTest.faultyBank, maccPerms)
 
 	mpKeeperTest.stakingKeeper = staking.NewKeeper(
 		cdc,
@@ -169,6 +178,9 @@ func createMarketplaceKeeperTest() (*marketplaceKeeperTest, error) {
 	mpKeeperTest.ms.MountStoreWithDB(keyAuctionStore, sdk.StoreTypeIAVL, db)
 	mpKeeperTest.ms.MountStoreWithDB(keySupply, sdk.StoreTypeIAVL, db)
 	mpKeeperTest.ms.MountStoreWithDB(keyIBC, sdk.StoreTypeIAVL, db)
+	mpKeeperTest.ms.MountStoreWithDB(keyOffer, sdk.StoreTypeIAVL, db)
+	mpKeeperTest.ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	mpKeeperTest.ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
 
 	if err := mpKeeperTest.ms.LoadLatestVersion(); err != nil {
 		return nil, err
@@ -185,15 +197,16 @@ func createMarketplaceKeeperTest() (*marketplaceKeeperTest, error) {
 	metr := &common.MsgMetrics{NumMsgs: prometheus.NewCounterVec(prometheus.CounterOpts{},
 		[]string{common.PrometheusLabelStatus, common.PrometheusLabelMsgType})}
 	mpKeeperTest.marketKeeper = marketplace.NewKeeper(
-		mpKeeperTest.bankKeeper,
+		mpKeeperTest.faultyBank,
 		mpKeeperTest.stakingKeeper,
 		mpKeeperTest.distrKeeper,
 		mpStore,
 		keyRegisterCurrency,
 		keyAuctionStore,
 		keyDeletedNFT,
+		keyOffer,
 		cdc,
-		config.DefaultMPServerConfig(),
+		paramsKeeper.Subspace(marketplace.DefaultParamspace),
 		metr,
 		mpKeeperTest.nftKeeper,
 		&mpKeeperTest.supplyKeeper,
@@ -201,8 +214,10 @@ func createMarketplaceKeeperTest() (*marketplaceKeeperTest, error) {
 		&mpKeeperTest.ibcKeeper,
 	)
 
-	mpKeeperTest.ctx = sdk.NewContext(mpKeeperTest.ms, abci.Header{}, false, log.NewNopLogger())
+	mpKeeperTest.ctx = sdk.NewContext(mpKeeperTest.ms, abci.Header{Time: time.Now().UTC()}, false, log.NewNopLogger())
 	mpKeeperTest.marketKeeper.RegisterBasicDenoms(mpKeeperTest.ctx)
+	mpKeeperTest.marketKeeper.SetParams(mpKeeperTest.ctx, types.DefaultParams())
+	mpKeeperTest.distrKeeper.SetFeePool(mpKeeperTest.ctx, distr.InitialFeePool())
 	return mpKeeperTest, nil
 }
 
@@ -240,6 +255,30 @@ func (mp *marketplaceKeeperTest) updateVoteInfos(validatorsCount int, coins sdk.
 	return voteInfos, nil
 }
 
+// coins returns the given amount of the default token
+func coins(amount int64) sdk.Coins {
+	return sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(amount)))
+}
+
+// mintNFT mints a new NFT of the default denom to the owner and returns its ID
+func mintNFT(t *testing.T, mp *marketplaceKeeperTest, owner sdk.AccAddress) string {
+	msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), types.DefaultTokenDenom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mp.ctx, msg, mp.nftKeeper, mp.marketKeeper)
+	require.True(t, result.IsOK(), result.Log)
+	return msg.ID
+}
+
+// requireOwner checks the owner of the NFT in both the marketplace and the nft module, and its marketplace status
+func requireOwner(t *testing.T, mp *marketplaceKeeperTest, owner sdk.AccAddress, status types.NFTStatus, id string) {
+	token, err := mp.marketKeeper.GetNFT(mp.ctx, id)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(owner))
+	require.Equal(t, status, token.Status)
+	baseToken, err := mp.nftKeeper.GetNFT(mp.ctx, types.DefaultTokenDenom, id)
+	require.Nil(t, err)
+	require.True(t, baseToken.GetOwner().Equals(owner))
+}
+
 type testBuyPutOnMarketNFTData struct {
 	numberOfCoins   int64
 	priceOfToken    int64
@@ -286,10 +325,21 @@ func TestBuyPutOnMarketNFT(t *testing.T) {
 		result = handler(mpKeeperTest.ctx, *putOnMarketNFTMsg)
 		require.True(t, result.IsOK())
 
-		buyNFTMsg := types.NewMsgBuyNFT(mpKeeperTest.addrs[1], mpKeeperTest.addrs[3], msg.ID, "")
+		distrAddr := supply.NewModuleAddress(distr.ModuleName)
+		before := getBalances(mpKeeperTest, append(mpKeeperTest.addrs, distrAddr)...)
+		buyNFTMsg := types.NewMsgBuyNFT(mpKeeperTest.addrs[1], mpKeeperTest.addrs[3], msg.ID, defaultCommission)
 		result = handler(mpKeeperTest.ctx, *buyNFTMsg)
 		require.True(t, result.IsOK())
 
+		// the validators commission is sent to the distribution module account, no coins are lost
+		after := getBalances(mpKeeperTest, append(mpKeeperTest.addrs, distrAddr)...)
+		var total int64
+		for i := range before {
+			total += after[i] - before[i]
+		}
+		require.Zero(t, total)
+		require.True(t, after[4] > before[4])
+
 		// check seller's balance
 		require.Equal(t, data.expectedSellerAmount,
 			mpKeeperTest.bankKeeper.GetCoins(mpKeeperTest.ctx, mpKeeperTest.addrs[0]).AmountOf(denom).Int64())
@@ -311,20 +361,20 @@ func TestBuyPutOnMarketNFT(t *testing.T) {
 type commissionTestData struct {
 	amount     int64
 	commission int64
-	rat        float64
+	rat        sdk.Dec
 }
 
 func TestCommission(t *testing.T) {
 	denom := types.DefaultTokenDenom
 
 	testData := []commissionTestData{
-		{100, 1, 0.01},
-		{200, 2, 0.01},
-		{365, 3, 0.01},
-		{1000, 500, 0.5},
-		{1488, 982, 0.66},
-		{10, 0, 0.001},
-		{1000, 0, 0},
+		{100, 1, sdk.NewDecWithPrec(1, 2)},
+		{200, 2, sdk.NewDecWithPrec(1, 2)},
+		{365, 3, sdk.NewDecWithPrec(1, 2)},
+		{1000, 500, sdk.NewDecWithPrec(5, 1)},
+		{1488, 982, sdk.NewDecWithPrec(66, 2)},
+		{10, 0, sdk.NewDecWithPrec(1, 3)},
+		{1000, 0, sdk.ZeroDec()},
 	}
 
 	for _, data := range testData {
@@ -339,6 +389,7 @@ func createNFT(owner sdk.AccAddress) *types.NFT {
 		"name",
 		owner,
 		sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(0))),
+		time.Now().UTC(),
 	)
 	return token
 }
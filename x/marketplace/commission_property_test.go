@@ -0,0 +1,103 @@
+package marketplace_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommissionRoundsDown(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	property := func(amount uint32, rateBasisPoints uint16) bool {
+		price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(int64(amount))))
+		rate := sdk.NewDecWithPrec(int64(rateBasisPoints%10001), 4)
+
+		commission := sdk.NewDec(marketplace.GetCommission(price, rate).AmountOf(denom).Int64())
+		exact := sdk.NewDec(int64(amount)).Mul(rate)
+		return commission.LTE(exact) && exact.Sub(commission).LT(sdk.OneDec())
+	}
+	require.Nil(t, quick.Check(property, nil))
+}
+
+// validatorsRewards returns the sum of the outstanding rewards of the validators that signed the last block.
+func validatorsRewards(mp *marketplaceKeeperTest, denom string) sdk.Dec {
+	total := sdk.ZeroDec()
+	for _, vote := range mp.ctx.VoteInfos() {
+		val := mp.stakingKeeper.ValidatorByConsAddr(mp.ctx, sdk.ConsAddress(vote.Validator.Address))
+		total = total.Add(mp.distrKeeper.GetValidatorOutstandingRewards(mp.ctx, val.GetOperator()).AmountOf(denom))
+	}
+	return total
+}
+
+func TestPriceIsSplitExactly(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	for _, validatorsCount := range []int{1, 3, 7} {
+		mpKeeperTest, err := createMarketplaceKeeperTest()
+		require.Nil(t, err)
+
+		coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000000000000)))
+		require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+		_, err = mpKeeperTest.updateVoteInfos(validatorsCount, coins)
+		require.Nil(t, err)
+
+		seller, buyer := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+		sellerBeneficiary, buyerBeneficiary := mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+		handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+		maxCommission := mpKeeperTest.marketKeeper.GetParams(mpKeeperTest.ctx).MaxBeneficiaryCommission
+
+		// the seller, both beneficiaries and the validators together receive exactly what the buyer pays
+		property := func(amount uint32, commissionBasisPoints uint16) bool {
+			price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(int64(amount%1000000)+1)))
+			commission := sdk.NewDecWithPrec(int64(commissionBasisPoints), 4)
+			if commission.GT(maxCommission) {
+				commission = maxCommission
+			}
+
+			mintMsg := nft.NewMsgMintNFT(seller, seller, uuid.New().String(), denom, "")
+			if !marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper,
+				mpKeeperTest.marketKeeper).IsOK() {
+				return false
+			}
+			if !handler(mpKeeperTest.ctx, *types.NewMsgPutOnMarketNFT(seller, sellerBeneficiary, mintMsg.ID, price)).IsOK() {
+				return false
+			}
+
+			balancesBefore := getBalances(mpKeeperTest, mpKeeperTest.addrs...)
+			rewardsBefore := validatorsRewards(mpKeeperTest, denom)
+			if !handler(mpKeeperTest.ctx, *types.NewMsgBuyNFT(buyer, buyerBeneficiary, mintMsg.ID, commission)).IsOK() {
+				return false
+			}
+			balancesAfter := getBalances(mpKeeperTest, mpKeeperTest.addrs...)
+
+			paid := balancesBefore[1] - balancesAfter[1]
+			received := (balancesAfter[0] - balancesBefore[0]) +
+				(balancesAfter[2] - balancesBefore[2]) +
+				(balancesAfter[3] - balancesBefore[3])
+			receivedByValidators := validatorsRewards(mpKeeperTest, denom).Sub(rewardsBefore)
+
+			return paid == price.AmountOf(denom).Int64() &&
+				receivedByValidators.Add(sdk.NewDec(received)).Equal(sdk.NewDec(paid))
+		}
+		require.Nil(t, quick.Check(property, &quick.Config{MaxCount: 50}), "validators: %d", validatorsCount)
+
+		require.Nil(t, mpKeeperTest.clear())
+	}
+}
+
+func TestValidateBasicCommission(t *testing.T) {
+	addr := sdk.AccAddress([]byte("buyer"))
+	for _, commission := range []sdk.Dec{{}, sdk.NewDecWithPrec(-1, 2), sdk.NewDecWithPrec(101, 2)} {
+		require.NotNil(t, types.NewMsgBuyNFT(addr, addr, "token", commission).ValidateBasic())
+	}
+	for _, commission := range []sdk.Dec{sdk.ZeroDec(), sdk.NewDecWithPrec(15, 3), sdk.OneDec()} {
+		require.Nil(t, types.NewMsgBuyNFT(addr, addr, "token", commission).ValidateBasic())
+	}
+}
@@ -0,0 +1,104 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestDutchAuctionCurrentPrice(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	lot := types.NewDutchAuctionLot("token", coins(1000), coins(100), 0, start.Add(10*time.Hour))
+	lot.StartTime = start
+
+	// linear
+	require.Equal(t, coins(1000), lot.CurrentPrice(start.Add(-time.Hour)))
+	require.Equal(t, coins(1000), lot.CurrentPrice(start))
+	require.Equal(t, coins(910), lot.CurrentPrice(start.Add(time.Hour)))
+	require.Equal(t, coins(550), lot.CurrentPrice(start.Add(5*time.Hour)))
+	require.Equal(t, coins(100), lot.CurrentPrice(start.Add(10*time.Hour)))
+	require.Equal(t, coins(100), lot.CurrentPrice(start.Add(11*time.Hour)))
+
+	// rounded up, the price is never below the floor
+	require.Equal(t, coins(101), lot.CurrentPrice(start.Add(10*time.Hour-time.Second)))
+
+	// stepwise
+	lot.PriceStep = 2 * time.Hour
+	require.Equal(t, coins(1000), lot.CurrentPrice(start.Add(time.Hour)))
+	require.Equal(t, coins(820), lot.CurrentPrice(start.Add(2*time.Hour)))
+	require.Equal(t, coins(820), lot.CurrentPrice(start.Add(3*time.Hour+59*time.Minute)))
+	require.Equal(t, coins(280), lot.CurrentPrice(start.Add(9*time.Hour)))
+}
+
+func TestDutchAuction(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, buyer, beneficiary := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	require.True(t, result.IsOK())
+
+	start := mpKeeperTest.ctx.BlockHeader().Time
+	msg := types.NewMsgPutNFTOnDutchAuction(owner, beneficiary, mintMsg.ID, coins(1000), coins(100), time.Hour,
+		start.Add(10*time.Hour))
+	require.Nil(t, msg.ValidateBasic())
+	result = handler(mpKeeperTest.ctx, *msg)
+	require.True(t, result.IsOK(), result.Log)
+
+	// bids are not accepted
+	ctx := mpKeeperTest.ctx.WithBlockTime(start.Add(5*time.Hour + 30*time.Minute))
+	result = handler(ctx, *types.NewMsgMakeBidOnAuction(buyer, beneficiary, mintMsg.ID, coins(2000), defaultCommission))
+	require.False(t, result.IsOK())
+
+	bz, sdkErr := querier(ctx, []string{marketplace.QueryAuctionPrice, mintMsg.ID}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	var price sdk.Coins
+	types.ModuleCdc.MustUnmarshalJSON(bz, &price)
+	require.Equal(t, coins(550), price)
+
+	// the first buyer pays the current price
+	before := getBalances(mpKeeperTest, buyer)
+	result = handler(ctx, *types.NewMsgBuyOutOnAuction(buyer, beneficiary, mintMsg.ID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, int64(550), before[0]-getBalances(mpKeeperTest, buyer)[0])
+
+	token, err := mpKeeperTest.marketKeeper.GetNFT(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(buyer))
+	_, err = mpKeeperTest.marketKeeper.GetAuctionLot(ctx, mintMsg.ID)
+	require.NotNil(t, err)
+}
+
+func TestPutOnDutchAuctionValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("owner"))
+	finish := time.Now().UTC().Add(time.Hour)
+
+	require.Nil(t, types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), coins(10), 0, finish).ValidateBasic())
+
+	for name, msg := range map[string]*types.MsgPutNFTOnAuction{
+		"floor above opening":  types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), coins(200), 0, finish),
+		"no floor":             types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), sdk.Coins{}, 0, finish),
+		"other floor denom":    types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), sdk.NewCoins(sdk.NewCoin("other", sdk.NewInt(10))), 0, finish),
+		"negative price step":  types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), coins(10), -time.Second, finish),
+		"english with a floor": {Owner: addr, TokenID: "token", OpeningPrice: coins(100), FloorPrice: coins(10), TimeToSell: finish},
+		"unknown type":         {Owner: addr, TokenID: "token", OpeningPrice: coins(100), TimeToSell: finish, AuctionType: "chinese"},
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+}
@@ -0,0 +1,88 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscrowBidsAndOffers(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+	_, err = mpKeeperTest.updateVoteInfos(1, coins)
+	require.Nil(t, err)
+
+	ctx := mpKeeperTest.ctx
+	owner, bidder, buyer, beneficiary := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	escrow := supply.NewModuleAddress(types.ModuleName)
+	invariant := marketplace.EscrowInvariant(mpKeeperTest.marketKeeper)
+	amount := func(v int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(v)))
+	}
+	requireEscrow := func(expected int64) {
+		require.Equal(t, expected, mpKeeperTest.bankKeeper.GetCoins(ctx, escrow).AmountOf(denom).Int64())
+		msg, broken := invariant(ctx)
+		require.False(t, broken, msg)
+	}
+
+	ids := putNFTsOnAuction(t, mpKeeperTest, 1, time.Now().UTC().Add(time.Hour))
+	lotID := ids[0]
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(ctx, mintMsg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	require.True(t, result.IsOK())
+	offerTokenID := mintMsg.ID
+
+	// bids are locked in the module account
+	result = handler(ctx, *types.NewMsgMakeBidOnAuction(bidder, beneficiary, lotID, amount(200), defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	requireEscrow(200)
+	require.Equal(t, int64(800), mpKeeperTest.bankKeeper.GetCoins(ctx, bidder).AmountOf(denom).Int64())
+
+	// an outbid bidder is refunded from the module account
+	result = handler(ctx, *types.NewMsgMakeBidOnAuction(buyer, beneficiary, lotID, amount(300), defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	requireEscrow(300)
+	require.Equal(t, int64(1000), mpKeeperTest.bankKeeper.GetCoins(ctx, bidder).AmountOf(denom).Int64())
+
+	// offers are locked as well
+	result = handler(ctx, *types.NewMsgMakeOffer(bidder, beneficiary, amount(100), offerTokenID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	firstOfferID := string(result.Data)
+	result = handler(ctx, *types.NewMsgMakeOffer(bidder, beneficiary, amount(150), offerTokenID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	secondOfferID := string(result.Data)
+	requireEscrow(550)
+
+	result = handler(ctx, *types.NewMsgRemoveOffer(bidder, offerTokenID, firstOfferID))
+	require.True(t, result.IsOK(), result.Log)
+	requireEscrow(450)
+
+	result = handler(ctx, *types.NewMsgAcceptOffer(owner, beneficiary, offerTokenID, secondOfferID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	requireEscrow(300)
+
+	result = handler(ctx, *types.NewMsgRemoveNFTFromAuction(owner, lotID))
+	require.True(t, result.IsOK(), result.Log)
+	requireEscrow(0)
+	require.Equal(t, int64(1000), mpKeeperTest.bankKeeper.GetCoins(ctx, buyer).AmountOf(denom).Int64())
+
+	// coins that do not belong to any bid or offer break the invariant
+	_, err = mpKeeperTest.bankKeeper.AddCoins(ctx, escrow, amount(1))
+	require.Nil(t, err)
+	_, broken := invariant(ctx)
+	require.True(t, broken)
+}
@@ -3,20 +3,125 @@ package marketplace
 import (
 	"fmt"
 
+	"github.com/corestario/marketplace/x/marketplace/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	abci "github.com/tendermint/tendermint/abci/types"
 )
 
 type GenesisState struct {
-	NFTRecords           []*NFT          `json:"nft_records"`
-	RegisteredCurrencies []FungibleToken `json:"registered_tokens"`
+	NFTRecords           []*NFT                   `json:"nft_records"`
+	RegisteredCurrencies []FungibleToken          `json:"registered_tokens"`
+	Offers               []*types.Offer           `json:"offers"`
+	CollectionOffers     []*types.CollectionOffer `json:"collection_offers"`
+	Swaps                []*types.Swap            `json:"swaps"`
+	HashLocks            []*types.HashLock        `json:"hash_locks"`
+	RentalListings       []*types.RentalListing   `json:"rental_listings"`
+	Rentals              []*types.Rental          `json:"rentals"`
+	Loans                []*types.Loan            `json:"loans"`
+	Auctions             []AuctionGenesis         `json:"auctions"`
+	BidHistories         []BidHistoryGenesis      `json:"bid_histories"`
+	LatestAuctions       []LatestAuctionGenesis   `json:"latest_auctions"`
+	OfferSequence        uint64                   `json:"offer_sequence"`
+	Params               types.Params             `json:"params"`
+}
+
+// AuctionGenesis is an auction lot along with the state of the auction kept apart from the lot.
+type AuctionGenesis struct {
+	Lot          *types.AuctionLot  `json:"lot"`
+	SealedBids   []*types.SealedBid `json:"sealed_bids"`
+	ProxyMaxBid  sdk.Coins          `json:"proxy_max_bid"`
+	ReservePrice sdk.Coins          `json:"reserve_price"`
+}
+
+// BidHistoryGenesis is the bid history of the auction of an NFT with the given number,
+// PruneHeight is 0 unless the auction is finished and its history is scheduled to be pruned.
+type BidHistoryGenesis struct {
+	NFTID       string              `json:"nft_id"`
+	Auction     uint64              `json:"auction"`
+	Bids        []*types.AuctionBid `json:"bids"`
+	PruneHeight int64               `json:"prune_height"`
+}
+
+// LatestAuctionGenesis is the number of the latest auction of an NFT.
+type LatestAuctionGenesis struct {
+	NFTID   string `json:"nft_id"`
+	Auction uint64 `json:"auction"`
 }
 
 func NewGenesisState(nftRecords []*NFT) GenesisState {
-	return GenesisState{NFTRecords: nftRecords}
+	return GenesisState{NFTRecords: nftRecords, Params: types.DefaultParams()}
 }
 
 func ValidateGenesis(data GenesisState) error {
+	if err := types.ValidateParams(data.Params); err != nil {
+		return err
+	}
+
+	for _, offer := range data.Offers {
+		if offer.ID == "" || offer.NFTID == "" {
+			return fmt.Errorf("invalid Offer: ID: %s, NFTID: %s. Error: Missing ID", offer.ID, offer.NFTID)
+		}
+	}
+
+	for _, offer := range data.CollectionOffers {
+		if offer.ID == "" || offer.Denom == "" || offer.Quantity == 0 {
+			return fmt.Errorf("invalid CollectionOffer: ID: %s, Denom: %s, Quantity: %d", offer.ID, offer.Denom, offer.Quantity)
+		}
+	}
+
+	for _, swap := range data.Swaps {
+		if swap.ID == "" || swap.Proposer.Empty() || swap.Counterparty.Empty() {
+			return fmt.Errorf("invalid Swap: ID: %s, Proposer: %s, Counterparty: %s", swap.ID, swap.Proposer, swap.Counterparty)
+		}
+	}
+
+	for _, lock := range data.HashLocks {
+		if lock.NFTID == "" || lock.HashLock == "" {
+			return fmt.Errorf("invalid HashLock: NFTID: %s, HashLock: %s", lock.NFTID, lock.HashLock)
+		}
+	}
+
+	for _, listing := range data.RentalListings {
+		if listing.NFTID == "" || !types.IsValidRentalUnit(listing.Unit) {
+			return fmt.Errorf("invalid RentalListing: NFTID: %s, Unit: %s", listing.NFTID, listing.Unit)
+		}
+	}
+
+	for _, rental := range data.Rentals {
+		if rental.NFTID == "" || rental.User.Empty() {
+			return fmt.Errorf("invalid Rental: NFTID: %s, User: %s", rental.NFTID, rental.User)
+		}
+	}
+
+	for _, loan := range data.Loans {
+		if loan.ID == "" || loan.Borrower.Empty() || loan.NFTID == "" {
+			return fmt.Errorf("invalid Loan: ID: %s, Borrower: %s, NFTID: %s", loan.ID, loan.Borrower, loan.NFTID)
+		}
+	}
+
+	for _, auction := range data.Auctions {
+		if auction.Lot == nil || auction.Lot.NFTID == "" {
+			return fmt.Errorf("invalid Auction: missing lot")
+		}
+		for _, bid := range auction.SealedBids {
+			if bid.NFTID != auction.Lot.NFTID || bid.Bidder.Empty() {
+				return fmt.Errorf("invalid SealedBid: NFTID: %s, Bidder: %s", bid.NFTID, bid.Bidder)
+			}
+		}
+	}
+
+	for _, history := range data.BidHistories {
+		if history.NFTID == "" || history.Auction == 0 {
+			return fmt.Errorf("invalid BidHistory: NFTID: %s, Auction: %d", history.NFTID, history.Auction)
+		}
+	}
+
+	for _, latest := range data.LatestAuctions {
+		if latest.NFTID == "" || latest.Auction == 0 {
+			return fmt.Errorf("invalid LatestAuction: NFTID: %s, Auction: %d", latest.NFTID, latest.Auction)
+		}
+	}
+
 	for _, cur := range data.RegisteredCurrencies {
 		if cur.Creator == nil {
 			return fmt.Errorf("invalid FungibleToken: Denom: %s. Error: Missing Creator", cur.Denom)
@@ -31,43 +136,229 @@ func ValidateGenesis(data GenesisState) error {
 
 func DefaultGenesisState() GenesisState {
 	return GenesisState{
-		NFTRecords: []*NFT{},
+		NFTRecords:    []*NFT{},
+		OfferSequence: types.DefaultStartingOfferID,
+		Params:        types.DefaultParams(),
 	}
 }
 
 func InitGenesis(ctx sdk.Context, keeper *Keeper, data GenesisState) []abci.ValidatorUpdate {
+	keeper.SetParams(ctx, data.Params)
+
 	for _, record := range data.NFTRecords {
 		if err := keeper.MintNFT(ctx, record); err != nil {
 			panic(fmt.Sprintf("failed to InitGenesis: %v", err))
 		}
+		if record.IsRented() {
+			keeper.setRentalExpiry(ctx, record)
+		}
+	}
+
+	for _, offer := range data.Offers {
+		keeper.SetOffer(ctx, offer)
+	}
+	for _, offer := range data.CollectionOffers {
+		keeper.SetCollectionOffer(ctx, offer)
+	}
+	for _, swap := range data.Swaps {
+		keeper.SetSwap(ctx, swap)
+	}
+	for _, lock := range data.HashLocks {
+		keeper.SetHashLock(ctx, lock)
+	}
+	for _, listing := range data.RentalListings {
+		keeper.SetRentalListing(ctx, listing)
+	}
+	// the rentals are exported in the order of the histories and appended in the same order
+	for _, rental := range data.Rentals {
+		keeper.appendRentalHistory(ctx, rental)
+	}
+	for _, loan := range data.Loans {
+		keeper.SetLoan(ctx, loan)
+	}
+	for _, auction := range data.Auctions {
+		if err := keeper.createAuctionLot(ctx, auction.Lot); err != nil {
+			panic(fmt.Sprintf("failed to InitGenesis: %v", err))
+		}
+		for _, bid := range auction.SealedBids {
+			keeper.SetSealedBid(ctx, bid)
+		}
+		if !auction.ProxyMaxBid.Empty() {
+			keeper.setProxyMaxBid(ctx, auction.Lot.NFTID, auction.ProxyMaxBid)
+		}
+		if !auction.ReservePrice.Empty() {
+			keeper.setReservePrice(ctx, auction.Lot.NFTID, auction.ReservePrice)
+		}
+	}
+	for _, history := range data.BidHistories {
+		keeper.setBidHistory(ctx, history.NFTID, history.Auction, history.Bids)
+		if history.PruneHeight != 0 {
+			keeper.setBidHistoryPruning(ctx, history.NFTID, history.Auction, history.PruneHeight)
+		}
+	}
+	for _, latest := range data.LatestAuctions {
+		keeper.setLatestAuction(ctx, latest.NFTID, latest.Auction)
 	}
 
 	for _, currency := range data.RegisteredCurrencies {
 		keeper.registerFungibleTokensCurrency(ctx, currency)
 	}
+
+	if data.OfferSequence != 0 {
+		keeper.SetOfferSequence(ctx, data.OfferSequence)
+	}
+
+	// the escrow is checked against the whole imported state
+	keeper.MigrateOffers(ctx)
 	return []abci.ValidatorUpdate{}
 }
 
 func ExportGenesis(ctx sdk.Context, k *Keeper) GenesisState {
 	var (
-		records    []*NFT
-		currencies []FungibleToken
-		currency   FungibleToken
+		records          []*NFT
+		offers           []*types.Offer
+		collectionOffers []*types.CollectionOffer
+		swaps            []*types.Swap
+		hashLocks        []*types.HashLock
+		rentalListings   []*types.RentalListing
+		rentals          []*types.Rental
+		loans            []*types.Loan
+		auctions         []AuctionGenesis
+		bidHistories     []BidHistoryGenesis
+		latestAuctions   []LatestAuctionGenesis
+		currencies       []FungibleToken
+		currency         FungibleToken
 	)
 	nftIterator := k.GetNFTsIterator(ctx)
 	for ; nftIterator.Valid(); nftIterator.Next() {
-		id := string(nftIterator.Key())
-		nft, err := k.GetNFT(ctx, id)
-		if err != nil {
-			panic(fmt.Sprintf("failed to ExportGenesis: %v", err))
+		var nft NFT
+		k.cdc.MustUnmarshalJSON(nftIterator.Value(), &nft)
+		records = append(records, &nft)
+	}
+
+	offersIterator := k.GetOffersIterator(ctx)
+	for ; offersIterator.Valid(); offersIterator.Next() {
+		var offer types.Offer
+		k.cdc.MustUnmarshalJSON(offersIterator.Value(), &offer)
+		offers = append(offers, &offer)
+	}
+	offersIterator.Close()
+
+	collectionOffersIterator := k.GetCollectionOffersIterator(ctx)
+	for ; collectionOffersIterator.Valid(); collectionOffersIterator.Next() {
+		var offer types.CollectionOffer
+		k.cdc.MustUnmarshalJSON(collectionOffersIterator.Value(), &offer)
+		collectionOffers = append(collectionOffers, &offer)
+	}
+	collectionOffersIterator.Close()
+
+	swapsIterator := k.GetSwapsIterator(ctx)
+	for ; swapsIterator.Valid(); swapsIterator.Next() {
+		var swap types.Swap
+		k.cdc.MustUnmarshalJSON(swapsIterator.Value(), &swap)
+		swaps = append(swaps, &swap)
+	}
+	swapsIterator.Close()
+
+	hashLocksIterator := k.GetHashLocksIterator(ctx)
+	for ; hashLocksIterator.Valid(); hashLocksIterator.Next() {
+		var lock types.HashLock
+		k.cdc.MustUnmarshalJSON(hashLocksIterator.Value(), &lock)
+		hashLocks = append(hashLocks, &lock)
+	}
+	hashLocksIterator.Close()
+
+	rentalListingsIterator := k.GetRentalListingsIterator(ctx)
+	for ; rentalListingsIterator.Valid(); rentalListingsIterator.Next() {
+		var listing types.RentalListing
+		k.cdc.MustUnmarshalJSON(rentalListingsIterator.Value(), &listing)
+		rentalListings = append(rentalListings, &listing)
+	}
+	rentalListingsIterator.Close()
+
+	rentalHistoriesIterator := k.GetRentalHistoriesIterator(ctx)
+	for ; rentalHistoriesIterator.Valid(); rentalHistoriesIterator.Next() {
+		var rental types.Rental
+		k.cdc.MustUnmarshalJSON(rentalHistoriesIterator.Value(), &rental)
+		rentals = append(rentals, &rental)
+	}
+	rentalHistoriesIterator.Close()
+
+	loansIterator := k.GetLoansIterator(ctx)
+	for ; loansIterator.Valid(); loansIterator.Next() {
+		var loan types.Loan
+		k.cdc.MustUnmarshalJSON(loansIterator.Value(), &loan)
+		loans = append(loans, &loan)
+	}
+	loansIterator.Close()
+
+	lotsIterator := k.GetAuctionLotsIterator(ctx)
+	for ; lotsIterator.Valid(); lotsIterator.Next() {
+		var lot types.AuctionLot
+		k.cdc.MustUnmarshalJSON(lotsIterator.Value(), &lot)
+		auctions = append(auctions, AuctionGenesis{
+			Lot:          &lot,
+			SealedBids:   k.GetSealedBids(ctx, lot.NFTID),
+			ProxyMaxBid:  k.getProxyMaxBid(ctx, lot.NFTID),
+			ReservePrice: k.getReservePrice(ctx, lot.NFTID),
+		})
+	}
+	lotsIterator.Close()
+
+	// the histories are exported in the order of their keys, the finished auctions without bids follow them
+	histories := map[string]int{}
+	bidHistoriesIterator := k.GetBidHistoriesIterator(ctx)
+	for ; bidHistoriesIterator.Valid(); bidHistoriesIterator.Next() {
+		id, auction := types.SplitBidHistoryKey(bidHistoriesIterator.Key())
+		ref := string(types.GetBidHistoryPrefix(id, auction))
+		if _, ok := histories[ref]; !ok {
+			histories[ref] = len(bidHistories)
+			bidHistories = append(bidHistories, BidHistoryGenesis{NFTID: id, Auction: auction})
+		}
+		var bid types.AuctionBid
+		k.cdc.MustUnmarshalJSON(bidHistoriesIterator.Value(), &bid)
+		bidHistories[histories[ref]].Bids = append(bidHistories[histories[ref]].Bids, &bid)
+	}
+	bidHistoriesIterator.Close()
+
+	pruneQueueIterator := k.GetBidHistoryPruneQueueIterator(ctx)
+	for ; pruneQueueIterator.Valid(); pruneQueueIterator.Next() {
+		height, id, auction := types.SplitBidHistoryPruneQueueKey(pruneQueueIterator.Key())
+		ref := string(types.GetBidHistoryPrefix(id, auction))
+		if _, ok := histories[ref]; !ok {
+			histories[ref] = len(bidHistories)
+			bidHistories = append(bidHistories, BidHistoryGenesis{NFTID: id, Auction: auction})
 		}
-		records = append(records, nft)
+		bidHistories[histories[ref]].PruneHeight = height
+	}
+	pruneQueueIterator.Close()
+
+	latestAuctionsIterator := k.GetLatestAuctionsIterator(ctx)
+	for ; latestAuctionsIterator.Valid(); latestAuctionsIterator.Next() {
+		id := string(latestAuctionsIterator.Key()[len(types.LatestAuctionPrefix):])
+		latestAuctions = append(latestAuctions, LatestAuctionGenesis{NFTID: id, Auction: k.GetLatestAuction(ctx, id)})
 	}
+	latestAuctionsIterator.Close()
 
 	currIterator := k.GetRegisteredCurrenciesIterator(ctx)
 	for ; currIterator.Valid(); currIterator.Next() {
-		k.cdc.MustUnmarshalBinaryBare(currIterator.Value(), &currency)
+		k.cdc.MustUnmarshalJSON(currIterator.Value(), &currency)
 		currencies = append(currencies, currency)
 	}
-	return GenesisState{NFTRecords: records, RegisteredCurrencies: currencies}
+	return GenesisState{
+		NFTRecords:           records,
+		RegisteredCurrencies: currencies,
+		Offers:               offers,
+		CollectionOffers:     collectionOffers,
+		Swaps:                swaps,
+		HashLocks:            hashLocks,
+		RentalListings:       rentalListings,
+		Rentals:              rentals,
+		Loans:                loans,
+		Auctions:             auctions,
+		BidHistories:         bidHistories,
+		LatestAuctions:       latestAuctions,
+		OfferSequence:        k.GetOfferSequence(ctx),
+		Params:               k.GetParams(ctx),
+	}
 }
@@ -0,0 +1,89 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportAuctions(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, first, second := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+	start := ctx.BlockHeader().Time
+
+	// an English auction with a reserve price and a proxy bid
+	english := mintNFT(t, mpKeeperTest, owner)
+	msg := types.NewMsgPutNFTOnAuction(owner, owner, english, coins(100), sdk.Coins{}, start.Add(time.Hour))
+	msg.ReserveHash = types.ReservePriceHash(coins(300), "salt")
+	msg.RevealDuration = time.Hour
+	require.True(t, handler(ctx, *msg).IsOK())
+	require.True(t, handler(ctx, *types.NewMsgMakeProxyBidOnAuction(first, owner, english, coins(500), defaultCommission)).IsOK())
+	require.True(t, handler(ctx, *types.NewMsgMakeBidOnAuction(second, owner, english, coins(200), defaultCommission)).IsOK())
+
+	// a sealed-bid auction with a committed bid
+	sealed := mintNFT(t, mpKeeperTest, owner)
+	require.True(t, handler(ctx, *types.NewMsgPutNFTOnSealedAuction(owner, owner, sealed, coins(100), coins(10), false,
+		start.Add(time.Hour), time.Hour)).IsOK())
+	bidHash := types.SealedBidHash(second, coins(150), "salt")
+	require.True(t, handler(ctx, *types.NewMsgCommitSealedBid(second, owner, sealed, bidHash, defaultCommission)).IsOK())
+
+	// a finished auction whose bid history is waiting to be pruned
+	finished := mintNFT(t, mpKeeperTest, owner)
+	require.True(t, handler(ctx, *types.NewMsgPutNFTOnAuction(owner, owner, finished, coins(100), sdk.Coins{},
+		start.Add(time.Hour))).IsOK())
+	require.True(t, handler(ctx, *types.NewMsgMakeBidOnAuction(first, owner, finished, coins(100), defaultCommission)).IsOK())
+	require.True(t, handler(ctx, *types.NewMsgFinishAuction(owner, finished)).IsOK())
+
+	exported := marketplace.ExportGenesis(ctx, keeper)
+	require.Nil(t, marketplace.ValidateGenesis(exported))
+	require.Len(t, exported.Auctions, 2)
+	require.Len(t, exported.BidHistories, 2)
+	require.Len(t, exported.LatestAuctions, 3)
+	escrow := mpKeeperTest.bankKeeper.GetCoins(ctx, supply.NewModuleAddress(types.ModuleName))
+
+	// the NFTs and the module account balance come from the nft and the supply genesis, set the same way here
+	imported, err := createMarketplaceKeeperTest()
+	defer imported.clear()
+	require.Nil(t, err)
+	require.Nil(t, imported.updateAccountsWithCoins(coins(10000)))
+	require.Nil(t, imported.marketKeeper.LockCoins(imported.ctx, imported.addrs[0], escrow))
+	nft.InitGenesis(imported.ctx, *imported.nftKeeper, nft.ExportGenesis(ctx, *mpKeeperTest.nftKeeper))
+
+	marketplace.InitGenesis(imported.ctx, imported.marketKeeper, exported)
+	require.Equal(t, string(types.ModuleCdc.MustMarshalJSON(exported)),
+		string(types.ModuleCdc.MustMarshalJSON(marketplace.ExportGenesis(imported.ctx, imported.marketKeeper))))
+
+	for _, invariant := range []sdk.Invariant{
+		marketplace.EscrowInvariant(imported.marketKeeper),
+		marketplace.NFTIndexesInvariant(imported.marketKeeper),
+	} {
+		msgInv, broken := invariant(imported.ctx)
+		require.False(t, broken, msgInv)
+	}
+
+	// the imported auctions go on: the next auction of an NFT gets the next number
+	ctx = imported.ctx
+	handler = marketplace.NewHandler(imported.marketKeeper)
+	result := handler(ctx.WithBlockTime(start.Add(90*time.Minute)),
+		*types.NewMsgRevealReservePrice(owner, english, coins(300), "salt"))
+	require.True(t, result.IsOK(), result.Log)
+	result = handler(ctx, *types.NewMsgPutNFTOnAuction(first, first, english, coins(100), sdk.Coins{}, start.Add(time.Hour)))
+	require.True(t, result.IsOK(), result.Log)
+	lot, err := imported.marketKeeper.GetAuctionLot(ctx, english)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), lot.Auction)
+}
@@ -7,11 +7,9 @@ import (
 	"github.com/corestario/marketplace/common"
 	"github.com/corestario/marketplace/x/marketplace/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/distribution"
 	"github.com/cosmos/modules/incubator/nft"
-	"github.com/google/uuid"
 	abci_types "github.com/tendermint/tendermint/abci/types"
-	"github.com/tendermint/tendermint/libs/log"
-	"github.com/tendermint/tendermint/types/time"
 )
 
 // NewHandler returns a handler for "marketplace" type messages.
@@ -23,17 +21,41 @@ func NewHandler(keeper *Keeper) sdk.Handler {
 		case MsgRemoveNFTFromMarket:
 			return handleMsgRemoveNFTFromMarket(ctx, keeper, msg)
 		case MsgBuyNFT:
-			return handleMsgBuyNFT(ctx, keeper, msg)
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgBuyNFT(ctx, keeper, msg)
+			})
 		case MsgPutNFTOnAuction:
 			return handleMsgPutNFTOnAuction(ctx, keeper, msg)
 		case MsgRemoveNFTFromAuction:
-			return handleMsgRemoveNFTFromAuction(ctx, keeper, msg)
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRemoveNFTFromAuction(ctx, keeper, msg)
+			})
 		case MsgMakeBidOnAuction:
-			return handleMsgMakeBidOnAuction(ctx, keeper, msg)
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgMakeBidOnAuction(ctx, keeper, msg)
+			})
 		case MsgFinishAuction:
-			return handleMsgFinishAuction(ctx, keeper, msg)
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgFinishAuction(ctx, keeper, msg)
+			})
 		case MsgBuyoutOnAuction:
 			return handleMsgBuyoutOnAuction(ctx, keeper, msg)
+		case MsgCommitSealedBid:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgCommitSealedBid(ctx, keeper, msg)
+			})
+		case MsgRevealSealedBid:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRevealSealedBid(ctx, keeper, msg)
+			})
+		case MsgSettleSealedAuction:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgSettleSealedAuction(ctx, keeper, msg)
+			})
+		case MsgRevealReservePrice:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRevealReservePrice(ctx, keeper, msg)
+			})
 		case MsgBatchTransfer:
 			return handleMsgBatchTransfer(ctx, keeper, msg)
 		case MsgBatchPutOnMarket:
@@ -45,7 +67,9 @@ func NewHandler(keeper *Keeper) sdk.Handler {
 		case MsgMakeOffer:
 			return handleMsgMakeOffer(ctx, keeper, msg)
 		case MsgAcceptOffer:
-			return handleMsgAcceptOffer(ctx, keeper, msg)
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgAcceptOffer(ctx, keeper, msg)
+			})
 		case MsgRemoveOffer:
 			return handleMsgRemoveOffer(ctx, keeper, msg)
 		case MsgUpdateNFTParams:
@@ -58,6 +82,76 @@ func NewHandler(keeper *Keeper) sdk.Handler {
 			return handleMsgBurnFungibleToken(ctx, keeper, msg)
 		case MsgTransferNFTByIBC:
 			return HandleMsgTransferNFTByIBC(ctx, keeper, msg)
+		case MsgMintNFTWithRoyalty:
+			return handleMsgMintNFTWithRoyalty(ctx, keeper, msg)
+		case MsgCreateBundle:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgCreateBundle(ctx, keeper, msg)
+			})
+		case MsgRemoveBundle:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRemoveBundle(ctx, keeper, msg)
+			})
+		case MsgMakeCollectionOffer:
+			return handleMsgMakeCollectionOffer(ctx, keeper, msg)
+		case MsgFillCollectionOffer:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgFillCollectionOffer(ctx, keeper, msg)
+			})
+		case MsgRemoveCollectionOffer:
+			return handleMsgRemoveCollectionOffer(ctx, keeper, msg)
+		case MsgProposeSwap:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgProposeSwap(ctx, keeper, msg)
+			})
+		case MsgAcceptSwap:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgAcceptSwap(ctx, keeper, msg)
+			})
+		case MsgCancelSwap:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgCancelSwap(ctx, keeper, msg)
+			})
+		case MsgHashLockNFT:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgHashLockNFT(ctx, keeper, msg)
+			})
+		case MsgClaimHashLockedNFT:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgClaimHashLockedNFT(ctx, keeper, msg)
+			})
+		case MsgReclaimHashLockedNFT:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgReclaimHashLockedNFT(ctx, keeper, msg)
+			})
+		case MsgListNFTForRent:
+			return handleMsgListNFTForRent(ctx, keeper, msg)
+		case MsgRemoveRentalListing:
+			return handleMsgRemoveRentalListing(ctx, keeper, msg)
+		case MsgRentNFT:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRentNFT(ctx, keeper, msg)
+			})
+		case MsgRequestLoan:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRequestLoan(ctx, keeper, msg)
+			})
+		case MsgCancelLoanRequest:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgCancelLoanRequest(ctx, keeper, msg)
+			})
+		case MsgFundLoan:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgFundLoan(ctx, keeper, msg)
+			})
+		case MsgRepayLoan:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgRepayLoan(ctx, keeper, msg)
+			})
+		case MsgClaimLoanCollateral:
+			return handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+				return handleMsgClaimLoanCollateral(ctx, keeper, msg)
+			})
 		default:
 			errMsg := fmt.Sprintf("Unrecognized marketplace Msg type: %v", msg.Type())
 			return sdk.ErrUnknownRequest(errMsg).Result()
@@ -130,24 +224,19 @@ func handleMsgBuyNFT(ctx sdk.Context, mpKeeper *Keeper, msg MsgBuyNFT) sdk.Resul
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BuyNFT: token is not for sale")).Result()
 	}
 
-	beneficiariesCommission := types.DefaultBeneficiariesCommission
-	parsed, err := strconv.ParseFloat(msg.BeneficiaryCommission, 64)
-	if err == nil {
-		beneficiariesCommission = parsed
-	}
-	if beneficiariesCommission > mpKeeper.config.MaximumBeneficiaryCommission {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BuyNFT: beneficiary commission is too high")).Result()
+	if err := mpKeeper.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BuyNFT: %v", err)).Result()
 	}
 
 	priceAfterCommission, err := doNFTCommissions(
 		ctx,
 		mpKeeper,
+		token,
 		msg.Buyer,
-		token.Owner,
 		msg.Beneficiary,
 		token.SellerBeneficiary,
 		token.GetPrice(),
-		beneficiariesCommission,
+		msg.BeneficiaryCommission,
 	)
 	if err != nil {
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BuyNFT: failed to pay commissions: %v", err)).Result()
@@ -172,7 +261,7 @@ func handleMsgBuyNFT(ctx sdk.Context, mpKeeper *Keeper, msg MsgBuyNFT) sdk.Resul
 			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
 			sdk.NewAttribute(types.AttributeKeyBuyer, msg.Buyer.String()),
 			sdk.NewAttribute(types.AttributeKeyBeneficiary, msg.Beneficiary.String()),
-			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -189,37 +278,50 @@ func handleMsgMakeOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgMakeOffer) sdk
 	if !mpKeeper.coinKeeper.HasCoins(ctx, msg.Buyer, msg.Price) {
 		return sdk.ErrUnknownRequest("buyer does not have the offered funds").Result()
 	}
+	if err := mpKeeper.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to MakeOffer: %v", err)).Result()
+	}
 
-	token, err := mpKeeper.GetNFT(ctx, msg.TokenID)
-	if err != nil {
+	if _, err := mpKeeper.GetNFT(ctx, msg.TokenID); err != nil {
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to MakeOffer: %v", err)).Result()
 	}
+	if mpKeeper.countOffers(ctx, msg.TokenID) >= mpKeeper.GetParams(ctx).MaxOffersPerNFT {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to MakeOffer: too many offers for token %s", msg.TokenID)).Result()
+	}
 
-	token.AddOffer(&types.Offer{
-		ID:                    uuid.New().String(),
+	offer := &types.Offer{
+		NFTID:                 msg.TokenID,
 		Price:                 msg.Price,
 		Buyer:                 msg.Buyer,
 		BuyerBeneficiary:      msg.BuyerBeneficiary,
 		BeneficiaryCommission: msg.BeneficiaryCommission,
-	})
+		ExpirationTime:        msg.ExpirationTime,
+		ExpirationHeight:      msg.ExpirationHeight,
+	}
+	if offer.IsExpired(ctx.BlockHeader().Time, ctx.BlockHeight()) {
+		return sdk.ErrUnknownRequest("failed to MakeOffer: offer has already expired").Result()
+	}
 
-	if _, err := mpKeeper.coinKeeper.SubtractCoins(ctx, msg.Buyer, msg.Price); err != nil {
+	offerID := mpKeeper.GetNextOfferID(ctx)
+	offer.ID = offerID
+
+	if err := mpKeeper.LockCoins(ctx, msg.Buyer, msg.Price); err != nil {
 		return wrapError("failed to MakeOffer", err)
 	}
+	mpKeeper.SetOffer(ctx, offer)
 
-	if err := mpKeeper.UpdateNFT(ctx, token); err != nil {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to MakeOffer: %v", err)).Result()
-	}
 	mpKeeper.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgMakeOffer)
 	ctx.EventManager().EmitEvents(sdk.Events{
 		sdk.NewEvent(
 			msg.Type(),
-			sdk.NewAttribute(types.AttributeKeyOfferID, token.Offers[len(token.Offers)-1].ID),
+			sdk.NewAttribute(types.AttributeKeyOfferID, offerID),
 			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
 			sdk.NewAttribute(types.AttributeKeyPrice, msg.Price.String()),
 			sdk.NewAttribute(types.AttributeKeyBuyer, msg.Buyer.String()),
 			sdk.NewAttribute(types.AttributeKeyBeneficiary, msg.BuyerBeneficiary.String()),
-			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
+			sdk.NewAttribute(types.AttributeKeyExpirationTime, msg.ExpirationTime.String()),
+			sdk.NewAttribute(types.AttributeKeyExpirationHeight, strconv.FormatInt(msg.ExpirationHeight, 10)),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -227,7 +329,7 @@ func handleMsgMakeOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgMakeOffer) sdk
 			sdk.NewAttribute(sdk.AttributeKeySender, msg.Buyer.String()),
 		),
 	})
-	return sdk.Result{Events: ctx.EventManager().Events()}
+	return sdk.Result{Data: []byte(offerID), Events: ctx.EventManager().Events()}
 }
 
 func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer) sdk.Result {
@@ -237,18 +339,26 @@ func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer)
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: %v", err)).Result()
 	}
 
-	offer, ok := token.GetOffer(msg.OfferID)
-	if !ok {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: no ofer with ID %s", msg.OfferID)).Result()
+	if !token.Owner.Equals(msg.Seller) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: %s is not the owner of NFT #%s",
+			msg.Seller, msg.TokenID)).Result()
+	}
+
+	offer, err := mpKeeper.GetOffer(ctx, msg.TokenID, msg.OfferID)
+	if err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: %v", err)).Result()
+	}
+
+	if offer.IsExpired(ctx.BlockHeader().Time, ctx.BlockHeight()) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: offer %s has expired", msg.OfferID)).Result()
 	}
 
-	beneficiariesCommission := types.DefaultBeneficiariesCommission
-	parsed, err := strconv.ParseFloat(msg.BeneficiaryCommission, 64)
-	if err == nil {
-		beneficiariesCommission = parsed
+	if token.IsLocked() {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: token is %s", token.Status)).Result()
 	}
-	if beneficiariesCommission > mpKeeper.config.MaximumBeneficiaryCommission {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: beneficiary commission is too high")).Result()
+
+	if err := mpKeeper.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: %v", err)).Result()
 	}
 
 	if token.IsOnMarket() {
@@ -264,16 +374,13 @@ func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer)
 			return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: could not get auction lot")).Result()
 		}
 
-		if lot.ExpirationTime.Before(time.Now().UTC()) {
+		if lot.ExpirationTime.Before(ctx.BlockHeader().Time) {
 			return sdk.ErrUnknownRequest(fmt.Sprintf("auction is already finished")).Result()
 		}
 
-		// return bid to last bidder if exists
-		if lot.LastBid != nil {
-			_, err := mpKeeper.coinKeeper.AddCoins(ctx, lot.LastBid.Bidder, lot.LastBid.Bid)
-			if err != nil {
-				return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: could not get return coins to bidder")).Result()
-			}
+		// return bids to bidders if exist
+		if err := mpKeeper.refundAuctionBids(ctx, lot); err != nil {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: could not get return coins to bidder")).Result()
 		}
 
 		err = mpKeeper.RemoveNFTFromAuction(ctx, msg.TokenID, msg.Seller)
@@ -283,19 +390,19 @@ func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer)
 	}
 
 	// Return frozen funds to the buyer so that doNFTCommissions works correctly
-	if _, err = mpKeeper.coinKeeper.AddCoins(ctx, offer.Buyer, offer.Price); err != nil {
+	if err = mpKeeper.UnlockCoins(ctx, offer.Buyer, offer.Price); err != nil {
 		return wrapError("failed to AcceptOffer", err)
 	}
 
 	priceAfterCommission, err := doNFTCommissions(
 		ctx,
 		mpKeeper,
+		token,
 		offer.Buyer,
-		token.Owner,
 		offer.BuyerBeneficiary,
 		msg.SellerBeneficiary,
 		offer.Price,
-		beneficiariesCommission,
+		msg.BeneficiaryCommission,
 	)
 	if err != nil {
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: failed to pay commissions: %v", err)).Result()
@@ -305,9 +412,7 @@ func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer)
 		return sdk.ErrInsufficientCoins("failed to AcceptOffer: buyer does not have enough coins").Result()
 	}
 
-	if ok := token.RemoveOffer(offer.ID, offer.Buyer); !ok {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptOffer: no ofer with ID %s", msg.OfferID)).Result()
-	}
+	mpKeeper.DeleteOffer(ctx, offer)
 
 	token.Owner = offer.Buyer
 	token.SetSellerBeneficiary(sdk.AccAddress{})
@@ -321,7 +426,7 @@ func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer)
 		sdk.NewEvent(
 			msg.Type(),
 			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
-			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -334,28 +439,18 @@ func handleMsgAcceptOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgAcceptOffer)
 
 func handleMsgRemoveOffer(ctx sdk.Context, mpKeeper *Keeper, msg MsgRemoveOffer) sdk.Result {
 	mpKeeper.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRemoveOffer)
-	token, err := mpKeeper.GetNFT(ctx, msg.TokenID)
+	offer, err := mpKeeper.GetOffer(ctx, msg.TokenID, msg.OfferID)
 	if err != nil {
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveOffer: %v", err)).Result()
 	}
-
-	offer, ok := token.GetOffer(msg.OfferID)
-	if !ok {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveOffer: no ofer with ID %s", msg.OfferID)).Result()
-	}
-
-	ok = token.RemoveOffer(msg.OfferID, msg.Buyer)
-	if !ok {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveOffer: no ofer with ID %s", msg.OfferID)).Result()
+	if !offer.Buyer.Equals(msg.Buyer) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveOffer: %s is not the buyer of offer %s", msg.Buyer, msg.OfferID)).Result()
 	}
 
-	if _, err := mpKeeper.coinKeeper.AddCoins(ctx, msg.Buyer, offer.Price); err != nil {
+	if err := mpKeeper.UnlockCoins(ctx, msg.Buyer, offer.Price); err != nil {
 		return wrapError("failed to RemoveOffer", err)
 	}
-
-	if err := mpKeeper.UpdateNFT(ctx, token); err != nil {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveOffer: %v", err)).Result()
-	}
+	mpKeeper.DeleteOffer(ctx, offer)
 
 	mpKeeper.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRemoveOffer)
 	ctx.EventManager().EmitEvents(sdk.Events{
@@ -492,12 +587,12 @@ func handleMsgBurnFungibleToken(ctx sdk.Context, mpKeeper *Keeper, msg MsgBurnFu
 func doNFTCommissions(
 	ctx sdk.Context,
 	k *Keeper,
+	token *types.NFT,
 	buyer,
-	seller,
 	sellerBeneficiary,
 	buyerBeneficiary sdk.AccAddress,
 	price sdk.Coins,
-	beneficiariesCommission float64,
+	beneficiariesCommission sdk.Dec,
 ) (priceAfterCommission sdk.Coins, err error) {
 	logger := ctx.Logger()
 
@@ -514,48 +609,85 @@ func doNFTCommissions(
 			vals = append(vals, vote.Validator)
 		}
 	}
-	lenVals := float64(len(vals))
+	lenVals := int64(len(vals))
 	if len(vals) == 0 {
-		lenVals = 1.0
+		lenVals = 1
 	}
 	// first calculate all commissions and total commission as sum of them
-	singleValCommission := GetCommission(price, types.DefaultValidatorsCommission/lenVals)
+	singleValCommission := GetCommission(price, k.GetParams(ctx).ValidatorsCommission.QuoInt64(lenVals))
 	totalValsCommission := sdk.NewCoins()
-	for i := 0; i < int(lenVals); i++ {
+	for i := int64(0); i < lenVals; i++ {
 		totalValsCommission = totalValsCommission.Add(singleValCommission)
 	}
 
 	totalCommission := sdk.NewCoins()
-	beneficiaryCommission := GetCommission(price, beneficiariesCommission/2)
+	beneficiaryCommission := GetCommission(price, beneficiariesCommission.QuoInt64(2))
 	logger.Info("calculated beneficiary commission", "beneficiary_commission", beneficiaryCommission.String())
 
 	totalCommission = totalCommission.Add(beneficiaryCommission)
 	totalCommission = totalCommission.Add(beneficiaryCommission)
 	totalCommission = totalCommission.Add(totalValsCommission)
 
+	// The creator is paid a royalty only when the NFT is resold. The royalty is capped by
+	// the current MaxRoyalty param, so that the commissions never exceed the price.
+	// The items of a bundle share its price equally, each pays the royalty on its share.
+	royaltyTokens := []*types.NFT{token}
+	if token.IsBundle() {
+		if royaltyTokens, err = k.getBundleItems(ctx, token); err != nil {
+			return nil, err
+		}
+	}
+	royalties := make([]sdk.Coins, len(royaltyTokens))
+	for i, royaltyToken := range royaltyTokens {
+		royalties[i] = sdk.NewCoins()
+		if !royaltyToken.Owner.Equals(royaltyToken.Creator) {
+			royaltyRate := royaltyToken.GetRoyalty()
+			if maxRoyalty := k.GetParams(ctx).MaxRoyalty; royaltyRate.GT(maxRoyalty) {
+				royaltyRate = maxRoyalty
+			}
+			royalties[i] = quoCoins(GetCommission(price, royaltyRate), int64(len(royaltyTokens)))
+		}
+		totalCommission = totalCommission.Add(royalties[i])
+	}
+
 	priceAfterCommission = price.Sub(totalCommission)
 	logger.Info("calculated total commission", "total_commission", totalCommission.String(),
 		"price_after_commission", priceAfterCommission.String())
 
-	var initialBalances = GetBalances(ctx, k, buyer, seller, buyerBeneficiary, sellerBeneficiary)
 	// Pay commission to the beneficiaries.
 	if err := k.coinKeeper.SendCoins(ctx, buyer, sellerBeneficiary, beneficiaryCommission); err != nil {
-		RollbackCommissions(ctx, k, logger, initialBalances)
 		return nil, fmt.Errorf("failed to pay commission to beneficiary: %v", err)
 	}
 	logger.Info("payed seller beneficiary commission", "seller_beneficiary", sellerBeneficiary.String())
 	if err := k.coinKeeper.SendCoins(ctx, buyer, buyerBeneficiary, beneficiaryCommission); err != nil {
-		RollbackCommissions(ctx, k, logger, initialBalances)
 		return nil, fmt.Errorf("failed to pay commission to beneficiary: %v", err)
 	}
 	logger.Info("payed buyer beneficiary commission", "buyer_beneficiary", buyerBeneficiary.String())
 
-	// First we take tokens from the buyer, then we allocate tokens to validators via distribution module.
-	if _, err := k.coinKeeper.SubtractCoins(ctx, buyer, totalValsCommission); err != nil {
-		RollbackCommissions(ctx, k, logger, initialBalances)
+	// Pay royalty to the creator.
+	for i, royaltyToken := range royaltyTokens {
+		royalty := royalties[i]
+		if royalty.IsZero() {
+			continue
+		}
+		if err := k.coinKeeper.SendCoins(ctx, buyer, royaltyToken.Creator, royalty); err != nil {
+			return nil, fmt.Errorf("failed to pay royalty to creator: %v", err)
+		}
+		logger.Info("payed creator royalty", "creator", royaltyToken.Creator.String(), "royalty", royalty.String())
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypePayRoyalty,
+			sdk.NewAttribute(types.AttributeKeyNFTID, royaltyToken.ID),
+			sdk.NewAttribute(types.AttributeKeyCreator, royaltyToken.Creator.String()),
+			sdk.NewAttribute(types.AttributeKeyRoyalty, royalty.String()),
+		))
+	}
+
+	// First we send tokens from the buyer to the distribution module account, then we allocate them
+	// to validators via distribution module.
+	if err := k.supplyKeeper.SendCoinsFromAccountToModule(ctx, buyer, distribution.ModuleName, totalValsCommission); err != nil {
 		return nil, fmt.Errorf("failed to take validators commission from buyer: %v", err)
 	}
-	logger.Info("wrote off validators commission")
+	logger.Info("sent validators commission to distribution module")
 
 	logger.Info("paying validators", "validator_commission", singleValCommission.String(),
 		"num_validators", len(vals))
@@ -563,93 +695,89 @@ func doNFTCommissions(
 		consVal := k.stakingKeeper.ValidatorByConsAddr(ctx, sdk.ConsAddress(val.Address))
 		k.distrKeeper.AllocateTokensToValidator(ctx, consVal, sdk.NewDecCoins(singleValCommission))
 	}
+	// without validators to pay, the commission goes to the community pool so that the distribution
+	// module account holds no coins it does not account for
+	if len(vals) == 0 {
+		feePool := k.distrKeeper.GetFeePool(ctx)
+		feePool.CommunityPool = feePool.CommunityPool.Add(sdk.NewDecCoins(totalValsCommission))
+		k.distrKeeper.SetFeePool(ctx, feePool)
+	}
 
 	return priceAfterCommission, nil
 }
 
-type balance struct {
-	addr   sdk.AccAddress
-	amount sdk.Coins
-}
-
-func GetBalances(ctx sdk.Context, mpKeeper *Keeper, addrs ...sdk.AccAddress) []*balance {
-	var out []*balance
-	for _, addr := range addrs {
-		out = append(out, &balance{
-			addr:   addr,
-			amount: mpKeeper.coinKeeper.GetCoins(ctx, addr),
-		})
+// runAtomically runs a multi-step trade in a cached context. The state changes
+// and events of the trade are written to ctx only if it succeeds.
+func runAtomically(ctx sdk.Context, trade func(ctx sdk.Context) error) error {
+	cacheCtx, writeCache := ctx.CacheContext()
+	if err := trade(cacheCtx); err != nil {
+		return err
 	}
-
-	return out
+	writeCache()
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	return nil
 }
 
-func RollbackCommissions(ctx sdk.Context, mpKeeper *Keeper, logger log.Logger, initialBalances []*balance) {
-	for _, balance := range initialBalances {
-		if err := mpKeeper.coinKeeper.SetCoins(ctx, balance.addr, balance.amount); err != nil {
-			logger.Error("failed to rollback commissions", "addr", balance.addr.String(), "error", err)
-		}
-	}
+// handleAtomically is runAtomically for message handlers: the state changes
+// and events of the handler are written to ctx only if its result is OK.
+func handleAtomically(ctx sdk.Context, handler func(ctx sdk.Context) sdk.Result) sdk.Result {
+	cacheCtx, writeCache := ctx.CacheContext()
+	result := handler(cacheCtx)
+	if !result.IsOK() {
+		return result
+	}
+	writeCache()
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	result.Events = ctx.EventManager().Events()
+	return result
 }
 
-func calculateNumAndDenom(p float64) (sdk.Dec, sdk.Dec) {
-	if p == 0 {
-		return sdk.NewDec(0), sdk.NewDec(1)
-	}
-	/*
-		//	Considering a float64 less than 1.0 (e.g. 0.0015)
-		//	as a quotient (fraction) of two numbers p/q
-		//	(p (numerator) divided by q (denominator)), where
-		//	p is an integer number (e.g. 15) and
-		//	q is an integer number, which is product of 10 (e.g. 10000),
-		//	we can express input float64 value of commission
-		//	this way (as p/q)
-		//	This is supposed to simplify commission calculation
-	*/
-	//	init q
-	q := int64(1)
-	//	for loop till the precision limit
-	for i := 0; i < sdk.Precision; i++ {
-		//	multiply input float number
-		p *= 10
-		//	multiply q as well
-		q *= 10
-		//	check if input number became integer
-		//	this if faster than
-		//	math.Trunc(p) == p
-		if float64(int64(p)) == p {
-			break
-		}
+// GetCommission returns the given share of the price. Every amount is rounded down to a whole
+// number of coins, so commissions never add up to more than the price: the seller receives
+// what is left of the price after all commissions, including the rounded off fractions.
+func GetCommission(price sdk.Coins, rate sdk.Dec) sdk.Coins {
+	commission := sdk.NewCoins()
+	for _, coin := range price {
+		amount := sdk.NewDecFromInt(coin.Amount).MulTruncate(rate).TruncateInt()
+		commission = commission.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, amount)))
 	}
-	return sdk.NewDec(int64(p)), sdk.NewDec(q)
+	return commission
 }
 
-func GetCommission(price sdk.Coins, rat64 float64) sdk.Coins {
-	if rat64 >= 1 {
-		return price
+// quoCoins divides every amount of the coins by n, rounding down.
+func quoCoins(coins sdk.Coins, n int64) sdk.Coins {
+	out := sdk.NewCoins()
+	for _, coin := range coins {
+		out = out.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, coin.Amount.QuoRaw(n))))
 	}
-	num, denom := calculateNumAndDenom(rat64)
-	priceDec := sdk.NewDecCoins(price)
-	totalCommission, _ := priceDec.MulDec(num).QuoDec(denom).TruncateDecimal()
-	return totalCommission
+	return out
 }
 
 func handleMsgBatchTransfer(ctx sdk.Context, mpKeeper *Keeper, msg MsgBatchTransfer) sdk.Result {
 	mpKeeper.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgBatchTransfer)
 
-	for _, tokenID := range msg.TokenIDs {
+	tokens := make([]*NFT, len(msg.TokenIDs))
+	for i, tokenID := range msg.TokenIDs {
 		token, err := mpKeeper.GetNFT(ctx, tokenID)
 		if err != nil {
-			sdk.ErrUnknownRequest(fmt.Sprintf("failed to find token %s: %v", tokenID, err)).Result()
+			return sdk.ErrUnknownRequest(fmt.Sprintf("failed to find token %s: %v", tokenID, err)).Result()
 		}
-		res := HandleMsgTransferNFTMarketplace(ctx, nft.MsgTransferNFT{
-			Sender:    msg.Sender,
-			Recipient: msg.Recipient,
-			Denom:     token.Denom,
-			ID:        tokenID,
-		}, mpKeeper.nftKeeper, mpKeeper)
+		tokens[i] = token
+	}
+
+	for _, token := range tokens {
+		token := token
+
+		res := handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+			return HandleMsgTransferNFTMarketplace(ctx, nft.MsgTransferNFT{
+				Sender:    msg.Sender,
+				Recipient: msg.Recipient,
+				Denom:     token.Denom,
+				ID:        token.ID,
+			}, mpKeeper.nftKeeper, mpKeeper)
+		})
 		if !res.IsOK() {
-			ctx.Logger().Info("batch transfer error, tokenID:", tokenID, "result:", string(res.Data))
+			ctx.Logger().Info("batch transfer error, tokenID:", token.ID, "result:", string(res.Data))
 			continue
 		}
 	}
@@ -727,13 +855,8 @@ func handleMsgBatchRemoveFromMarket(ctx sdk.Context, mpKeeper *Keeper, msg MsgBa
 func handleMsgBatchBuyOnMarket(ctx sdk.Context, mpKeeper *Keeper, msg MsgBatchBuyOnMarket) sdk.Result {
 	mpKeeper.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgMsgBatchBuyOnMarket)
 
-	beneficiariesCommission := types.DefaultBeneficiariesCommission
-	parsed, err := strconv.ParseFloat(msg.BeneficiaryCommission, 64)
-	if err == nil {
-		beneficiariesCommission = parsed
-	}
-	if beneficiariesCommission > mpKeeper.config.MaximumBeneficiaryCommission {
-		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BuyNFT: beneficiary commission is too high")).Result()
+	if err := mpKeeper.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BuyNFT: %v", err)).Result()
 	}
 
 	priceSum := sdk.NewCoins()
@@ -758,10 +881,13 @@ func handleMsgBatchBuyOnMarket(ctx sdk.Context, mpKeeper *Keeper, msg MsgBatchBu
 	for _, tokenID := range msg.TokenIDs {
 		tokenID := tokenID
 
-		res := handleMsgBuyNFT(ctx, mpKeeper, MsgBuyNFT{
-			Buyer:       msg.Buyer,
-			Beneficiary: msg.Beneficiary,
-			TokenID:     tokenID,
+		res := handleAtomically(ctx, func(ctx sdk.Context) sdk.Result {
+			return handleMsgBuyNFT(ctx, mpKeeper, MsgBuyNFT{
+				Buyer:                 msg.Buyer,
+				Beneficiary:           msg.Beneficiary,
+				BeneficiaryCommission: msg.BeneficiaryCommission,
+				TokenID:               tokenID,
+			})
 		})
 		if !res.IsOK() {
 			ctx.Logger().Info("batch buy error, tokenID:", tokenID, "result:", string(res.Data))
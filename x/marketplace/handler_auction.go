@@ -7,7 +7,6 @@ import (
 	"github.com/corestario/marketplace/common"
 	"github.com/corestario/marketplace/x/marketplace/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/types/time"
 )
 
 func handleMsgPutNFTOnAuction(ctx sdk.Context, k *Keeper, msg types.MsgPutNFTOnAuction) sdk.Result {
@@ -18,6 +17,25 @@ func handleMsgPutNFTOnAuction(ctx sdk.Context, k *Keeper, msg types.MsgPutNFTOnA
 		return wrapError(failMsg, fmt.Errorf("failed to PutNFTOnAuction: %v", "denom does not exist"))
 	}
 
+	lot := types.NewAuctionLot(msg.TokenID, msg.OpeningPrice, msg.BuyoutPrice, msg.TimeToSell)
+	lot.MinBidIncrement = msg.MinBidIncrement
+	lot.MinBidIncrementRate = msg.MinBidIncrementRate
+	lot.ExtensionWindow = msg.ExtensionWindow
+	if msg.ReserveHash != "" {
+		lot.ReserveHash = msg.ReserveHash
+		lot.RevealEndTime = msg.TimeToSell.Add(msg.RevealDuration)
+	}
+	switch msg.AuctionType {
+	case types.AuctionTypeDutch:
+		lot = types.NewDutchAuctionLot(msg.TokenID, msg.OpeningPrice, msg.FloorPrice, msg.PriceStep, msg.TimeToSell)
+	case types.AuctionTypeSealed:
+		if !k.IsDenomExist(ctx, msg.BidDeposit) {
+			return wrapError(failMsg, fmt.Errorf("failed to PutNFTOnAuction: %v", "denom does not exist"))
+		}
+		lot = types.NewSealedAuctionLot(msg.TokenID, msg.OpeningPrice, msg.BidDeposit, msg.SecondPrice,
+			msg.TimeToSell, msg.TimeToSell.Add(msg.RevealDuration))
+	}
+
 	if !msg.BuyoutPrice.IsZero() {
 		if !k.IsDenomExist(ctx, msg.BuyoutPrice) {
 			return wrapError(failMsg, fmt.Errorf("failed to PutNFTOnAuction: %v", "denom does not exist"))
@@ -27,8 +45,25 @@ func handleMsgPutNFTOnAuction(ctx sdk.Context, k *Keeper, msg types.MsgPutNFTOnA
 		}
 	}
 
-	if err := k.PutNFTOnAuction(ctx, msg.TokenID, msg.Owner, msg.Beneficiary, msg.OpeningPrice,
-		msg.BuyoutPrice, msg.TimeToSell); err != nil {
+	params := k.GetParams(ctx)
+	duration := msg.TimeToSell.Sub(ctx.BlockHeader().Time)
+	if duration < params.MinAuctionDuration {
+		return wrapError(failMsg, fmt.Errorf("auction must last at least %s", params.MinAuctionDuration))
+	}
+	if duration > params.MaxAuctionDuration {
+		return wrapError(failMsg, fmt.Errorf("auction must last at most %s", params.MaxAuctionDuration))
+	}
+	if msg.RevealDuration > params.MaxAuctionDuration {
+		return wrapError(failMsg, fmt.Errorf("reveal window must last at most %s", params.MaxAuctionDuration))
+	}
+	if msg.ExtensionWindow > params.MaxAuctionDuration {
+		return wrapError(failMsg, fmt.Errorf("extension window must last at most %s", params.MaxAuctionDuration))
+	}
+	if !msg.MinBidIncrement.Empty() && !k.IsDenomExist(ctx, msg.MinBidIncrement) {
+		return wrapError(failMsg, fmt.Errorf("failed to PutNFTOnAuction: %v", "denom does not exist"))
+	}
+
+	if err := k.putNFTOnAuction(ctx, msg.Owner, msg.Beneficiary, lot); err != nil {
 		return wrapError(failMsg, fmt.Errorf("failed to PutNFTOnAuction: %v", err))
 	}
 
@@ -41,6 +76,12 @@ func handleMsgPutNFTOnAuction(ctx sdk.Context, k *Keeper, msg types.MsgPutNFTOnA
 			sdk.NewAttribute(types.AttributeKeyOpeningPrice, msg.OpeningPrice.String()),
 			sdk.NewAttribute(types.AttributeKeyBuyoutPrice, msg.BuyoutPrice.String()),
 			sdk.NewAttribute(types.AttributeKeyFinishTime, msg.TimeToSell.String()),
+			sdk.NewAttribute(types.AttributeKeyAuctionType, string(lot.AuctionType)),
+			sdk.NewAttribute(types.AttributeKeyFloorPrice, msg.FloorPrice.String()),
+			sdk.NewAttribute(types.AttributeKeyDeposit, msg.BidDeposit.String()),
+			sdk.NewAttribute(types.AttributeKeyMinBidIncrement, minBidIncrementString(lot)),
+			sdk.NewAttribute(types.AttributeKeyExtensionWindow, msg.ExtensionWindow.String()),
+			sdk.NewAttribute(types.AttributeKeyHasReserve, strconv.FormatBool(lot.HasReserve())),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -71,12 +112,14 @@ func handleMsgRemoveNFTFromAuction(ctx sdk.Context, k *Keeper, msg MsgRemoveNFTF
 			fmt.Errorf("auction lot owner: %v and finisher: %v do not match", msg.Owner, nft.Owner))
 	}
 
-	// return bid to last bidder if exists
-	if lot.LastBid != nil {
-		_, err := k.coinKeeper.AddCoins(ctx, lot.LastBid.Bidder, lot.LastBid.Bid)
-		if err != nil {
-			return wrapError(failMsg, err)
-		}
+	// the owner cannot back out after seeing the revealed bids
+	if lot.IsSealed() && !lot.ExpirationTime.After(ctx.BlockHeader().Time) {
+		return wrapError(failMsg, fmt.Errorf("bidding on sealed-bid auction is already closed"))
+	}
+
+	// return bids to bidders if exist
+	if err := k.refundAuctionBids(ctx, lot); err != nil {
+		return wrapError(failMsg, err)
 	}
 
 	// return nft to owner, delete lot
@@ -114,27 +157,22 @@ func handleMsgFinishAuction(ctx sdk.Context, k *Keeper, msg MsgFinishAuction) sd
 		return wrapError(failMsg, err)
 	}
 
-	// auction time has not expired yet
-	if lot.ExpirationTime.After(time.Now().UTC()) {
+	// bids on a sealed-bid auction are revealed until the end of the reveal window
+	if lot.IsSealed() && lot.RevealEndTime.After(ctx.BlockHeader().Time) {
+		return wrapError(failMsg, fmt.Errorf("reveal window is not over yet"))
+	}
+
+	// auction time has not expired yet, or the owner may still reveal the reserve price
+	if lot.SettlementTime().After(ctx.BlockHeader().Time) {
 		if !nft.Owner.Equals(msg.Owner) {
 			return wrapError(failMsg,
 				fmt.Errorf("auction lot owner: %v and finisher: %v do not match", msg.Owner, nft.Owner))
 		}
 	}
 
-	owner := nft.Owner.String()
-
-	// no bids on lot
-	if lot.LastBid != nil {
-		owner = lot.LastBid.Bidder.String()
-		if err := k.BuyLotOnAuction(ctx, lot.LastBid.Bidder, lot.LastBid.BuyerBeneficiary,
-			lot.LastBid.Bid, lot, lot.LastBid.BeneficiaryCommission); err != nil {
-			return wrapError(failMsg, err)
-		}
-	} else {
-		if err := k.RemoveNFTFromAuction(ctx, msg.TokenID, nft.Owner); err != nil {
-			return wrapError(failMsg, err)
-		}
+	owner, err := k.FinishAuction(ctx, lot)
+	if err != nil {
+		return wrapError(failMsg, err)
 	}
 
 	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgFinishAuction)
@@ -142,7 +180,7 @@ func handleMsgFinishAuction(ctx sdk.Context, k *Keeper, msg MsgFinishAuction) sd
 	ctx.EventManager().EmitEvents(sdk.Events{
 		sdk.NewEvent(
 			msg.Type(),
-			sdk.NewAttribute(types.AttributeKeyOwner, owner),
+			sdk.NewAttribute(types.AttributeKeyOwner, owner.String()),
 			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
 		),
 		sdk.NewEvent(
@@ -154,82 +192,180 @@ func handleMsgFinishAuction(ctx sdk.Context, k *Keeper, msg MsgFinishAuction) sd
 	return sdk.Result{Events: ctx.EventManager().Events()}
 }
 
+func handleMsgRevealReservePrice(ctx sdk.Context, k *Keeper, msg MsgRevealReservePrice) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRevealReservePrice)
+
+	failMsg := "failed to RevealReservePrice"
+	lot, err := k.GetAuctionLot(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	if !lot.HasReserve() {
+		return wrapError(failMsg, fmt.Errorf("lot has no reserve price"))
+	}
+
+	nft, err := k.GetNFT(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+	if !nft.Owner.Equals(msg.Owner) {
+		return wrapError(failMsg,
+			fmt.Errorf("auction lot owner: %v and revealer: %v do not match", nft.Owner, msg.Owner))
+	}
+
+	if !lot.RevealEndTime.After(ctx.BlockHeader().Time) {
+		return wrapError(failMsg, fmt.Errorf("reveal window is already closed"))
+	}
+	if types.ReservePriceHash(msg.ReservePrice, msg.Salt) != lot.ReserveHash {
+		return wrapError(failMsg, fmt.Errorf("reserve price and salt do not match the committed hash"))
+	}
+
+	// the reserve price is public from now on, the auction is settled right away
+	k.setReservePrice(ctx, lot.NFTID, msg.ReservePrice)
+	owner, err := k.FinishAuction(ctx, lot)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRevealReservePrice)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyOwner, owner.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyReservePrice, msg.ReservePrice.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
 func handleMsgMakeBidOnAuction(ctx sdk.Context, k *Keeper, msg MsgMakeBidOnAuction) sdk.Result {
 	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgMakeBidOnAuction)
 
-	logger := ctx.Logger()
 	failMsg := "failed to MakeBidOnAuction"
 	lot, err := k.GetAuctionLot(ctx, msg.TokenID)
 	if err != nil {
 		return wrapError(failMsg, err)
 	}
 
-	if lot.ExpirationTime.Before(time.Now().UTC()) {
+	if lot.ExpirationTime.Before(ctx.BlockHeader().Time) {
 		return wrapError(failMsg, fmt.Errorf("auction is already finished"))
 	}
 
-	beneficiariesCommission := types.DefaultBeneficiariesCommission
-	parsed, err := strconv.ParseFloat(msg.BeneficiaryCommission, 64)
-	if err == nil {
-		beneficiariesCommission = parsed
+	if lot.IsDutch() {
+		return wrapError(failMsg, fmt.Errorf("a Dutch auction takes no bids, buy the lot out at the current price"))
+	}
+
+	if lot.IsSealed() {
+		return wrapError(failMsg, fmt.Errorf("a sealed-bid auction takes committed bids only"))
+	}
+
+	if err := k.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return wrapError(failMsg, err)
 	}
-	if beneficiariesCommission > k.config.MaximumBeneficiaryCommission {
-		return wrapError(failMsg, fmt.Errorf("failed to BuyNFT: beneficiary commission is too high"))
+
+	// a proxy bid offers up to its maximum, the visible bid is computed below
+	offer := msg.Bid
+	if msg.IsProxy() {
+		offer = msg.MaxBid
 	}
-	beneficiariesCommissionString := fmt.Sprintf("%v", beneficiariesCommission)
 
 	// bid is less than lastBid
 	if lot.LastBid != nil {
-		if msg.Bid.IsAllLTE(lot.LastBid.Bid) {
-			return wrapError(failMsg, fmt.Errorf("bid: %+v is lower than last bid: %+v", msg.Bid, lot.LastBid.Bid))
+		if offer.IsAllLTE(lot.LastBid.Bid) {
+			return wrapError(failMsg, fmt.Errorf("bid: %+v is lower than last bid: %+v", offer, lot.LastBid.Bid))
 		}
 	}
 
 	// bid is less than opening price
-	if lot.OpeningPrice.IsAnyGT(msg.Bid) {
-		return wrapError(failMsg, fmt.Errorf("bid: %+v is lower than opening price: %+v", msg.Bid, lot.OpeningPrice))
+	if lot.OpeningPrice.IsAnyGT(offer) {
+		return wrapError(failMsg, fmt.Errorf("bid: %+v is lower than opening price: %+v", offer, lot.OpeningPrice))
 	}
 
-	// no buyout, change lastBid
-	balances := GetBalances(ctx, k, msg.Bidder, msg.BuyerBeneficiary)
-	if lot.LastBid != nil {
-		balances = append(balances,
-			GetBalances(ctx, k, lot.LastBid.Bidder, lot.LastBid.BuyerBeneficiary)...)
+	// bid does not raise lastBid by the minimum increment
+	if minBid := lot.MinNextBid(); lot.LastBid != nil && !offer.IsAllGTE(minBid) {
+		return wrapError(failMsg, fmt.Errorf("bid: %+v is lower than minimum next bid: %+v", offer, minBid))
+	}
+
+	blockTime := ctx.BlockHeader().Time
+	var bid sdk.Coins
+	leaderMaxBid := k.getProxyMaxBid(ctx, lot.NFTID)
+	if lot.LastBid != nil && leaderMaxBid.IsAllGTE(offer) {
+		if lot.LastBid.Bidder.Equals(msg.Bidder) {
+			return wrapError(failMsg, fmt.Errorf("bid: %+v does not raise the maximum bid of the bidder", offer))
+		}
+		// the leading proxy bid holds, an equal offer loses to the earlier bid
+		if !k.coinKeeper.HasCoins(ctx, msg.Bidder, offer) {
+			return wrapError(failMsg, fmt.Errorf("bidder does not have enough coins"))
+		}
+		bid = offer
+		k.appendBidHistory(ctx, lot, types.NewAuctionBid(msg.Bidder, msg.BuyerBeneficiary, bid,
+			msg.BeneficiaryCommission, blockTime))
+		lot.SetLastBid(types.NewAuctionBid(lot.LastBid.Bidder, lot.LastBid.BuyerBeneficiary,
+			minCoins(lot.MinBidOver(offer), leaderMaxBid), lot.LastBid.BeneficiaryCommission, blockTime))
+	} else {
+		bid = offer
+		if msg.IsProxy() {
+			bid = k.proxyBid(lot, msg.Bidder, leaderMaxBid, msg.MaxBid)
+		}
+
 		// return coins to previous bidder
-		_, err = k.coinKeeper.AddCoins(ctx, lot.LastBid.Bidder, lot.LastBid.Bid)
-		if err != nil {
-			RollbackCommissions(ctx, k, logger, balances)
+		if lot.LastBid != nil {
+			if err = k.UnlockCoins(ctx, lot.LastBid.Bidder, k.lockedBid(ctx, lot)); err != nil {
+				return wrapError(failMsg, err)
+			}
+		}
+
+		// take coins from new bidder
+		if err = k.LockCoins(ctx, msg.Bidder, offer); err != nil {
 			return wrapError(failMsg, err)
 		}
-	}
 
-	// take coins from new bidder
-	_, err = k.coinKeeper.SubtractCoins(ctx, msg.Bidder, msg.Bid)
-	if err != nil {
-		RollbackCommissions(ctx, k, logger, balances)
-		return wrapError(failMsg, err)
+		if msg.IsProxy() {
+			k.setProxyMaxBid(ctx, lot.NFTID, msg.MaxBid)
+		} else {
+			k.deleteProxyMaxBid(ctx, lot.NFTID)
+		}
+		lot.SetLastBid(types.NewAuctionBid(msg.Bidder, msg.BuyerBeneficiary, bid, msg.BeneficiaryCommission, blockTime))
 	}
 
-	auctionBid := types.NewAuctionBid(msg.Bidder, msg.BuyerBeneficiary, msg.Bid, beneficiariesCommissionString)
-	lot.SetLastBid(auctionBid)
+	k.appendBidHistory(ctx, lot, lot.LastBid)
+	// a late bid extends the auction and the reveal window of the reserve price along with it,
+	// UpdateAuctionLot moves the lot in the expiry queue
+	expirationTime := lot.ExtendedExpirationTime(blockTime)
+	if lot.HasReserve() {
+		lot.RevealEndTime = lot.RevealEndTime.Add(expirationTime.Sub(lot.ExpirationTime))
+	}
+	lot.ExpirationTime = expirationTime
 
 	if err := k.UpdateAuctionLot(ctx, lot); err != nil {
-		RollbackCommissions(ctx, k, logger, balances)
 		return wrapError(failMsg, err)
 	}
 
 	attrs := []sdk.Attribute{
 		sdk.NewAttribute(types.AttributeKeyBidder, msg.Bidder.String()),
 		sdk.NewAttribute(types.AttributeKeyBeneficiary, msg.BuyerBeneficiary.String()),
-		sdk.NewAttribute(types.AttributeKeyBid, msg.Bid.String()),
-		sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission),
+		sdk.NewAttribute(types.AttributeKeyBid, bid.String()),
+		sdk.NewAttribute(types.AttributeKeyIsProxy, strconv.FormatBool(msg.IsProxy())),
+		sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
 		sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+		sdk.NewAttribute(types.AttributeKeyLastBidder, lot.LastBid.Bidder.String()),
+		sdk.NewAttribute(types.AttributeKeyPrice, lot.LastBid.Bid.String()),
+		sdk.NewAttribute(types.AttributeKeyFinishTime, lot.ExpirationTime.String()),
 	}
 
-	// Bid is more than buyout price. Perform buyout.
+	// Last bid is more than buyout price. Perform buyout.
 	if !lot.BuyoutPrice.IsZero() {
-		if msg.Bid.IsAllGTE(lot.BuyoutPrice) {
-			err = k.BuyLotOnAuction(ctx, msg.Bidder, msg.BuyerBeneficiary, lot.BuyoutPrice, lot, msg.BeneficiaryCommission)
+		if lot.LastBid.Bid.IsAllGTE(lot.BuyoutPrice) {
+			err = k.BuyLotOnAuction(ctx, lot.LastBid.Bidder, lot.LastBid.BuyerBeneficiary, lot.BuyoutPrice, lot,
+				lot.LastBid.BeneficiaryCommission)
 			if err != nil {
 				return wrapError(failMsg, err)
 			}
@@ -262,15 +398,25 @@ func handleMsgBuyoutOnAuction(ctx sdk.Context, k *Keeper, msg MsgBuyoutOnAuction
 		return wrapError(failMsg, err)
 	}
 
-	if lot.ExpirationTime.Before(time.Now().UTC()) {
+	if lot.ExpirationTime.Before(ctx.BlockHeader().Time) {
 		return wrapError(failMsg, fmt.Errorf("auction is already finished"))
 	}
 
-	if lot.BuyoutPrice.IsZero() {
+	// the lot of a Dutch auction is bought out at its current price
+	price := lot.BuyoutPrice
+	if lot.IsDutch() {
+		price = lot.CurrentPrice(ctx.BlockHeader().Time)
+	}
+
+	if price.IsZero() {
 		return wrapError(failMsg, fmt.Errorf("lot has no buyoutprice"))
 	}
 
-	err = k.BuyLotOnAuction(ctx, msg.Buyer, msg.BuyerBeneficiary, lot.BuyoutPrice, lot, msg.BeneficiaryCommission)
+	if err := k.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	err = k.BuyLotOnAuction(ctx, msg.Buyer, msg.BuyerBeneficiary, price, lot, msg.BeneficiaryCommission)
 	if err != nil {
 		return wrapError(failMsg, err)
 	}
@@ -282,8 +428,9 @@ func handleMsgBuyoutOnAuction(ctx sdk.Context, k *Keeper, msg MsgBuyoutOnAuction
 			msg.Type(),
 			sdk.NewAttribute(types.AttributeKeyBidder, msg.Buyer.String()),
 			sdk.NewAttribute(types.AttributeKeyBeneficiary, msg.BuyerBeneficiary.String()),
-			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
 			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyPrice, price.String()),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -294,6 +441,22 @@ func handleMsgBuyoutOnAuction(ctx sdk.Context, k *Keeper, msg MsgBuyoutOnAuction
 	return sdk.Result{Events: ctx.EventManager().Events()}
 }
 
+// minBidIncrementString returns the minimum bid increment of the lot, absolute or as a share of the last bid.
+func minBidIncrementString(lot *types.AuctionLot) string {
+	if !lot.MinBidIncrementRate.IsNil() && lot.MinBidIncrementRate.IsPositive() {
+		return lot.MinBidIncrementRate.String()
+	}
+	return lot.MinBidIncrement.String()
+}
+
+// minCoins returns a if b covers it, otherwise b.
+func minCoins(a, b sdk.Coins) sdk.Coins {
+	if b.IsAllGTE(a) {
+		return a
+	}
+	return b
+}
+
 func wrapError(failMsg string, err error) sdk.Result {
 	return sdk.Result{
 		Code:      sdk.CodeUnknownRequest,
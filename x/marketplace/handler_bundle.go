@@ -0,0 +1,58 @@
+package marketplace
+
+import (
+	"strings"
+
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgCreateBundle(ctx sdk.Context, k *Keeper, msg MsgCreateBundle) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgCreateBundle)
+
+	if err := k.CreateBundle(ctx, msg.Owner, msg.BundleID, msg.TokenIDs); err != nil {
+		return wrapError("failed to CreateBundle", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgCreateBundle)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.BundleID),
+			sdk.NewAttribute(types.AttributeKeyItems, strings.Join(msg.TokenIDs, ",")),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgRemoveBundle(ctx sdk.Context, k *Keeper, msg MsgRemoveBundle) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRemoveBundle)
+
+	if err := k.RemoveBundle(ctx, msg.Owner, msg.BundleID); err != nil {
+		return wrapError("failed to RemoveBundle", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRemoveBundle)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.BundleID),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
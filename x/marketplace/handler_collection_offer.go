@@ -0,0 +1,169 @@
+package marketplace
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgMakeCollectionOffer(ctx sdk.Context, k *Keeper, msg MsgMakeCollectionOffer) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgMakeCollectionOffer)
+
+	if err := k.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return wrapError("failed to MakeCollectionOffer", err)
+	}
+
+	offer := &types.CollectionOffer{
+		ID:                    k.GetNextOfferID(ctx),
+		Denom:                 msg.Denom,
+		Buyer:                 msg.Buyer,
+		Price:                 msg.Price,
+		Quantity:              msg.GetQuantity(),
+		BuyerBeneficiary:      msg.BuyerBeneficiary,
+		BeneficiaryCommission: msg.BeneficiaryCommission,
+		TimeCreated:           ctx.BlockHeader().Time,
+	}
+	if err := k.LockCoins(ctx, msg.Buyer, offer.GetLocked()); err != nil {
+		return wrapError("failed to MakeCollectionOffer", err)
+	}
+	k.SetCollectionOffer(ctx, offer)
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgMakeCollectionOffer)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyOfferID, offer.ID),
+			sdk.NewAttribute(types.AttributeKeyDenom, msg.Denom),
+			sdk.NewAttribute(types.AttributeKeyPrice, msg.Price.String()),
+			sdk.NewAttribute(types.AttributeKeyQuantity, strconv.FormatUint(offer.Quantity, 10)),
+			sdk.NewAttribute(types.AttributeKeyBuyer, msg.Buyer.String()),
+			sdk.NewAttribute(types.AttributeKeyBeneficiary, msg.BuyerBeneficiary.String()),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Buyer.String()),
+		),
+	})
+	return sdk.Result{Data: []byte(offer.ID), Events: ctx.EventManager().Events()}
+}
+
+func handleMsgFillCollectionOffer(ctx sdk.Context, k *Keeper, msg MsgFillCollectionOffer) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgFillCollectionOffer)
+
+	offer, err := k.GetCollectionOffer(ctx, msg.OfferID)
+	if err != nil {
+		return wrapError("failed to FillCollectionOffer", err)
+	}
+	token, err := k.GetNFT(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError("failed to FillCollectionOffer", err)
+	}
+	if token.Denom != offer.Denom {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to FillCollectionOffer: NFT #%s is not of denom %s",
+			msg.TokenID, offer.Denom)).Result()
+	}
+	if !token.Owner.Equals(msg.Seller) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to FillCollectionOffer: %s is not the owner of NFT #%s",
+			msg.Seller, msg.TokenID)).Result()
+	}
+	if token.Status != types.NFTStatusDefault {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to FillCollectionOffer: NFT #%s is %s",
+			msg.TokenID, token.Status)).Result()
+	}
+	if err := k.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return wrapError("failed to FillCollectionOffer", err)
+	}
+
+	// Return the price of one NFT to the buyer so that doNFTCommissions works correctly
+	if err := k.UnlockCoins(ctx, offer.Buyer, offer.Price); err != nil {
+		return wrapError("failed to FillCollectionOffer", err)
+	}
+	priceAfterCommission, err := doNFTCommissions(
+		ctx,
+		k,
+		token,
+		offer.Buyer,
+		offer.BuyerBeneficiary,
+		msg.SellerBeneficiary,
+		offer.Price,
+		msg.BeneficiaryCommission,
+	)
+	if err != nil {
+		return wrapError("failed to FillCollectionOffer: failed to pay commissions", err)
+	}
+	if err := k.coinKeeper.SendCoins(ctx, offer.Buyer, token.Owner, priceAfterCommission); err != nil {
+		return wrapError("failed to FillCollectionOffer", err)
+	}
+
+	token.Owner = offer.Buyer
+	token.SetSellerBeneficiary(sdk.AccAddress{})
+	if err := k.UpdateNFT(ctx, token); err != nil {
+		return wrapError("failed to FillCollectionOffer", err)
+	}
+
+	offer.Quantity--
+	if offer.Quantity == 0 {
+		k.DeleteCollectionOffer(ctx, offer)
+	} else {
+		k.SetCollectionOffer(ctx, offer)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgFillCollectionOffer)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyOfferID, msg.OfferID),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyPrice, offer.Price.String()),
+			sdk.NewAttribute(types.AttributeKeyBuyer, offer.Buyer.String()),
+			sdk.NewAttribute(types.AttributeKeyQuantity, strconv.FormatUint(offer.Quantity, 10)),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Seller.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgRemoveCollectionOffer(ctx sdk.Context, k *Keeper, msg MsgRemoveCollectionOffer) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRemoveCollectionOffer)
+
+	offer, err := k.GetCollectionOffer(ctx, msg.OfferID)
+	if err != nil {
+		return wrapError("failed to RemoveCollectionOffer", err)
+	}
+	if !offer.Buyer.Equals(msg.Buyer) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveCollectionOffer: %s is not the buyer of offer %s",
+			msg.Buyer, msg.OfferID)).Result()
+	}
+	if err := k.UnlockCoins(ctx, offer.Buyer, offer.GetLocked()); err != nil {
+		return wrapError("failed to RemoveCollectionOffer", err)
+	}
+	k.DeleteCollectionOffer(ctx, offer)
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRemoveCollectionOffer)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyOfferID, msg.OfferID),
+			sdk.NewAttribute(types.AttributeKeyDenom, offer.Denom),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Buyer.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
@@ -0,0 +1,95 @@
+package marketplace
+
+import (
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgHashLockNFT(ctx sdk.Context, k *Keeper, msg MsgHashLockNFT) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgHashLockNFT)
+
+	lock := &types.HashLock{
+		NFTID:       msg.TokenID,
+		Owner:       msg.Owner,
+		Recipient:   msg.Recipient,
+		HashLock:    msg.HashLock,
+		Timeout:     msg.Timeout,
+		TimeCreated: ctx.BlockHeader().Time,
+	}
+	if err := k.HashLockNFT(ctx, lock); err != nil {
+		return wrapError("failed to HashLockNFT", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgHashLockNFT)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, msg.Recipient.String()),
+			sdk.NewAttribute(types.AttributeKeyHashLock, msg.HashLock),
+			sdk.NewAttribute(types.AttributeKeyTimeout, msg.Timeout.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgClaimHashLockedNFT(ctx sdk.Context, k *Keeper, msg MsgClaimHashLockedNFT) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgClaimHashLockedNFT)
+
+	lock, err := k.ClaimHashLockedNFT(ctx, msg.TokenID, msg.Recipient, msg.Preimage)
+	if err != nil {
+		return wrapError("failed to ClaimHashLockedNFT", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgClaimHashLockedNFT)
+
+	// the preimage is emitted so that the owner can claim the other side of a cross-chain swap with it
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyOwner, lock.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, msg.Recipient.String()),
+			sdk.NewAttribute(types.AttributeKeyHashLock, lock.HashLock),
+			sdk.NewAttribute(types.AttributeKeyPreimage, msg.Preimage),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Recipient.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgReclaimHashLockedNFT(ctx sdk.Context, k *Keeper, msg MsgReclaimHashLockedNFT) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgReclaimHashLockedNFT)
+
+	if err := k.ReclaimHashLockedNFT(ctx, msg.TokenID, msg.Owner); err != nil {
+		return wrapError("failed to ReclaimHashLockedNFT", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgReclaimHashLockedNFT)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
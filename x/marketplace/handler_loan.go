@@ -0,0 +1,186 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgRequestLoan(ctx sdk.Context, k *Keeper, msg MsgRequestLoan) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRequestLoan)
+
+	for _, coins := range []sdk.Coins{msg.Principal, msg.Interest} {
+		if !coins.Empty() && !k.IsDenomExist(ctx, coins) {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RequestLoan: denom does not exist")).Result()
+		}
+	}
+
+	loan := &types.Loan{
+		ID:          k.GetNextOfferID(ctx),
+		Borrower:    msg.Borrower,
+		NFTID:       msg.TokenID,
+		Principal:   msg.Principal,
+		Interest:    msg.Interest,
+		Duration:    msg.Duration,
+		TimeCreated: ctx.BlockHeader().Time,
+	}
+	if err := k.RequestLoan(ctx, loan); err != nil {
+		return wrapError("failed to RequestLoan", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRequestLoan)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyLoanID, loan.ID),
+			sdk.NewAttribute(types.AttributeKeyBorrower, msg.Borrower.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyPrincipal, msg.Principal.String()),
+			sdk.NewAttribute(types.AttributeKeyInterest, msg.Interest.String()),
+			sdk.NewAttribute(types.AttributeKeyDuration, msg.Duration.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Borrower.String()),
+		),
+	})
+	return sdk.Result{Data: []byte(loan.ID), Events: ctx.EventManager().Events()}
+}
+
+func handleMsgCancelLoanRequest(ctx sdk.Context, k *Keeper, msg MsgCancelLoanRequest) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgCancelLoanRequest)
+
+	loan, err := k.GetLoan(ctx, msg.LoanID)
+	if err != nil {
+		return wrapError("failed to CancelLoanRequest", err)
+	}
+	if !loan.Borrower.Equals(msg.Borrower) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to CancelLoanRequest: %s is not the borrower of loan %s",
+			msg.Borrower, msg.LoanID)).Result()
+	}
+	if err := k.CancelLoanRequest(ctx, loan); err != nil {
+		return wrapError("failed to CancelLoanRequest", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgCancelLoanRequest)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyLoanID, loan.ID),
+			sdk.NewAttribute(types.AttributeKeyBorrower, loan.Borrower.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, loan.NFTID),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Borrower.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgFundLoan(ctx sdk.Context, k *Keeper, msg MsgFundLoan) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgFundLoan)
+
+	loan, err := k.GetLoan(ctx, msg.LoanID)
+	if err != nil {
+		return wrapError("failed to FundLoan", err)
+	}
+	if err := k.FundLoan(ctx, loan, msg.Lender); err != nil {
+		return wrapError("failed to FundLoan", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgFundLoan)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyLoanID, loan.ID),
+			sdk.NewAttribute(types.AttributeKeyBorrower, loan.Borrower.String()),
+			sdk.NewAttribute(types.AttributeKeyLender, msg.Lender.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, loan.NFTID),
+			sdk.NewAttribute(types.AttributeKeyPrincipal, loan.Principal.String()),
+			sdk.NewAttribute(types.AttributeKeyDeadline, loan.Deadline.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Lender.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgRepayLoan(ctx sdk.Context, k *Keeper, msg MsgRepayLoan) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRepayLoan)
+
+	loan, err := k.GetLoan(ctx, msg.LoanID)
+	if err != nil {
+		return wrapError("failed to RepayLoan", err)
+	}
+	if !loan.Borrower.Equals(msg.Borrower) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RepayLoan: %s is not the borrower of loan %s",
+			msg.Borrower, msg.LoanID)).Result()
+	}
+	if err := k.RepayLoan(ctx, loan); err != nil {
+		return wrapError("failed to RepayLoan", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRepayLoan)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyLoanID, loan.ID),
+			sdk.NewAttribute(types.AttributeKeyBorrower, loan.Borrower.String()),
+			sdk.NewAttribute(types.AttributeKeyLender, loan.Lender.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, loan.NFTID),
+			sdk.NewAttribute(types.AttributeKeyAmount, loan.GetRepayment().String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Borrower.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgClaimLoanCollateral(ctx sdk.Context, k *Keeper, msg MsgClaimLoanCollateral) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgClaimLoanCollateral)
+
+	loan, err := k.GetLoan(ctx, msg.LoanID)
+	if err != nil {
+		return wrapError("failed to ClaimLoanCollateral", err)
+	}
+	if !loan.Lender.Equals(msg.Lender) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to ClaimLoanCollateral: %s is not the lender of loan %s",
+			msg.Lender, msg.LoanID)).Result()
+	}
+	if err := k.ClaimLoanCollateral(ctx, loan); err != nil {
+		return wrapError("failed to ClaimLoanCollateral", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgClaimLoanCollateral)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyLoanID, loan.ID),
+			sdk.NewAttribute(types.AttributeKeyBorrower, loan.Borrower.String()),
+			sdk.NewAttribute(types.AttributeKeyLender, loan.Lender.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, loan.NFTID),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Lender.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
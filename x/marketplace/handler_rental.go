@@ -0,0 +1,110 @@
+package marketplace
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgListNFTForRent(ctx sdk.Context, k *Keeper, msg MsgListNFTForRent) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgListNFTForRent)
+
+	if !k.IsDenomExist(ctx, msg.Price) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to ListNFTForRent: denom does not exist")).Result()
+	}
+
+	listing := &types.RentalListing{
+		NFTID:       msg.TokenID,
+		Owner:       msg.Owner,
+		Price:       msg.Price,
+		Unit:        msg.Unit,
+		MaxDuration: msg.MaxDuration,
+		TimeCreated: ctx.BlockHeader().Time,
+	}
+	if err := k.ListNFTForRent(ctx, listing); err != nil {
+		return wrapError("failed to ListNFTForRent", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgListNFTForRent)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyPrice, msg.Price.String()),
+			sdk.NewAttribute(types.AttributeKeyUnit, msg.Unit),
+			sdk.NewAttribute(types.AttributeKeyMaxDuration, strconv.FormatUint(msg.MaxDuration, 10)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgRemoveRentalListing(ctx sdk.Context, k *Keeper, msg MsgRemoveRentalListing) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRemoveRentalListing)
+
+	listing, err := k.GetRentalListing(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError("failed to RemoveRentalListing", err)
+	}
+	if !listing.Owner.Equals(msg.Owner) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to RemoveRentalListing: %s is not the owner of the listing of NFT #%s",
+			msg.Owner, msg.TokenID)).Result()
+	}
+	k.DeleteRentalListing(ctx, msg.TokenID)
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRemoveRentalListing)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyOwner, msg.Owner.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgRentNFT(ctx sdk.Context, k *Keeper, msg MsgRentNFT) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRentNFT)
+
+	rental, err := k.RentNFT(ctx, msg.TokenID, msg.Renter, msg.Duration)
+	if err != nil {
+		return wrapError("failed to RentNFT", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRentNFT)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyOwner, rental.Owner.String()),
+			sdk.NewAttribute(types.AttributeKeyUser, msg.Renter.String()),
+			sdk.NewAttribute(types.AttributeKeyPrice, rental.Price.String()),
+			sdk.NewAttribute(types.AttributeKeyDuration, strconv.FormatUint(rental.Duration, 10)),
+			sdk.NewAttribute(types.AttributeKeyUnit, rental.Unit),
+			sdk.NewAttribute(types.AttributeKeyExpirationTime, rental.ExpirationTime.String()),
+			sdk.NewAttribute(types.AttributeKeyExpirationHeight, strconv.FormatInt(rental.ExpirationHeight, 10)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Renter.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
@@ -0,0 +1,170 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgCommitSealedBid(ctx sdk.Context, k *Keeper, msg MsgCommitSealedBid) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgCommitSealedBid)
+
+	failMsg := "failed to CommitSealedBid"
+	lot, err := k.GetAuctionLot(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	if !lot.IsSealed() {
+		return wrapError(failMsg, fmt.Errorf("lot is not on a sealed-bid auction"))
+	}
+
+	if !lot.ExpirationTime.After(ctx.BlockHeader().Time) {
+		return wrapError(failMsg, fmt.Errorf("bidding is already closed"))
+	}
+
+	if err := k.checkBeneficiaryCommission(ctx, msg.BeneficiaryCommission); err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	// a new commitment replaces the previous one of the bidder, the deposit is locked once
+	bid, err := k.GetSealedBid(ctx, msg.TokenID, msg.Bidder)
+	if err != nil {
+		if err := k.LockCoins(ctx, msg.Bidder, lot.BidDeposit); err != nil {
+			return wrapError(failMsg, err)
+		}
+		bid = types.NewSealedBid(msg.TokenID, msg.Bidder, msg.BuyerBeneficiary, msg.BeneficiaryCommission,
+			msg.BidHash, lot.BidDeposit, ctx.BlockHeader().Time)
+	} else {
+		bid.BuyerBeneficiary = msg.BuyerBeneficiary
+		bid.BeneficiaryCommission = msg.BeneficiaryCommission
+		bid.BidHash = msg.BidHash
+		bid.TimeCreated = ctx.BlockHeader().Time
+	}
+	k.SetSealedBid(ctx, bid)
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgCommitSealedBid)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyBidder, msg.Bidder.String()),
+			sdk.NewAttribute(types.AttributeKeyBeneficiary, msg.BuyerBeneficiary.String()),
+			sdk.NewAttribute(types.AttributeKeyCommission, msg.BeneficiaryCommission.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyBidHash, msg.BidHash),
+			sdk.NewAttribute(types.AttributeKeyDeposit, bid.Deposit.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Bidder.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgRevealSealedBid(ctx sdk.Context, k *Keeper, msg MsgRevealSealedBid) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgRevealSealedBid)
+
+	failMsg := "failed to RevealSealedBid"
+	lot, err := k.GetAuctionLot(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	if !lot.IsSealed() {
+		return wrapError(failMsg, fmt.Errorf("lot is not on a sealed-bid auction"))
+	}
+
+	blockTime := ctx.BlockHeader().Time
+	if lot.ExpirationTime.After(blockTime) {
+		return wrapError(failMsg, fmt.Errorf("bidding is not closed yet"))
+	}
+	if !lot.RevealEndTime.After(blockTime) {
+		return wrapError(failMsg, fmt.Errorf("reveal window is already closed"))
+	}
+
+	bid, err := k.GetSealedBid(ctx, msg.TokenID, msg.Bidder)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+	if bid.IsRevealed() {
+		return wrapError(failMsg, fmt.Errorf("bid is already revealed"))
+	}
+	if types.SealedBidHash(msg.Bidder, msg.Bid, msg.Salt) != bid.BidHash {
+		return wrapError(failMsg, fmt.Errorf("bid and salt do not match the committed hash"))
+	}
+
+	// bid is less than opening price
+	if len(msg.Bid) != 1 || !msg.Bid.IsAllGTE(lot.OpeningPrice) {
+		return wrapError(failMsg, fmt.Errorf("bid: %+v is lower than opening price: %+v", msg.Bid, lot.OpeningPrice))
+	}
+
+	// take coins from bidder, the deposit stays locked until settlement
+	if err := k.LockCoins(ctx, msg.Bidder, msg.Bid); err != nil {
+		return wrapError(failMsg, err)
+	}
+	bid.Bid = msg.Bid
+	k.SetSealedBid(ctx, bid)
+	k.appendBidHistory(ctx, lot,
+		types.NewAuctionBid(msg.Bidder, bid.BuyerBeneficiary, msg.Bid, bid.BeneficiaryCommission, blockTime))
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgRevealSealedBid)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyBidder, msg.Bidder.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+			sdk.NewAttribute(types.AttributeKeyBid, msg.Bid.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Bidder.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgSettleSealedAuction(ctx sdk.Context, k *Keeper, msg MsgSettleSealedAuction) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgSettleSealedAuction)
+
+	failMsg := "failed to SettleSealedAuction"
+	lot, err := k.GetAuctionLot(ctx, msg.TokenID)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	if !lot.IsSealed() {
+		return wrapError(failMsg, fmt.Errorf("lot is not on a sealed-bid auction"))
+	}
+
+	if lot.RevealEndTime.After(ctx.BlockHeader().Time) {
+		return wrapError(failMsg, fmt.Errorf("reveal window is not over yet"))
+	}
+
+	owner, err := k.FinishAuction(ctx, lot)
+	if err != nil {
+		return wrapError(failMsg, err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgSettleSealedAuction)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeyOwner, owner.String()),
+			sdk.NewAttribute(types.AttributeKeyNFTID, msg.TokenID),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
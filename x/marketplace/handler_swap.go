@@ -0,0 +1,127 @@
+package marketplace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corestario/marketplace/common"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func handleMsgProposeSwap(ctx sdk.Context, k *Keeper, msg MsgProposeSwap) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgProposeSwap)
+
+	for _, coins := range []sdk.Coins{msg.ProposerCoins, msg.CounterpartyCoins} {
+		if !coins.Empty() && !k.IsDenomExist(ctx, coins) {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("failed to ProposeSwap: denom does not exist")).Result()
+		}
+	}
+
+	swap := &types.Swap{
+		ID:                k.GetNextOfferID(ctx),
+		Proposer:          msg.Proposer,
+		Counterparty:      msg.Counterparty,
+		ProposerNFTs:      msg.ProposerNFTs,
+		ProposerCoins:     msg.ProposerCoins,
+		CounterpartyNFTs:  msg.CounterpartyNFTs,
+		CounterpartyCoins: msg.CounterpartyCoins,
+		ExpirationTime:    msg.ExpirationTime,
+		TimeCreated:       ctx.BlockHeader().Time,
+	}
+	if err := k.ProposeSwap(ctx, swap); err != nil {
+		return wrapError("failed to ProposeSwap", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgProposeSwap)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeySwapID, swap.ID),
+			sdk.NewAttribute(types.AttributeKeyProposer, msg.Proposer.String()),
+			sdk.NewAttribute(types.AttributeKeyCounterparty, msg.Counterparty.String()),
+			sdk.NewAttribute(types.AttributeKeyProposerNFTs, strings.Join(msg.ProposerNFTs, ",")),
+			sdk.NewAttribute(types.AttributeKeyProposerCoins, msg.ProposerCoins.String()),
+			sdk.NewAttribute(types.AttributeKeyCounterpartyNFTs, strings.Join(msg.CounterpartyNFTs, ",")),
+			sdk.NewAttribute(types.AttributeKeyCounterpartyCoins, msg.CounterpartyCoins.String()),
+			sdk.NewAttribute(types.AttributeKeyExpirationTime, msg.ExpirationTime.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Proposer.String()),
+		),
+	})
+	return sdk.Result{Data: []byte(swap.ID), Events: ctx.EventManager().Events()}
+}
+
+func handleMsgAcceptSwap(ctx sdk.Context, k *Keeper, msg MsgAcceptSwap) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgAcceptSwap)
+
+	swap, err := k.GetSwap(ctx, msg.SwapID)
+	if err != nil {
+		return wrapError("failed to AcceptSwap", err)
+	}
+	if !swap.Counterparty.Equals(msg.Counterparty) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to AcceptSwap: %s is not the counterparty of swap %s",
+			msg.Counterparty, msg.SwapID)).Result()
+	}
+	if err := k.AcceptSwap(ctx, swap); err != nil {
+		return wrapError("failed to AcceptSwap", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgAcceptSwap)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeySwapID, swap.ID),
+			sdk.NewAttribute(types.AttributeKeyProposer, swap.Proposer.String()),
+			sdk.NewAttribute(types.AttributeKeyCounterparty, swap.Counterparty.String()),
+			sdk.NewAttribute(types.AttributeKeyProposerNFTs, strings.Join(swap.ProposerNFTs, ",")),
+			sdk.NewAttribute(types.AttributeKeyProposerCoins, swap.ProposerCoins.String()),
+			sdk.NewAttribute(types.AttributeKeyCounterpartyNFTs, strings.Join(swap.CounterpartyNFTs, ",")),
+			sdk.NewAttribute(types.AttributeKeyCounterpartyCoins, swap.CounterpartyCoins.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Counterparty.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleMsgCancelSwap(ctx sdk.Context, k *Keeper, msg MsgCancelSwap) sdk.Result {
+	k.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgCancelSwap)
+
+	swap, err := k.GetSwap(ctx, msg.SwapID)
+	if err != nil {
+		return wrapError("failed to CancelSwap", err)
+	}
+	if !swap.Proposer.Equals(msg.Proposer) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to CancelSwap: %s is not the proposer of swap %s",
+			msg.Proposer, msg.SwapID)).Result()
+	}
+	if err := k.CancelSwap(ctx, swap); err != nil {
+		return wrapError("failed to CancelSwap", err)
+	}
+
+	k.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgCancelSwap)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			msg.Type(),
+			sdk.NewAttribute(types.AttributeKeySwapID, swap.ID),
+			sdk.NewAttribute(types.AttributeKeyProposer, swap.Proposer.String()),
+			sdk.NewAttribute(types.AttributeKeyCounterparty, swap.Counterparty.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Proposer.String()),
+		),
+	})
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
@@ -0,0 +1,124 @@
+package marketplace_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestHashLock(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	owner, recipient, other := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+	now := ctx.BlockHeader().Time
+
+	preimage := hex.EncodeToString([]byte("the secret of the other chain"))
+	hash := sha256.Sum256([]byte("the secret of the other chain"))
+	hashLock := hex.EncodeToString(hash[:])
+	lockNFT := func(id string) sdk.Result {
+		return handler(ctx, *types.NewMsgHashLockNFT(owner, recipient, id, hashLock, now.Add(time.Hour)))
+	}
+
+	// a lock that has already timed out or of an NFT on sale is rejected
+	sword := mintNFT(t, mpKeeperTest, owner)
+	require.False(t, handler(ctx, *types.NewMsgHashLockNFT(owner, recipient, sword, hashLock, now)).IsOK())
+	result := handler(ctx, *types.NewMsgPutOnMarketNFT(owner, owner, sword, coins(100)))
+	require.True(t, result.IsOK(), result.Log)
+	require.False(t, lockNFT(sword).IsOK())
+	result = handler(ctx, *types.NewMsgRemoveNFTFromMarket(owner, sword))
+	require.True(t, result.IsOK(), result.Log)
+
+	// the locked NFT cannot be sold or transferred
+	result = lockNFT(sword)
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusHashLocked, sword)
+	require.False(t, handler(ctx, *types.NewMsgPutOnMarketNFT(owner, owner, sword, coins(100))).IsOK())
+	require.NotNil(t, keeper.TransferNFT(ctx, sword, owner, other))
+	require.False(t, lockNFT(sword).IsOK())
+
+	bz, sdkErr := querier(ctx, []string{marketplace.QueryHashLock, sword}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	var lock types.HashLock
+	types.ModuleCdc.MustUnmarshalJSON(bz, &lock)
+	require.Equal(t, hashLock, lock.HashLock)
+	require.True(t, lock.Recipient.Equals(recipient))
+
+	// only the recipient claims with the preimage, the owner cannot reclaim before the timeout
+	require.False(t, handler(ctx, *types.NewMsgClaimHashLockedNFT(recipient, sword, hex.EncodeToString([]byte("guess")))).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgClaimHashLockedNFT(other, sword, preimage)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgReclaimHashLockedNFT(owner, sword)).IsOK())
+
+	claimMsg := types.NewMsgClaimHashLockedNFT(recipient, sword, preimage)
+	result = handler(ctx, *claimMsg)
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, recipient, types.NFTStatusDefault, sword)
+	_, sdkErr = querier(ctx, []string{marketplace.QueryHashLock, sword}, abci.RequestQuery{})
+	require.NotNil(t, sdkErr)
+
+	var revealed []string
+	for _, event := range result.Events {
+		if event.Type != claimMsg.Type() {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == types.AttributeKeyPreimage {
+				revealed = append(revealed, string(attr.Value))
+			}
+		}
+	}
+	require.Equal(t, []string{preimage}, revealed)
+
+	// after the timeout the recipient cannot claim and the owner reclaims the NFT
+	shield := mintNFT(t, mpKeeperTest, owner)
+	result = lockNFT(shield)
+	require.True(t, result.IsOK(), result.Log)
+	ctx = ctx.WithBlockTime(now.Add(time.Hour))
+	require.False(t, handler(ctx, *types.NewMsgClaimHashLockedNFT(recipient, shield, preimage)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgReclaimHashLockedNFT(recipient, shield)).IsOK())
+	result = handler(ctx, *types.NewMsgReclaimHashLockedNFT(owner, shield))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusDefault, shield)
+
+	msgInv, broken := marketplace.NFTIndexesInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestHashLockValidateBasic(t *testing.T) {
+	owner, recipient := sdk.AccAddress([]byte("owner")), sdk.AccAddress([]byte("recipient"))
+	hash := sha256.Sum256([]byte("secret"))
+	hashLock := hex.EncodeToString(hash[:])
+	timeout := time.Now()
+
+	require.Nil(t, types.NewMsgHashLockNFT(owner, recipient, "token", hashLock, timeout).ValidateBasic())
+	for name, msg := range map[string]*types.MsgHashLockNFT{
+		"no recipient":     types.NewMsgHashLockNFT(owner, sdk.AccAddress{}, "token", hashLock, timeout),
+		"same recipient":   types.NewMsgHashLockNFT(owner, owner, "token", hashLock, timeout),
+		"no token":         types.NewMsgHashLockNFT(owner, recipient, "", hashLock, timeout),
+		"short hash lock":  types.NewMsgHashLockNFT(owner, recipient, "token", hashLock[2:], timeout),
+		"hash lock no hex": types.NewMsgHashLockNFT(owner, recipient, "token", "secret", timeout),
+		"no timeout":       types.NewMsgHashLockNFT(owner, recipient, "token", hashLock, time.Time{}),
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+
+	require.Nil(t, types.NewMsgClaimHashLockedNFT(recipient, "token", hex.EncodeToString([]byte("secret"))).ValidateBasic())
+	require.NotNil(t, types.NewMsgClaimHashLockedNFT(recipient, "token", "").ValidateBasic())
+	require.NotNil(t, types.NewMsgClaimHashLockedNFT(recipient, "token", "secret").ValidateBasic())
+	require.NotNil(t, types.NewMsgClaimHashLockedNFT(recipient, "token",
+		hex.EncodeToString(make([]byte, types.MaxPreimageSize+1))).ValidateBasic())
+}
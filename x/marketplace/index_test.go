@@ -0,0 +1,117 @@
+package marketplace_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// scanNFTs returns the IDs of the NFTs matching the filter by walking the whole NFT store.
+func scanNFTs(mp *marketplaceKeeperTest, match func(token *types.NFT) bool) []string {
+	var ids []string
+	iterator := mp.marketKeeper.GetNFTsIterator(mp.ctx)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var token types.NFT
+		types.ModuleCdc.MustUnmarshalJSON(iterator.Value(), &token)
+		if match(&token) {
+			ids = append(ids, token.ID)
+		}
+	}
+	return ids
+}
+
+func getIDs(nfts []*types.NFT) []string {
+	var ids []string
+	for _, token := range nfts {
+		ids = append(ids, token.ID)
+	}
+	return ids
+}
+
+func TestNFTIndexesMatchFullScan(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	addrs := mpKeeperTest.addrs
+	denoms := []string{"art", "arts", "music"}
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	nftHandler := marketplace.CustomNFTHandler(mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(100)))
+	finishTime := mpKeeperTest.ctx.BlockHeader().Time.Add(time.Hour)
+	r := rand.New(rand.NewSource(1))
+
+	var ids []string
+	for i := 0; i < 300; i++ {
+		ctx := mpKeeperTest.ctx
+		if len(ids) == 0 || r.Intn(4) == 0 {
+			owner := addrs[r.Intn(len(addrs))]
+			msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denoms[r.Intn(len(denoms))], "")
+			require.True(t, nftHandler(ctx, msg).IsOK())
+			ids = append(ids, msg.ID)
+			continue
+		}
+
+		// random operations, many of them fail and must leave the indexes untouched
+		id := ids[r.Intn(len(ids))]
+		token, err := mpKeeperTest.marketKeeper.GetNFT(ctx, id)
+		if err != nil {
+			continue
+		}
+		other := addrs[r.Intn(len(addrs))]
+		switch r.Intn(6) {
+		case 0:
+			handler(ctx, *types.NewMsgPutOnMarketNFT(token.Owner, token.Owner, id, price))
+		case 1:
+			handler(ctx, *types.NewMsgBuyNFT(other, other, id, defaultCommission))
+		case 2:
+			nftHandler(ctx, nft.NewMsgTransferNFT(token.Owner, other, token.Denom, id))
+		case 3:
+			handler(ctx, *types.NewMsgPutNFTOnAuction(token.Owner, token.Owner, id, price, sdk.Coins{}, finishTime))
+		case 4:
+			handler(ctx, *types.NewMsgRemoveNFTFromMarket(token.Owner, id))
+		case 5:
+			nftHandler(ctx, nft.NewMsgBurnNFT(token.Owner, id, token.Denom))
+		}
+	}
+
+	ctx := mpKeeperTest.ctx
+	keeper := mpKeeperTest.marketKeeper
+	for _, addr := range addrs {
+		require.Equal(t, scanNFTs(mpKeeperTest, func(token *types.NFT) bool { return token.Owner.Equals(addr) }),
+			getIDs(keeper.GetNFTsByOwner(ctx, addr)))
+	}
+	for _, status := range []types.NFTStatus{types.NFTStatusDefault, types.NFTStatusOnMarket, types.NFTStatusOnAuction} {
+		require.Equal(t, scanNFTs(mpKeeperTest, func(token *types.NFT) bool { return token.Status == status }),
+			getIDs(keeper.GetNFTsByStatus(ctx, status)))
+	}
+	require.NotEmpty(t, keeper.GetNFTsOnMarket(ctx))
+	require.NotEmpty(t, keeper.GetNFTsByStatus(ctx, types.NFTStatusOnAuction))
+	for _, d := range denoms {
+		require.Equal(t, scanNFTs(mpKeeperTest, func(token *types.NFT) bool { return token.Denom == d }),
+			getIDs(keeper.GetNFTsByDenom(ctx, d)))
+	}
+
+	msg, broken := marketplace.NFTIndexesInvariant(keeper)(ctx)
+	require.False(t, broken, msg)
+
+	// the filtered query returns what the full scan does
+	params := types.QueryNFTsParams{Owner: addrs[0], Status: "on_market", Denom: "art", Limit: types.MaxQueryNFTsLimit}
+	res := queryNFTs(t, mpKeeperTest, params)
+	require.Equal(t, scanNFTs(mpKeeperTest, func(token *types.NFT) bool {
+		return token.Owner.Equals(addrs[0]) && token.IsOnMarket() && token.Denom == "art"
+	}), nftIDs(res))
+}
@@ -0,0 +1,120 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+)
+
+// RegisterInvariants registers all marketplace invariants
+func RegisterInvariants(ir sdk.InvariantRegistry, k *Keeper) {
+	ir.RegisterRoute(types.ModuleName, "escrow", EscrowInvariant(k))
+	ir.RegisterRoute(types.ModuleName, "nft-indexes", NFTIndexesInvariant(k))
+}
+
+// EscrowInvariant checks that the marketplace module account holds exactly
+// the coins locked in outstanding auction bids, proxy bid maximums, sealed bid deposits, offers,
+// collection offers and swaps. The crisis module of the app asserts it every inv-check-period blocks.
+func EscrowInvariant(k *Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		locked := k.lockedCoins(ctx)
+		balance := k.coinKeeper.GetCoins(ctx, supply.NewModuleAddress(types.ModuleName))
+		broken := !balance.IsAllGTE(locked) || !locked.IsAllGTE(balance)
+
+		return sdk.FormatInvariant(types.ModuleName, "escrow", fmt.Sprintf(
+			"\tsum of bids, offers and swaps: %v\n\tmodule account balance: %v\n", locked, balance)), broken
+	}
+}
+
+// NFTIndexesInvariant checks that the owner, status and denom indexes list
+// every NFT under its current values and nothing else.
+func NFTIndexesInvariant(k *Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		store := ctx.KVStore(k.storeKey)
+		var msg string
+		count := 0
+
+		nftIterator := k.GetNFTsIterator(ctx)
+		for ; nftIterator.Valid(); nftIterator.Next() {
+			var token types.NFT
+			k.cdc.MustUnmarshalJSON(nftIterator.Value(), &token)
+			count++
+			if !store.Has(types.GetNFTOwnerIndexKey(token.Owner, token.ID)) ||
+				!store.Has(types.GetNFTStatusIndexKey(token.Status, token.ID)) ||
+				!store.Has(types.GetNFTDenomIndexKey(token.Denom, token.ID)) {
+				msg += fmt.Sprintf("\tNFT %s is missing from an index\n", token.ID)
+			}
+		}
+		nftIterator.Close()
+
+		for _, prefix := range [][]byte{types.NFTOwnerIndexPrefix, types.NFTStatusIndexPrefix, types.NFTDenomIndexPrefix} {
+			if entries := countKeys(store, prefix); entries != count {
+				msg += fmt.Sprintf("\tindex %X has %d entries for %d NFTs\n", prefix, entries, count)
+			}
+		}
+
+		return sdk.FormatInvariant(types.ModuleName, "nft-indexes", msg), msg != ""
+	}
+}
+
+func countKeys(store sdk.KVStore, prefix []byte) int {
+	count := 0
+	iterator := sdk.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		count++
+	}
+	return count
+}
+
+// lockedCoins returns the coins locked in outstanding auction bids, proxy bid maximums, sealed bid deposits,
+// offers, collection offers and swaps.
+func (k *Keeper) lockedCoins(ctx sdk.Context) sdk.Coins {
+	var locked sdk.Coins
+
+	lotsIterator := k.GetAuctionLotsIterator(ctx)
+	for ; lotsIterator.Valid(); lotsIterator.Next() {
+		var lot types.AuctionLot
+		k.cdc.MustUnmarshalJSON(lotsIterator.Value(), &lot)
+		if lot.LastBid != nil {
+			locked = locked.Add(k.lockedBid(ctx, &lot))
+		}
+	}
+	lotsIterator.Close()
+
+	sealedBidsIterator := k.GetSealedBidsIterator(ctx)
+	for ; sealedBidsIterator.Valid(); sealedBidsIterator.Next() {
+		var bid types.SealedBid
+		k.cdc.MustUnmarshalJSON(sealedBidsIterator.Value(), &bid)
+		locked = locked.Add(bid.Deposit.Add(bid.Bid))
+	}
+	sealedBidsIterator.Close()
+
+	offersIterator := k.GetOffersIterator(ctx)
+	for ; offersIterator.Valid(); offersIterator.Next() {
+		var offer types.Offer
+		k.cdc.MustUnmarshalJSON(offersIterator.Value(), &offer)
+		locked = locked.Add(offer.Price)
+	}
+	offersIterator.Close()
+
+	collectionOffersIterator := k.GetCollectionOffersIterator(ctx)
+	for ; collectionOffersIterator.Valid(); collectionOffersIterator.Next() {
+		var offer types.CollectionOffer
+		k.cdc.MustUnmarshalJSON(collectionOffersIterator.Value(), &offer)
+		locked = locked.Add(offer.GetLocked())
+	}
+	collectionOffersIterator.Close()
+
+	swapsIterator := k.GetSwapsIterator(ctx)
+	for ; swapsIterator.Valid(); swapsIterator.Next() {
+		var swap types.Swap
+		k.cdc.MustUnmarshalJSON(swapsIterator.Value(), &swap)
+		locked = locked.Add(swap.ProposerCoins)
+	}
+	swapsIterator.Close()
+
+	return locked
+}
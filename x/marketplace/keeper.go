@@ -4,16 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
-	"time"
 
 	"github.com/cosmos/cosmos-sdk/x/ibc"
 	"github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
 	transfer "github.com/cosmos/cosmos-sdk/x/ibc/20-transfer"
 
 	"github.com/corestario/marketplace/common"
-	"github.com/corestario/marketplace/x/marketplace/config"
 	"github.com/corestario/marketplace/x/marketplace/types"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -21,6 +18,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/distribution"
 	channeltypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/supply"
 	"github.com/cosmos/modules/incubator/nft"
@@ -36,14 +34,14 @@ type Keeper struct {
 	deletedStoreKey          *sdk.KVStoreKey
 	currencyRegistryStoreKey *sdk.KVStoreKey
 	auctionStoreKey          *sdk.KVStoreKey
+	offerStoreKey            *sdk.KVStoreKey
 	cdc                      *codec.Codec // The wire codec for binary encoding/decoding.
-	config                   *config.MPServerConfig
+	paramSpace               params.Subspace
 	msgMetr                  *common.MsgMetrics
 	nftKeeper                *nft.Keeper
 	supplyKeeper             *supply.Keeper
 	accKeeper                *auth.AccountKeeper
 	ibcKeeper                *ibc.Keeper
-	httpCli                  *http.Client
 }
 
 // NewKeeper creates new instances of the marketplace Keeper
@@ -55,8 +53,9 @@ func NewKeeper(
 	deletedStoreKey *sdk.KVStoreKey,
 	currencyRegistryStoreKey *sdk.KVStoreKey,
 	auctionStoreKey *sdk.KVStoreKey,
+	offerStoreKey *sdk.KVStoreKey,
 	cdc *codec.Codec,
-	cfg *config.MPServerConfig,
+	paramSpace params.Subspace,
 	msgMetr *common.MsgMetrics,
 	nftKeeper *nft.Keeper,
 	supplyKeeper *supply.Keeper,
@@ -71,14 +70,14 @@ func NewKeeper(
 		deletedStoreKey:          deletedStoreKey,
 		currencyRegistryStoreKey: currencyRegistryStoreKey,
 		auctionStoreKey:          auctionStoreKey,
+		offerStoreKey:            offerStoreKey,
 		cdc:                      cdc,
-		config:                   cfg,
+		paramSpace:               paramSpace.WithKeyTable(types.ParamKeyTable()),
 		msgMetr:                  msgMetr,
 		nftKeeper:                nftKeeper,
 		supplyKeeper:             supplyKeeper,
 		accKeeper:                accKeeper,
 		ibcKeeper:                ibcKeeper,
-		httpCli:                  &http.Client{Timeout: time.Second * 5},
 	}
 }
 
@@ -93,11 +92,11 @@ func (k *Keeper) increaseCounter(labels ...string) {
 
 func (k *Keeper) GetNFT(ctx sdk.Context, id string) (*NFT, error) {
 	store := ctx.KVStore(k.storeKey)
-	if !store.Has([]byte(id)) {
+	if !store.Has(types.GetNFTKey(id)) {
 		return nil, fmt.Errorf("could not find NFT with id %s", id)
 	}
 
-	bz := store.Get([]byte(id))
+	bz := store.Get(types.GetNFTKey(id))
 	var token NFT
 	k.cdc.MustUnmarshalJSON(bz, &token)
 
@@ -107,29 +106,116 @@ func (k *Keeper) GetNFT(ctx sdk.Context, id string) (*NFT, error) {
 func (k *Keeper) MintNFT(ctx sdk.Context, nft *NFT) error {
 	id := nft.ID
 	store := ctx.KVStore(k.storeKey)
-	if store.Has([]byte(id)) {
+	if store.Has(types.GetNFTKey(id)) {
 		return fmt.Errorf("nft with ID %s already exists", id)
 	}
 
 	bz := k.cdc.MustMarshalJSON(nft)
-	store.Set([]byte(id), bz)
+	store.Set(types.GetNFTKey(id), bz)
+	k.setNFTIndexes(ctx, nft)
 	return nil
 }
 
 func (k *Keeper) BurnNFT(ctx sdk.Context, id string) error {
-	store := ctx.KVStore(k.storeKey)
-	if !store.Has([]byte(id)) {
-		return fmt.Errorf("could not find NFT with id %s", id)
+	token, err := k.GetNFT(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	store.Delete([]byte(id))
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetNFTKey(id))
+	k.deleteNFTIndexes(ctx, token)
 	return nil
 }
 
+// setNFTIndexes adds the NFT to the owner, status and denom indexes.
+func (k *Keeper) setNFTIndexes(ctx sdk.Context, nft *NFT) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetNFTOwnerIndexKey(nft.Owner, nft.ID), []byte(nft.ID))
+	store.Set(types.GetNFTStatusIndexKey(nft.Status, nft.ID), []byte(nft.ID))
+	store.Set(types.GetNFTDenomIndexKey(nft.Denom, nft.ID), []byte(nft.ID))
+}
+
+// deleteNFTIndexes removes the NFT from the owner, status and denom indexes.
+func (k *Keeper) deleteNFTIndexes(ctx sdk.Context, nft *NFT) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetNFTOwnerIndexKey(nft.Owner, nft.ID))
+	store.Delete(types.GetNFTStatusIndexKey(nft.Status, nft.ID))
+	store.Delete(types.GetNFTDenomIndexKey(nft.Denom, nft.ID))
+}
+
 // Get an iterator over all NFTs.
 func (k *Keeper) GetNFTsIterator(ctx sdk.Context) sdk.Iterator {
 	store := ctx.KVStore(k.storeKey)
-	return sdk.KVStorePrefixIterator(store, nil)
+	return sdk.KVStorePrefixIterator(store, types.NFTPrefix)
+}
+
+// getIndexedNFTs returns the NFTs listed in the index under the prefix, ordered by ID.
+func (k *Keeper) getIndexedNFTs(ctx sdk.Context, prefix []byte) []*NFT {
+	var nfts []*NFT
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), prefix)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		token, err := k.GetNFT(ctx, string(iterator.Value()))
+		if err != nil {
+			panic(fmt.Sprintf("NFT index refers to a missing NFT: %v", err))
+		}
+		nfts = append(nfts, token)
+	}
+	return nfts
+}
+
+// GetNFTsByOwner returns all NFTs of the owner, ordered by ID.
+func (k *Keeper) GetNFTsByOwner(ctx sdk.Context, owner sdk.AccAddress) []*NFT {
+	return k.getIndexedNFTs(ctx, types.GetNFTOwnerIndexPrefix(owner))
+}
+
+// GetNFTsByStatus returns all NFTs with the status, ordered by ID.
+func (k *Keeper) GetNFTsByStatus(ctx sdk.Context, status types.NFTStatus) []*NFT {
+	return k.getIndexedNFTs(ctx, types.GetNFTStatusIndexPrefix(status))
+}
+
+// GetNFTsOnMarket returns all NFTs put on the market, ordered by ID.
+func (k *Keeper) GetNFTsOnMarket(ctx sdk.Context) []*NFT {
+	return k.GetNFTsByStatus(ctx, types.NFTStatusOnMarket)
+}
+
+// GetNFTsByDenom returns all NFTs of the denom, ordered by ID.
+func (k *Keeper) GetNFTsByDenom(ctx sdk.Context, denom string) []*NFT {
+	return k.getIndexedNFTs(ctx, types.GetNFTDenomIndexPrefix(denom))
+}
+
+// GetFilteredNFTs returns the requested page of the NFTs matching the params and the number of matching NFTs.
+// The candidates are taken from an index when the params filter by owner, status or denom.
+func (k *Keeper) GetFilteredNFTs(ctx sdk.Context, params types.QueryNFTsParams) ([]*NFT, int) {
+	var candidates []*NFT
+	status, byStatus := params.GetStatus()
+	switch {
+	case !params.Owner.Empty():
+		candidates = k.GetNFTsByOwner(ctx, params.Owner)
+	case byStatus:
+		candidates = k.GetNFTsByStatus(ctx, status)
+	case params.Denom != "":
+		candidates = k.GetNFTsByDenom(ctx, params.Denom)
+	default:
+		iterator := k.GetNFTsIterator(ctx)
+		for ; iterator.Valid(); iterator.Next() {
+			var token NFT
+			k.cdc.MustUnmarshalJSON(iterator.Value(), &token)
+			candidates = append(candidates, &token)
+		}
+		iterator.Close()
+	}
+
+	var matching []*NFT
+	for _, token := range candidates {
+		if params.Matches(token) {
+			matching = append(matching, token)
+		}
+	}
+
+	params.Sort(matching)
+	return params.Paginate(matching), len(matching)
 }
 
 // Get an iterator over all registered currencies
@@ -151,6 +237,9 @@ func (k *Keeper) PutNFTOnMarket(ctx sdk.Context, id string, owner, beneficiary s
 	if token.IsOnSale() {
 		return fmt.Errorf("NFT #%s is alredy on sale", id)
 	}
+	if token.IsLocked() {
+		return fmt.Errorf("NFT #%s is %s", id, token.Status)
+	}
 	token.SetPrice(price)
 	token.SetStatus(types.NFTStatusOnMarket)
 	token.SetSellerBeneficiary(beneficiary)
@@ -179,13 +268,21 @@ func (k *Keeper) RemoveNFTFromMarket(ctx sdk.Context, id string, owner sdk.AccAd
 }
 
 func (k *Keeper) UpdateNFT(ctx sdk.Context, newToken *NFT) error {
-	store := ctx.KVStore(k.storeKey)
-	if !store.Has([]byte(newToken.ID)) {
-		return fmt.Errorf("could not find NFT with id %s", newToken.ID)
+	oldToken, err := k.GetNFT(ctx, newToken.ID)
+	if err != nil {
+		return err
 	}
 
+	store := ctx.KVStore(k.storeKey)
 	bz := k.cdc.MustMarshalJSON(newToken)
-	store.Set([]byte(newToken.ID), bz)
+	store.Set(types.GetNFTKey(newToken.ID), bz)
+	k.deleteNFTIndexes(ctx, oldToken)
+	k.setNFTIndexes(ctx, newToken)
+
+	// a bundle has no base token, its items follow its owner instead
+	if newToken.IsBundle() {
+		return k.updateBundleItemsOwner(ctx, newToken)
+	}
 
 	newBaseToken, err := k.nftKeeper.GetNFT(ctx, newToken.Denom, newToken.ID)
 	if err != nil {
@@ -214,10 +311,24 @@ func (k *Keeper) IsDenomExist(ctx sdk.Context, coins sdk.Coins) bool {
 	return true
 }
 
-// Creates a new fungible token with given supply and denom for FungibleTokenCreationPrice
-func (k *Keeper) CreateFungibleToken(ctx sdk.Context, creator sdk.AccAddress, denom string, amount int64) error {
-	logger := ctx.Logger()
+// LockCoins moves coins of a bid or an offer from the account to the marketplace module account.
+func (k *Keeper) LockCoins(ctx sdk.Context, addr sdk.AccAddress, coins sdk.Coins) error {
+	if err := k.supplyKeeper.SendCoinsFromAccountToModule(ctx, addr, types.ModuleName, coins); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnlockCoins returns coins of a bid or an offer from the marketplace module account.
+func (k *Keeper) UnlockCoins(ctx sdk.Context, addr sdk.AccAddress, coins sdk.Coins) error {
+	if err := k.supplyKeeper.SendCoinsFromModuleToAccount(ctx, types.ModuleName, addr, coins); err != nil {
+		return err
+	}
+	return nil
+}
 
+// Creates a new fungible token with given supply and denom for the FungibleTokenCreationPrice param
+func (k *Keeper) CreateFungibleToken(ctx sdk.Context, creator sdk.AccAddress, denom string, amount int64) error {
 	store := ctx.KVStore(k.currencyRegistryStoreKey)
 	if store.Has([]byte(denom)) {
 		return fmt.Errorf("currency already exists")
@@ -228,29 +339,25 @@ func (k *Keeper) CreateFungibleToken(ctx sdk.Context, creator sdk.AccAddress, de
 		return fmt.Errorf("failed to get comissionAddress: %v", err)
 	}
 
-	initialBalances := GetBalances(ctx, k, creator, commissionAddress)
-
-	if err := k.coinKeeper.SendCoins(ctx, creator, commissionAddress,
-		sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(FungibleTokenCreationPrice)))); err != nil {
-		RollbackCommissions(ctx, k, logger, initialBalances)
-		return fmt.Errorf("failed to send coins to comissionAddress")
-	}
-	mintedCoins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(amount)))
-	sdkErr := k.supplyKeeper.MintCoins(ctx, bank.ModuleName, mintedCoins)
-	if sdkErr != nil {
-		RollbackCommissions(ctx, k, logger, initialBalances)
-		return fmt.Errorf("failed to mint fungible tokens: %v", sdkErr.Error())
-	}
+	return runAtomically(ctx, func(ctx sdk.Context) error {
+		if err := k.coinKeeper.SendCoins(ctx, creator, commissionAddress, k.GetParams(ctx).FungibleTokenCreationPrice); err != nil {
+			return fmt.Errorf("failed to send coins to comissionAddress")
+		}
+		mintedCoins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(amount)))
+		sdkErr := k.supplyKeeper.MintCoins(ctx, bank.ModuleName, mintedCoins)
+		if sdkErr != nil {
+			return fmt.Errorf("failed to mint fungible tokens: %v", sdkErr.Error())
+		}
 
-	sdkErr = k.supplyKeeper.SendCoinsFromModuleToAccount(ctx, bank.ModuleName, creator, mintedCoins)
-	if sdkErr != nil {
-		RollbackCommissions(ctx, k, logger, initialBalances)
-		return fmt.Errorf("failed to add coins: %v", sdkErr.Error())
-	}
+		sdkErr = k.supplyKeeper.SendCoinsFromModuleToAccount(ctx, bank.ModuleName, creator, mintedCoins)
+		if sdkErr != nil {
+			return fmt.Errorf("failed to add coins: %v", sdkErr.Error())
+		}
 
-	k.registerFungibleTokensCurrency(ctx, FungibleToken{Creator: creator, Denom: denom, EmissionAmount: amount})
+		k.registerFungibleTokensCurrency(ctx, FungibleToken{Creator: creator, Denom: denom, EmissionAmount: amount})
 
-	return nil
+		return nil
+	})
 }
 
 // Should be run just once
@@ -330,6 +437,9 @@ func (k *Keeper) TransferNFT(ctx sdk.Context, id string, sender, recipient sdk.A
 	if token.IsOnSale() {
 		return fmt.Errorf("failed to transferNFT: NFT is on sale")
 	}
+	if token.IsLocked() {
+		return fmt.Errorf("failed to transferNFT: NFT is %s", token.Status)
+	}
 
 	if !token.Owner.Equals(sender) {
 		return fmt.Errorf("%s is not the owner of NFT #%s", sender.String(), id)
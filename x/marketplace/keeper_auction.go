@@ -2,7 +2,6 @@ package marketplace
 
 import (
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/corestario/marketplace/x/marketplace/types"
@@ -11,7 +10,12 @@ import (
 
 func (k *Keeper) PutNFTOnAuction(ctx sdk.Context, id string, owner, beneficiary sdk.AccAddress,
 	openingPrice, buyoutPrice sdk.Coins, expirationTime time.Time) error {
+	return k.putNFTOnAuction(ctx, owner, beneficiary, types.NewAuctionLot(id, openingPrice, buyoutPrice, expirationTime))
+}
 
+// putNFTOnAuction puts the NFT on auction as the given lot, which starts at the current block time.
+func (k *Keeper) putNFTOnAuction(ctx sdk.Context, owner, beneficiary sdk.AccAddress, lot *types.AuctionLot) error {
+	id := lot.NFTID
 	token, err := k.GetNFT(ctx, id)
 
 	if err != nil {
@@ -25,9 +29,14 @@ func (k *Keeper) PutNFTOnAuction(ctx sdk.Context, id string, owner, beneficiary
 	if token.IsOnSale() {
 		return fmt.Errorf("NFT #%s is alredy on sale", id)
 	}
+	if token.IsLocked() {
+		return fmt.Errorf("NFT #%s is %s", id, token.Status)
+	}
 	token.SetStatus(types.NFTStatusOnAuction)
 	token.SetSellerBeneficiary(beneficiary)
-	lot := types.NewAuctionLot(id, openingPrice, buyoutPrice, expirationTime)
+	lot.StartTime = ctx.BlockHeader().Time
+	// the bid history of the previous auction of the NFT is kept under its own number until pruned
+	lot.Auction = k.nextAuction(ctx, id)
 	err = k.createAuctionLot(ctx, lot)
 	if err != nil {
 		return fmt.Errorf("failed to create auction lot: %v", err)
@@ -37,11 +46,13 @@ func (k *Keeper) PutNFTOnAuction(ctx sdk.Context, id string, owner, beneficiary
 
 func (k *Keeper) createAuctionLot(ctx sdk.Context, lot *types.AuctionLot) error {
 	store := ctx.KVStore(k.auctionStoreKey)
-	if store.Has([]byte(lot.NFTID)) {
+	key := types.GetAuctionLotKey(lot.NFTID)
+	if store.Has(key) {
 		return fmt.Errorf("lot already exists")
 	}
 	bz := k.cdc.MustMarshalJSON(lot)
-	store.Set([]byte(lot.NFTID), bz)
+	store.Set(key, bz)
+	store.Set(types.GetAuctionExpiryQueueKey(lot.SettlementTime(), lot.NFTID), []byte(lot.NFTID))
 	return nil
 }
 
@@ -76,31 +87,107 @@ func (k *Keeper) removeNFTFromAuction(ctx sdk.Context, nft *NFT) error {
 }
 
 func (k *Keeper) deleteAuctionLot(ctx sdk.Context, id string) error {
-	store := ctx.KVStore(k.auctionStoreKey)
-	if !store.Has([]byte(id)) {
-		return fmt.Errorf("lot does not exist")
+	lot, err := k.GetAuctionLot(ctx, id)
+	if err != nil {
+		return err
 	}
-	store.Delete([]byte(id))
+	store := ctx.KVStore(k.auctionStoreKey)
+	store.Delete(types.GetAuctionLotKey(id))
+	store.Delete(types.GetAuctionExpiryQueueKey(lot.SettlementTime(), id))
+	store.Delete(types.GetReservePriceKey(id))
+	store.Delete(types.GetProxyMaxBidKey(id))
+	k.scheduleBidHistoryPruning(ctx, lot)
 	return nil
 }
 
 func (k *Keeper) UpdateAuctionLot(ctx sdk.Context, lot *types.AuctionLot) error {
-	store := ctx.KVStore(k.auctionStoreKey)
-	if !store.Has([]byte(lot.NFTID)) {
+	oldLot, err := k.GetAuctionLot(ctx, lot.NFTID)
+	if err != nil {
 		return fmt.Errorf("could not find lot with id %s", lot.NFTID)
 	}
 
+	store := ctx.KVStore(k.auctionStoreKey)
+	if !oldLot.SettlementTime().Equal(lot.SettlementTime()) {
+		store.Delete(types.GetAuctionExpiryQueueKey(oldLot.SettlementTime(), lot.NFTID))
+		store.Set(types.GetAuctionExpiryQueueKey(lot.SettlementTime(), lot.NFTID), []byte(lot.NFTID))
+	}
+
 	bz := k.cdc.MustMarshalJSON(lot)
-	store.Set([]byte(lot.NFTID), bz)
+	store.Set(types.GetAuctionLotKey(lot.NFTID), bz)
 	return nil
 }
 
+// setReservePrice sets the revealed reserve price of the lot of the given NFT. Until the owner reveals it,
+// only its hash is known, kept in the lot. The lot is checked against it at settlement only.
+func (k *Keeper) setReservePrice(ctx sdk.Context, id string, reserve sdk.Coins) {
+	ctx.KVStore(k.auctionStoreKey).Set(types.GetReservePriceKey(id), k.cdc.MustMarshalJSON(reserve))
+}
+
+// getReservePrice returns the revealed reserve price of the lot of the given NFT,
+// empty if there is none or it has not been revealed.
+func (k *Keeper) getReservePrice(ctx sdk.Context, id string) sdk.Coins {
+	bz := ctx.KVStore(k.auctionStoreKey).Get(types.GetReservePriceKey(id))
+	if bz == nil {
+		return sdk.Coins{}
+	}
+	var reserve sdk.Coins
+	k.cdc.MustUnmarshalJSON(bz, &reserve)
+	return reserve
+}
+
+// setProxyMaxBid sets the maximum bid of the leading proxy bidder of the lot of the given NFT.
+// It is stored apart from the lot so that queries of the lot do not reveal it.
+func (k *Keeper) setProxyMaxBid(ctx sdk.Context, id string, maxBid sdk.Coins) {
+	ctx.KVStore(k.auctionStoreKey).Set(types.GetProxyMaxBidKey(id), k.cdc.MustMarshalJSON(maxBid))
+}
+
+// getProxyMaxBid returns the maximum bid of the leading proxy bidder of the lot of the given NFT,
+// empty if the last bid is not a proxy bid.
+func (k *Keeper) getProxyMaxBid(ctx sdk.Context, id string) sdk.Coins {
+	bz := ctx.KVStore(k.auctionStoreKey).Get(types.GetProxyMaxBidKey(id))
+	if bz == nil {
+		return sdk.Coins{}
+	}
+	var maxBid sdk.Coins
+	k.cdc.MustUnmarshalJSON(bz, &maxBid)
+	return maxBid
+}
+
+func (k *Keeper) deleteProxyMaxBid(ctx sdk.Context, id string) {
+	ctx.KVStore(k.auctionStoreKey).Delete(types.GetProxyMaxBidKey(id))
+}
+
+// proxyBid returns the visible bid of a proxy bid with the given maximum that takes the lead on the lot:
+// the lowest bid over the last bid, or over the maximum of the outbid proxy bid. The leading bidder raising
+// its maximum keeps its visible bid. The reserve price is not known yet, the maximum is checked against it
+// at settlement.
+func (k *Keeper) proxyBid(lot *types.AuctionLot, bidder sdk.AccAddress, leaderMaxBid, maxBid sdk.Coins) sdk.Coins {
+	bid := lot.MinNextBid()
+	switch {
+	case lot.LastBid != nil && lot.LastBid.Bidder.Equals(bidder):
+		bid = lot.LastBid.Bid
+	case !leaderMaxBid.Empty():
+		bid = minCoins(lot.MinBidOver(leaderMaxBid), maxBid)
+	}
+	return bid
+}
+
+// lockedBid returns the coins locked for the last bid on the lot: the maximum bid of a proxy bid,
+// otherwise the bid itself.
+func (k *Keeper) lockedBid(ctx sdk.Context, lot *types.AuctionLot) sdk.Coins {
+	if maxBid := k.getProxyMaxBid(ctx, lot.NFTID); !maxBid.Empty() {
+		return maxBid
+	}
+	return lot.LastBid.Bid
+}
+
 func (k *Keeper) GetAuctionLot(ctx sdk.Context, id string) (*types.AuctionLot, error) {
 	store := ctx.KVStore(k.auctionStoreKey)
-	if !store.Has([]byte(id)) {
+	key := types.GetAuctionLotKey(id)
+	if !store.Has(key) {
 		return nil, fmt.Errorf("lot does not exist")
 	}
-	bz := store.Get([]byte(id))
+	bz := store.Get(key)
 	var lot types.AuctionLot
 	k.cdc.MustUnmarshalJSON(bz, &lot)
 	return &lot, nil
@@ -108,13 +195,19 @@ func (k *Keeper) GetAuctionLot(ctx sdk.Context, id string) (*types.AuctionLot, e
 
 func (k *Keeper) GetAuctionLotsIterator(ctx sdk.Context) sdk.Iterator {
 	store := ctx.KVStore(k.auctionStoreKey)
-	return sdk.KVStorePrefixIterator(store, nil)
+	return sdk.KVStorePrefixIterator(store, types.AuctionLotPrefix)
+}
+
+// GetExpiredAuctionLotsIterator returns an iterator over the expiry queue entries of the lots
+// due for settlement strictly before endTime, ordered by settlement time. Values are NFT IDs.
+func (k *Keeper) GetExpiredAuctionLotsIterator(ctx sdk.Context, endTime time.Time) sdk.Iterator {
+	store := ctx.KVStore(k.auctionStoreKey)
+	return store.Iterator(types.AuctionExpiryQueuePrefix, types.GetAuctionExpiryQueueTimeKey(endTime))
 }
 
 // buyout the lot
 func (k *Keeper) BuyLotOnAuction(ctx sdk.Context, buyer, buyerBeneficiary sdk.AccAddress,
-	price sdk.Coins, lot *types.AuctionLot, buyerCommission string) error {
-	logger := ctx.Logger()
+	price sdk.Coins, lot *types.AuctionLot, buyerCommission sdk.Dec) error {
 	nft, err := k.GetNFT(ctx, lot.NFTID)
 	if err != nil {
 		return err
@@ -123,84 +216,173 @@ func (k *Keeper) BuyLotOnAuction(ctx sdk.Context, buyer, buyerBeneficiary sdk.Ac
 		return fmt.Errorf("nft is not on auction")
 	}
 
-	commission := types.DefaultBeneficiariesCommission
-	parsed, err := strconv.ParseFloat(buyerCommission, 64)
-	if err == nil {
-		commission = parsed
-	}
-	balances := GetBalances(ctx, k, buyer, buyerBeneficiary, nft.SellerBeneficiary, nft.Owner)
-	if lot.LastBid != nil {
-		balances = append(balances,
-			GetBalances(ctx, k, lot.LastBid.Bidder, lot.LastBid.BuyerBeneficiary)...)
-		_, err = k.coinKeeper.AddCoins(ctx, lot.LastBid.Bidder, lot.LastBid.Bid)
+	return runAtomically(ctx, func(ctx sdk.Context) error {
+		// the part of a proxy bid above the price is refunded here as well
+		if lot.LastBid != nil {
+			if err := k.UnlockCoins(ctx, lot.LastBid.Bidder, k.lockedBid(ctx, lot)); err != nil {
+				return err
+			}
+		}
+
+		// similar to buyNFTOnMarket
+		priceAfterCommission, err := doNFTCommissions(
+			ctx,
+			k,
+			nft,
+			buyer,
+			nft.SellerBeneficiary,
+			buyerBeneficiary,
+			price,
+			buyerCommission,
+		)
 		if err != nil {
-			RollbackCommissions(ctx, k, logger, balances)
 			return err
 		}
+
+		if err := k.coinKeeper.SendCoins(ctx, buyer, nft.Owner, priceAfterCommission); err != nil {
+			return fmt.Errorf("buyer does not have enough coins")
+		}
+
+		if err := k.deleteAuctionLot(ctx, lot.NFTID); err != nil {
+			return err
+		}
+
+		// transfer nfr to new owner
+		nft.SetSellerBeneficiary(sdk.AccAddress{})
+		nft.Owner = buyer
+		nft.SetStatus(types.NFTStatusDefault)
+
+		return k.UpdateNFT(ctx, nft)
+	})
+}
+
+// FinishAuction settles the lot: the NFT goes to the last bidder if there is one,
+// otherwise it is returned to its owner. Returns the resulting owner of the NFT.
+func (k *Keeper) FinishAuction(ctx sdk.Context, lot *types.AuctionLot) (sdk.AccAddress, error) {
+	if lot.IsSealed() {
+		return k.settleSealedAuction(ctx, lot)
 	}
 
-	// similar to buyNFTOnMarket
-	priceAfterCommission, err := doNFTCommissions(
-		ctx,
-		k,
-		buyer,
-		nft.Owner,
-		nft.SellerBeneficiary,
-		buyerBeneficiary,
-		price,
-		commission,
-	)
+	nft, err := k.GetNFT(ctx, lot.NFTID)
 	if err != nil {
-		RollbackCommissions(ctx, k, logger, balances)
-		return err
+		return nil, err
 	}
 
-	err = k.coinKeeper.SendCoins(ctx, buyer, nft.Owner, priceAfterCommission)
-	if err != nil {
-		RollbackCommissions(ctx, k, logger, balances)
-		return fmt.Errorf("buyer does not have enough coins")
+	// no bids on lot
+	if lot.LastBid == nil {
+		if err := k.removeNFTFromAuction(ctx, nft); err != nil {
+			return nil, err
+		}
+		return nft.Owner, nil
 	}
 
-	err = k.deleteAuctionLot(ctx, lot.NFTID)
-	if err != nil {
-		RollbackCommissions(ctx, k, logger, balances)
-		return err
+	// the highest bid, up to the maximum of a proxy bid, is below the revealed reserve price,
+	// return the bid and the NFT, a reserve price that has not been revealed counts as met
+	reserve := k.getReservePrice(ctx, lot.NFTID)
+	if !reserve.Empty() && !k.lockedBid(ctx, lot).IsAllGTE(reserve) {
+		if err := k.UnlockCoins(ctx, lot.LastBid.Bidder, k.lockedBid(ctx, lot)); err != nil {
+			return nil, err
+		}
+		if err := k.removeNFTFromAuction(ctx, nft); err != nil {
+			return nil, err
+		}
+		return nft.Owner, nil
 	}
 
-	// transfer nfr to new owner
-	nft.SetSellerBeneficiary(sdk.AccAddress{})
-	nft.Owner = buyer
-	nft.SetStatus(types.NFTStatusDefault)
+	// a proxy bid that meets the reserve price pays at least the reserve price
+	price := lot.LastBid.Bid
+	if !reserve.Empty() && !price.IsAllGTE(reserve) {
+		price = reserve
+	}
+	if err := k.BuyLotOnAuction(ctx, lot.LastBid.Bidder, lot.LastBid.BuyerBeneficiary,
+		price, lot, lot.LastBid.BeneficiaryCommission); err != nil {
+		return nil, err
+	}
+	return lot.LastBid.Bidder, nil
+}
 
-	if err := k.UpdateNFT(ctx, nft); err != nil {
-		RollbackCommissions(ctx, k, logger, balances)
-		return err
+// refundAuctionBids returns the coins locked in the bids on the lot to the bidders.
+func (k *Keeper) refundAuctionBids(ctx sdk.Context, lot *types.AuctionLot) error {
+	if lot.LastBid != nil {
+		if err := k.UnlockCoins(ctx, lot.LastBid.Bidder, k.lockedBid(ctx, lot)); err != nil {
+			return err
+		}
+	}
+	for _, bid := range k.GetSealedBids(ctx, lot.NFTID) {
+		if err := k.UnlockCoins(ctx, bid.Bidder, bid.Deposit.Add(bid.Bid)); err != nil {
+			return err
+		}
+		k.deleteSealedBid(ctx, bid)
 	}
 	return nil
 }
 
+// cancelAuction refunds the bids on the lot that failed to settle with the given error and returns the NFT
+// to its owner. If that fails as well, the lot is only taken out of the expiry queue, it keeps its bids
+// and stays on auction until its owner removes it.
+func (k *Keeper) cancelAuction(ctx sdk.Context, lot *types.AuctionLot, settleErr error) {
+	err := runAtomically(ctx, func(ctx sdk.Context) error {
+		if err := k.refundAuctionBids(ctx, lot); err != nil {
+			return err
+		}
+		nft, err := k.GetNFT(ctx, lot.NFTID)
+		if err != nil {
+			return err
+		}
+		return k.removeNFTFromAuction(ctx, nft)
+	})
+	if err != nil {
+		ctx.Logger().Error("failed to cancel auction", "lot", lot.NFTID, "error", err)
+		ctx.KVStore(k.auctionStoreKey).Delete(types.GetAuctionExpiryQueueKey(lot.SettlementTime(), lot.NFTID))
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeFailAuctionSettling,
+		sdk.NewAttribute(types.AttributeKeyNFTID, lot.NFTID),
+		sdk.NewAttribute(types.AttributeKeyError, settleErr.Error()),
+	))
+}
+
+// CheckFinishedAuctions settles all lots that are due for settlement by the current block time.
 func (k *Keeper) CheckFinishedAuctions(ctx sdk.Context) {
-	// TODO: is error handler necessary here?
 	logger := ctx.Logger()
-	iterator := k.GetAuctionLotsIterator(ctx)
-	timeNow := time.Now().UTC()
-	defer iterator.Close()
+	blockTime := ctx.BlockHeader().Time
+
+	// lots are collected first because settling a lot deletes it from the queue being iterated
+	var expired []*types.AuctionLot
+	iterator := k.GetExpiredAuctionLotsIterator(ctx, blockTime)
 	for ; iterator.Valid(); iterator.Next() {
-		var lot types.AuctionLot
-		k.cdc.MustUnmarshalJSON(iterator.Value(), &lot)
-		if lot.ExpirationTime.Before(timeNow) {
-			// there was at least one bid
-			addr, err := sdk.AccAddressFromBech32(k.config.FinishingAccountAddr)
-			if err != nil {
-				logger.Error("failed to get acc address from bench32", "bench", k.config.FinishingAccountAddr, "error", err)
-				continue
-			}
+		lot, err := k.GetAuctionLot(ctx, string(iterator.Value()))
+		if err != nil {
+			logger.Error("expiry queue refers to a missing lot", "lot", string(iterator.Value()), "error", err)
+			continue
+		}
+		expired = append(expired, lot)
+	}
+	iterator.Close()
 
-			acc := k.accKeeper.GetAccount(ctx, addr)
-			if err := k.SendFinish(lot.NFTID, acc); err != nil {
-				logger.Error("failed to sent finish tx", "lot", lot.NFTID, "error", err)
-				continue
-			}
+	for _, lot := range expired {
+		// settle every lot in its own cache so that a failed lot leaves no partial state behind
+		cacheCtx, writeCache := ctx.CacheContext()
+		owner, err := k.FinishAuction(cacheCtx, lot)
+		if err != nil {
+			logger.Error("failed to finish auction", "lot", lot.NFTID, "error", err)
+			k.cancelAuction(ctx, lot, err)
+			continue
 		}
+		writeCache()
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+
+		ctx.EventManager().EmitEvents(sdk.Events{
+			sdk.NewEvent(
+				types.MsgFinishAuction{}.Type(),
+				sdk.NewAttribute(types.AttributeKeyOwner, owner.String()),
+				sdk.NewAttribute(types.AttributeKeyNFTID, lot.NFTID),
+			),
+			sdk.NewEvent(
+				sdk.EventTypeMessage,
+				sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			),
+		})
 	}
 }
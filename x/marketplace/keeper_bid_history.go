@@ -0,0 +1,119 @@
+package marketplace
+
+import (
+	"encoding/binary"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// appendBidHistory appends the bid to the bid history of the auction of the lot.
+func (k *Keeper) appendBidHistory(ctx sdk.Context, lot *types.AuctionLot, bid *types.AuctionBid) {
+	store := ctx.KVStore(k.auctionStoreKey)
+
+	var index uint64
+	iterator := sdk.KVStoreReversePrefixIterator(store, types.GetBidHistoryPrefix(lot.NFTID, lot.Auction))
+	if iterator.Valid() {
+		key := iterator.Key()
+		index = binary.BigEndian.Uint64(key[len(key)-8:]) + 1
+	}
+	iterator.Close()
+
+	store.Set(types.GetBidHistoryKey(lot.NFTID, lot.Auction, index), k.cdc.MustMarshalJSON(bid))
+}
+
+// setBidHistory sets the bid history of the auction of the NFT with the given number.
+func (k *Keeper) setBidHistory(ctx sdk.Context, id string, auction uint64, bids []*types.AuctionBid) {
+	store := ctx.KVStore(k.auctionStoreKey)
+	for index, bid := range bids {
+		store.Set(types.GetBidHistoryKey(id, auction, uint64(index)), k.cdc.MustMarshalJSON(bid))
+	}
+}
+
+// GetBidHistory returns the bids on the auction of the NFT with the given number in the order they were made.
+// The history of a finished auction is kept until it is pruned.
+func (k *Keeper) GetBidHistory(ctx sdk.Context, id string, auction uint64) []*types.AuctionBid {
+	bids := []*types.AuctionBid{}
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.auctionStoreKey), types.GetBidHistoryPrefix(id, auction))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var bid types.AuctionBid
+		k.cdc.MustUnmarshalJSON(iterator.Value(), &bid)
+		bids = append(bids, &bid)
+	}
+	return bids
+}
+
+// GetLatestAuction returns the number of the current or the last finished auction of the NFT,
+// 0 if the NFT has never been put on auction.
+func (k *Keeper) GetLatestAuction(ctx sdk.Context, id string) uint64 {
+	bz := ctx.KVStore(k.auctionStoreKey).Get(types.GetLatestAuctionKey(id))
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (k *Keeper) setLatestAuction(ctx sdk.Context, id string, auction uint64) {
+	ctx.KVStore(k.auctionStoreKey).Set(types.GetLatestAuctionKey(id), sdk.Uint64ToBigEndian(auction))
+}
+
+// nextAuction returns the number of a new auction of the NFT and stores it as the latest one.
+func (k *Keeper) nextAuction(ctx sdk.Context, id string) uint64 {
+	auction := k.GetLatestAuction(ctx, id) + 1
+	k.setLatestAuction(ctx, id, auction)
+	return auction
+}
+
+// scheduleBidHistoryPruning schedules the bid history of the finished auction of the lot to be pruned
+// in BidHistoryRetention blocks.
+func (k *Keeper) scheduleBidHistoryPruning(ctx sdk.Context, lot *types.AuctionLot) {
+	height := ctx.BlockHeight() + int64(k.GetParams(ctx).BidHistoryRetention)
+	k.setBidHistoryPruning(ctx, lot.NFTID, lot.Auction, height)
+}
+
+// setBidHistoryPruning schedules the bid history of the auction of the NFT with the given number
+// to be pruned at the height.
+func (k *Keeper) setBidHistoryPruning(ctx sdk.Context, id string, auction uint64, height int64) {
+	ctx.KVStore(k.auctionStoreKey).Set(types.GetBidHistoryPruneQueueKey(height, id, auction),
+		types.GetBidHistoryPrefix(id, auction))
+}
+
+func (k *Keeper) GetBidHistoriesIterator(ctx sdk.Context) sdk.Iterator {
+	return sdk.KVStorePrefixIterator(ctx.KVStore(k.auctionStoreKey), types.BidHistoryPrefix)
+}
+
+func (k *Keeper) GetBidHistoryPruneQueueIterator(ctx sdk.Context) sdk.Iterator {
+	return sdk.KVStorePrefixIterator(ctx.KVStore(k.auctionStoreKey), types.BidHistoryPruneQueuePrefix)
+}
+
+func (k *Keeper) GetLatestAuctionsIterator(ctx sdk.Context) sdk.Iterator {
+	return sdk.KVStorePrefixIterator(ctx.KVStore(k.auctionStoreKey), types.LatestAuctionPrefix)
+}
+
+// PruneBidHistories deletes the bid histories of the auctions that finished
+// BidHistoryRetention blocks ago or earlier.
+func (k *Keeper) PruneBidHistories(ctx sdk.Context) {
+	store := ctx.KVStore(k.auctionStoreKey)
+
+	// keys are collected first because deleting while iterating is not supported
+	var keys, prefixes [][]byte
+	iterator := store.Iterator(types.BidHistoryPruneQueuePrefix,
+		sdk.PrefixEndBytes(types.GetBidHistoryPruneQueueHeightKey(ctx.BlockHeight())))
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+		prefixes = append(prefixes, iterator.Value())
+	}
+	iterator.Close()
+
+	for _, prefix := range prefixes {
+		iterator := sdk.KVStorePrefixIterator(store, prefix)
+		for ; iterator.Valid(); iterator.Next() {
+			keys = append(keys, iterator.Key())
+		}
+		iterator.Close()
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
@@ -0,0 +1,108 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// CreateBundle bundles the NFTs of the owner into a new NFT with the given ID. The NFTs cannot be
+// traded on their own until the bundle is removed, they follow the owner of the bundle.
+func (k *Keeper) CreateBundle(ctx sdk.Context, owner sdk.AccAddress, id string, tokenIDs []string) error {
+	if _, err := k.GetNFT(ctx, id); err == nil {
+		return fmt.Errorf("NFT #%s already exists", id)
+	}
+	if ctx.KVStore(k.deletedStoreKey).Has([]byte(id)) {
+		return fmt.Errorf("NFT #%s has been deleted", id)
+	}
+
+	for _, tokenID := range tokenIDs {
+		token, err := k.GetNFT(ctx, tokenID)
+		if err != nil {
+			return fmt.Errorf("failed to GetNFT: %v", err)
+		}
+		if !token.Owner.Equals(owner) {
+			return fmt.Errorf("%s is not the owner of NFT #%s", owner.String(), tokenID)
+		}
+		if token.IsOnSale() || token.IsLocked() {
+			return fmt.Errorf("NFT #%s is %s", tokenID, token.Status)
+		}
+		if token.IsBundle() {
+			return fmt.Errorf("NFT #%s is a bundle", tokenID)
+		}
+		token.SetStatus(types.NFTStatusInBundle)
+		if err := k.UpdateNFT(ctx, token); err != nil {
+			return err
+		}
+	}
+
+	return k.MintNFT(ctx, types.NewBundle(id, owner, tokenIDs, ctx.BlockHeader().Time))
+}
+
+// RemoveBundle deletes the bundle and releases its NFTs to the owner, offers for the bundle are refunded.
+func (k *Keeper) RemoveBundle(ctx sdk.Context, owner sdk.AccAddress, id string) error {
+	bundle, err := k.GetNFT(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	if !bundle.IsBundle() {
+		return fmt.Errorf("NFT #%s is not a bundle", id)
+	}
+	if !bundle.Owner.Equals(owner) {
+		return fmt.Errorf("%s is not the owner of NFT #%s", owner.String(), id)
+	}
+	if bundle.IsOnSale() || bundle.IsLocked() {
+		return fmt.Errorf("NFT #%s is %s", id, bundle.Status)
+	}
+
+	for _, offer := range k.GetOffers(ctx, id) {
+		if err := k.UnlockCoins(ctx, offer.Buyer, offer.Price); err != nil {
+			return err
+		}
+		k.DeleteOffer(ctx, offer)
+	}
+
+	items, err := k.getBundleItems(ctx, bundle)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.SetStatus(types.NFTStatusDefault)
+		if err := k.UpdateNFT(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return k.BurnNFT(ctx, id)
+}
+
+func (k *Keeper) getBundleItems(ctx sdk.Context, bundle *NFT) ([]*NFT, error) {
+	items := make([]*NFT, 0, len(bundle.Items))
+	for _, id := range bundle.Items {
+		item, err := k.GetNFT(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("bundle #%s: %v", bundle.ID, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// updateBundleItemsOwner hands the items of the bundle over to the owner of the bundle.
+func (k *Keeper) updateBundleItemsOwner(ctx sdk.Context, bundle *NFT) error {
+	items, err := k.getBundleItems(ctx, bundle)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Owner.Equals(bundle.Owner) {
+			continue
+		}
+		item.Owner = bundle.Owner
+		if err := k.UpdateNFT(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetCollectionOffer returns the collection offer with the given ID.
+func (k *Keeper) GetCollectionOffer(ctx sdk.Context, id string) (*types.CollectionOffer, error) {
+	store := ctx.KVStore(k.offerStoreKey)
+	bz := store.Get(types.GetCollectionOfferKey(id))
+	if bz == nil {
+		return nil, fmt.Errorf("no collection offer with ID %s", id)
+	}
+
+	var offer types.CollectionOffer
+	k.cdc.MustUnmarshalJSON(bz, &offer)
+	return &offer, nil
+}
+
+// SetCollectionOffer stores the collection offer and adds it to the denom index.
+func (k *Keeper) SetCollectionOffer(ctx sdk.Context, offer *types.CollectionOffer) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Set(types.GetCollectionOfferKey(offer.ID), k.cdc.MustMarshalJSON(offer))
+	store.Set(types.GetCollectionOfferDenomIndexKey(offer.Denom, offer.ID), []byte(offer.ID))
+}
+
+// DeleteCollectionOffer removes the collection offer from the store and the denom index.
+// The coins locked by the offer are not unlocked.
+func (k *Keeper) DeleteCollectionOffer(ctx sdk.Context, offer *types.CollectionOffer) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Delete(types.GetCollectionOfferKey(offer.ID))
+	store.Delete(types.GetCollectionOfferDenomIndexKey(offer.Denom, offer.ID))
+}
+
+// GetCollectionOffers returns all collection offers for the denom, highest price first.
+func (k *Keeper) GetCollectionOffers(ctx sdk.Context, denom string) []*types.CollectionOffer {
+	var offers []*types.CollectionOffer
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.offerStoreKey), types.GetCollectionOfferDenomIndexPrefix(denom))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		offer, err := k.GetCollectionOffer(ctx, string(iterator.Value()))
+		if err != nil {
+			panic(fmt.Sprintf("collection offer denom index refers to a missing offer: %v", err))
+		}
+		offers = append(offers, offer)
+	}
+	types.SortCollectionOffers(offers)
+	return offers
+}
+
+// GetCollectionOffersIterator returns an iterator over all collection offers.
+func (k *Keeper) GetCollectionOffersIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.offerStoreKey)
+	return sdk.KVStorePrefixIterator(store, types.CollectionOfferPrefix)
+}
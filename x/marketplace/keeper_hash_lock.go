@@ -0,0 +1,119 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetHashLock returns the hash lock of the NFT with the given ID.
+func (k *Keeper) GetHashLock(ctx sdk.Context, id string) (*types.HashLock, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetHashLockKey(id))
+	if bz == nil {
+		return nil, fmt.Errorf("NFT #%s is not hash-locked", id)
+	}
+
+	var lock types.HashLock
+	k.cdc.MustUnmarshalJSON(bz, &lock)
+	return &lock, nil
+}
+
+// SetHashLock stores the hash lock.
+func (k *Keeper) SetHashLock(ctx sdk.Context, lock *types.HashLock) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetHashLockKey(lock.NFTID), k.cdc.MustMarshalJSON(lock))
+}
+
+// DeleteHashLock removes the hash lock of the NFT with the given ID, the status of the NFT is not changed.
+func (k *Keeper) DeleteHashLock(ctx sdk.Context, id string) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetHashLockKey(id))
+}
+
+// GetHashLocksIterator returns an iterator over all hash locks.
+func (k *Keeper) GetHashLocksIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.HashLockPrefix)
+}
+
+// HashLockNFT locks the NFT of the owner until it is claimed by the recipient or reclaimed after the timeout.
+func (k *Keeper) HashLockNFT(ctx sdk.Context, lock *types.HashLock) error {
+	if lock.IsTimedOut(ctx.BlockHeader().Time) {
+		return fmt.Errorf("timeout %v has passed", lock.Timeout)
+	}
+	token, err := k.GetNFT(ctx, lock.NFTID)
+	if err != nil {
+		return fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	if !token.Owner.Equals(lock.Owner) {
+		return fmt.Errorf("%s is not the owner of NFT #%s", lock.Owner.String(), lock.NFTID)
+	}
+	if token.Status != types.NFTStatusDefault {
+		return fmt.Errorf("NFT #%s is %s", lock.NFTID, token.Status)
+	}
+
+	token.SetStatus(types.NFTStatusHashLocked)
+	if err := k.UpdateNFT(ctx, token); err != nil {
+		return err
+	}
+	k.SetHashLock(ctx, lock)
+	return nil
+}
+
+// ClaimHashLockedNFT transfers the hash-locked NFT to the recipient if the preimage matches the hash lock
+// and the lock has not timed out.
+func (k *Keeper) ClaimHashLockedNFT(ctx sdk.Context, id string, recipient sdk.AccAddress, preimage string) (*types.HashLock, error) {
+	lock, err := k.GetHashLock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !lock.Recipient.Equals(recipient) {
+		return nil, fmt.Errorf("%s is not the recipient of NFT #%s", recipient.String(), id)
+	}
+	if lock.IsTimedOut(ctx.BlockHeader().Time) {
+		return nil, fmt.Errorf("hash lock of NFT #%s has timed out", id)
+	}
+	if hash, err := types.PreimageHash(preimage); err != nil || hash != lock.HashLock {
+		return nil, fmt.Errorf("preimage does not match the hash lock of NFT #%s", id)
+	}
+
+	token, err := k.GetNFT(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	token.Owner = recipient
+	token.SetStatus(types.NFTStatusDefault)
+	token.SetSellerBeneficiary(sdk.AccAddress{})
+	if err := k.UpdateNFT(ctx, token); err != nil {
+		return nil, err
+	}
+	k.DeleteHashLock(ctx, id)
+	return lock, nil
+}
+
+// ReclaimHashLockedNFT unlocks the hash-locked NFT of the owner once the lock has timed out.
+func (k *Keeper) ReclaimHashLockedNFT(ctx sdk.Context, id string, owner sdk.AccAddress) error {
+	lock, err := k.GetHashLock(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !lock.Owner.Equals(owner) {
+		return fmt.Errorf("%s is not the owner of NFT #%s", owner.String(), id)
+	}
+	if !lock.IsTimedOut(ctx.BlockHeader().Time) {
+		return fmt.Errorf("hash lock of NFT #%s times out at %v", id, lock.Timeout)
+	}
+
+	token, err := k.GetNFT(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	token.SetStatus(types.NFTStatusDefault)
+	if err := k.UpdateNFT(ctx, token); err != nil {
+		return err
+	}
+	k.DeleteHashLock(ctx, id)
+	return nil
+}
@@ -0,0 +1,162 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetLoan returns the loan with the given ID.
+func (k *Keeper) GetLoan(ctx sdk.Context, id string) (*types.Loan, error) {
+	store := ctx.KVStore(k.offerStoreKey)
+	bz := store.Get(types.GetLoanKey(id))
+	if bz == nil {
+		return nil, fmt.Errorf("no loan with ID %s", id)
+	}
+
+	var loan types.Loan
+	k.cdc.MustUnmarshalJSON(bz, &loan)
+	return &loan, nil
+}
+
+// SetLoan stores the loan and adds it to the party index of the borrower and, once funded, of the lender.
+func (k *Keeper) SetLoan(ctx sdk.Context, loan *types.Loan) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Set(types.GetLoanKey(loan.ID), k.cdc.MustMarshalJSON(loan))
+	store.Set(types.GetLoanPartyIndexKey(loan.Borrower, loan.ID), []byte(loan.ID))
+	if loan.IsFunded() {
+		store.Set(types.GetLoanPartyIndexKey(loan.Lender, loan.ID), []byte(loan.ID))
+	}
+}
+
+// DeleteLoan removes the loan from the store and the party index, the collateral is not released.
+func (k *Keeper) DeleteLoan(ctx sdk.Context, loan *types.Loan) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Delete(types.GetLoanKey(loan.ID))
+	store.Delete(types.GetLoanPartyIndexKey(loan.Borrower, loan.ID))
+	if loan.IsFunded() {
+		store.Delete(types.GetLoanPartyIndexKey(loan.Lender, loan.ID))
+	}
+}
+
+// GetLoansByParty returns all loans the address is the borrower or the lender of.
+func (k *Keeper) GetLoansByParty(ctx sdk.Context, party sdk.AccAddress) []*types.Loan {
+	var loans []*types.Loan
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.offerStoreKey), types.GetLoanPartyIndexPrefix(party))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		loan, err := k.GetLoan(ctx, string(iterator.Value()))
+		if err != nil {
+			panic(fmt.Sprintf("loan party index refers to a missing loan: %v", err))
+		}
+		loans = append(loans, loan)
+	}
+	return loans
+}
+
+// GetLoansIterator returns an iterator over all loans.
+func (k *Keeper) GetLoansIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.offerStoreKey)
+	return sdk.KVStorePrefixIterator(store, types.LoanPrefix)
+}
+
+// RequestLoan holds the NFT of the borrower as the collateral of the loan and stores the loan.
+func (k *Keeper) RequestLoan(ctx sdk.Context, loan *types.Loan) error {
+	token, err := k.GetNFT(ctx, loan.NFTID)
+	if err != nil {
+		return fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	if !token.Owner.Equals(loan.Borrower) {
+		return fmt.Errorf("%s is not the owner of NFT #%s", loan.Borrower.String(), loan.NFTID)
+	}
+	if token.Status != types.NFTStatusDefault {
+		return fmt.Errorf("NFT #%s is %s", loan.NFTID, token.Status)
+	}
+
+	token.SetStatus(types.NFTStatusCollateral)
+	if err := k.UpdateNFT(ctx, token); err != nil {
+		return err
+	}
+	k.SetLoan(ctx, loan)
+	return nil
+}
+
+// CancelLoanRequest returns the collateral of a loan that has not been funded to the borrower and deletes the loan.
+func (k *Keeper) CancelLoanRequest(ctx sdk.Context, loan *types.Loan) error {
+	if loan.IsFunded() {
+		return fmt.Errorf("loan %s has been funded", loan.ID)
+	}
+	if err := k.releaseCollateral(ctx, loan, loan.Borrower); err != nil {
+		return err
+	}
+
+	k.DeleteLoan(ctx, loan)
+	return nil
+}
+
+// FundLoan pays the principal from the lender to the borrower, the loan is due the duration of the loan later.
+func (k *Keeper) FundLoan(ctx sdk.Context, loan *types.Loan, lender sdk.AccAddress) error {
+	if loan.IsFunded() {
+		return fmt.Errorf("loan %s has been funded", loan.ID)
+	}
+	if lender.Equals(loan.Borrower) {
+		return fmt.Errorf("%s is the borrower of loan %s", lender.String(), loan.ID)
+	}
+	if err := k.coinKeeper.SendCoins(ctx, lender, loan.Borrower, loan.Principal); err != nil {
+		return err
+	}
+
+	loan.Lender = lender
+	loan.Deadline = ctx.BlockHeader().Time.Add(loan.Duration)
+	k.SetLoan(ctx, loan)
+	return nil
+}
+
+// RepayLoan pays the principal and the interest from the borrower to the lender before the deadline,
+// returns the collateral to the borrower and deletes the loan.
+func (k *Keeper) RepayLoan(ctx sdk.Context, loan *types.Loan) error {
+	if !loan.IsFunded() {
+		return fmt.Errorf("loan %s has not been funded", loan.ID)
+	}
+	if loan.IsDue(ctx.BlockHeader().Time) {
+		return fmt.Errorf("deadline %v of loan %s has passed", loan.Deadline, loan.ID)
+	}
+	if err := k.coinKeeper.SendCoins(ctx, loan.Borrower, loan.Lender, loan.GetRepayment()); err != nil {
+		return err
+	}
+	if err := k.releaseCollateral(ctx, loan, loan.Borrower); err != nil {
+		return err
+	}
+
+	k.DeleteLoan(ctx, loan)
+	return nil
+}
+
+// ClaimLoanCollateral transfers the collateral of a loan that was not repaid by the deadline to the lender
+// and deletes the loan.
+func (k *Keeper) ClaimLoanCollateral(ctx sdk.Context, loan *types.Loan) error {
+	if !loan.IsDue(ctx.BlockHeader().Time) {
+		return fmt.Errorf("loan %s is not due", loan.ID)
+	}
+	if err := k.releaseCollateral(ctx, loan, loan.Lender); err != nil {
+		return err
+	}
+
+	k.DeleteLoan(ctx, loan)
+	return nil
+}
+
+// releaseCollateral releases the NFT held as the collateral of the loan to the recipient.
+func (k *Keeper) releaseCollateral(ctx sdk.Context, loan *types.Loan, recipient sdk.AccAddress) error {
+	token, err := k.GetNFT(ctx, loan.NFTID)
+	if err != nil {
+		return fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	if !recipient.Equals(token.Owner) {
+		token.SetSellerBeneficiary(sdk.AccAddress{})
+	}
+	token.Owner = recipient
+	token.SetStatus(types.NFTStatusDefault)
+	return k.UpdateNFT(ctx, token)
+}
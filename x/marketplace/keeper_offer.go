@@ -0,0 +1,231 @@
+package marketplace
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetOfferSequence returns the number that will be used as the ID of the next offer.
+func (k *Keeper) GetOfferSequence(ctx sdk.Context) uint64 {
+	store := ctx.KVStore(k.offerStoreKey)
+	bz := store.Get(types.OfferSequenceKey)
+	if bz == nil {
+		return types.DefaultStartingOfferID
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+// SetOfferSequence sets the number that will be used as the ID of the next offer.
+func (k *Keeper) SetOfferSequence(ctx sdk.Context, sequence uint64) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Set(types.OfferSequenceKey, sdk.Uint64ToBigEndian(sequence))
+}
+
+// GetNextOfferID returns a new offer ID and increments the offer sequence.
+func (k *Keeper) GetNextOfferID(ctx sdk.Context) string {
+	sequence := k.GetOfferSequence(ctx)
+	k.SetOfferSequence(ctx, sequence+1)
+	return strconv.FormatUint(sequence, 10)
+}
+
+// GetOffer returns the offer with the given ID for the NFT.
+func (k *Keeper) GetOffer(ctx sdk.Context, nftID, offerID string) (*types.Offer, error) {
+	store := ctx.KVStore(k.offerStoreKey)
+	bz := store.Get(types.GetOfferKey(nftID, offerID))
+	if bz == nil {
+		return nil, fmt.Errorf("no offer with ID %s for NFT #%s", offerID, nftID)
+	}
+
+	var offer types.Offer
+	k.cdc.MustUnmarshalJSON(bz, &offer)
+	return &offer, nil
+}
+
+// SetOffer stores the offer and adds it to the buyer index and the expiry queues.
+func (k *Keeper) SetOffer(ctx sdk.Context, offer *types.Offer) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Set(types.GetOfferKey(offer.NFTID, offer.ID), k.cdc.MustMarshalJSON(offer))
+	store.Set(types.GetOfferBuyerIndexKey(offer.Buyer, offer.NFTID, offer.ID), types.GetOfferRef(offer.NFTID, offer.ID))
+	k.setOfferExpiry(ctx, offer.NFTID, offer)
+}
+
+// DeleteOffer removes the offer from the store, the buyer index and the expiry queues.
+// The coins locked by the offer are not unlocked.
+func (k *Keeper) DeleteOffer(ctx sdk.Context, offer *types.Offer) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Delete(types.GetOfferKey(offer.NFTID, offer.ID))
+	store.Delete(types.GetOfferBuyerIndexKey(offer.Buyer, offer.NFTID, offer.ID))
+	k.deleteOfferExpiry(ctx, offer.NFTID, offer)
+}
+
+// GetOffers returns all offers for the NFT.
+func (k *Keeper) GetOffers(ctx sdk.Context, nftID string) []*types.Offer {
+	var offers []*types.Offer
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.offerStoreKey), types.GetOffersPrefix(nftID))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var offer types.Offer
+		k.cdc.MustUnmarshalJSON(iterator.Value(), &offer)
+		offers = append(offers, &offer)
+	}
+	return offers
+}
+
+// GetOffersByBuyer returns all offers of the buyer.
+func (k *Keeper) GetOffersByBuyer(ctx sdk.Context, buyer sdk.AccAddress) []*types.Offer {
+	var offers []*types.Offer
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.offerStoreKey), types.GetOfferBuyerIndexPrefix(buyer))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		nftID, offerID := types.SplitOfferRef(iterator.Value())
+		offer, err := k.GetOffer(ctx, nftID, offerID)
+		if err != nil {
+			panic(fmt.Sprintf("offer buyer index refers to a missing offer: %v", err))
+		}
+		offers = append(offers, offer)
+	}
+	return offers
+}
+
+// countOffers returns the number of open offers for the NFT.
+func (k *Keeper) countOffers(ctx sdk.Context, nftID string) uint64 {
+	var count uint64
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.offerStoreKey), types.GetOffersPrefix(nftID))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		count++
+	}
+	return count
+}
+
+// GetOffersIterator returns an iterator over all offers.
+func (k *Keeper) GetOffersIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.offerStoreKey)
+	return sdk.KVStorePrefixIterator(store, types.OfferPrefix)
+}
+
+// MigrateOffers moves the offers embedded in NFT records written before offers had a store of their own
+// to the offer store. InitGenesis runs it, so a genesis exported by an older version is migrated on import.
+// The coins of those offers, and of the auction bids of that version, were taken from the buyers with
+// SubtractCoins instead of being locked in the module account, the module account is funded for them.
+func (k *Keeper) MigrateOffers(ctx sdk.Context) {
+	var tokens []*NFT
+	iterator := k.GetNFTsIterator(ctx)
+	for ; iterator.Valid(); iterator.Next() {
+		var token NFT
+		k.cdc.MustUnmarshalJSON(iterator.Value(), &token)
+		if len(token.Offers) != 0 {
+			tokens = append(tokens, &token)
+		}
+	}
+	iterator.Close()
+
+	store := ctx.KVStore(k.storeKey)
+	for _, token := range tokens {
+		for _, offer := range token.Offers {
+			offer.NFTID = token.ID
+			k.SetOffer(ctx, offer)
+		}
+		token.Offers = nil
+		store.Set(types.GetNFTKey(token.ID), k.cdc.MustMarshalJSON(token))
+	}
+
+	// the subtracted coins are still counted in the total supply, they are added back rather than minted
+	moduleAddr := k.supplyKeeper.GetModuleAddress(types.ModuleName)
+	balance := k.coinKeeper.GetCoins(ctx, moduleAddr)
+	var missing sdk.Coins
+	for _, coin := range k.lockedCoins(ctx) {
+		if diff := coin.Amount.Sub(balance.AmountOf(coin.Denom)); diff.IsPositive() {
+			missing = missing.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, diff)))
+		}
+	}
+	if missing.Empty() {
+		return
+	}
+	// creates the module account if there is none yet
+	k.supplyKeeper.GetModuleAccount(ctx, types.ModuleName)
+	if _, err := k.coinKeeper.AddCoins(ctx, moduleAddr, missing); err != nil {
+		panic(fmt.Sprintf("failed to fund the module account for the legacy offers and bids: %v", err))
+	}
+}
+
+// setOfferExpiry adds the offer for the given NFT to the expiry queue it expires by.
+func (k *Keeper) setOfferExpiry(ctx sdk.Context, nftID string, offer *types.Offer) {
+	store := ctx.KVStore(k.offerStoreKey)
+	ref := types.GetOfferRef(nftID, offer.ID)
+	if !offer.ExpirationTime.IsZero() {
+		store.Set(types.GetOfferExpiryTimeQueueKey(offer.ExpirationTime, nftID, offer.ID), ref)
+	}
+	if offer.ExpirationHeight > 0 {
+		store.Set(types.GetOfferExpiryHeightQueueKey(offer.ExpirationHeight, nftID, offer.ID), ref)
+	}
+}
+
+// deleteOfferExpiry removes the offer for the given NFT from the expiry queues.
+func (k *Keeper) deleteOfferExpiry(ctx sdk.Context, nftID string, offer *types.Offer) {
+	store := ctx.KVStore(k.offerStoreKey)
+	if !offer.ExpirationTime.IsZero() {
+		store.Delete(types.GetOfferExpiryTimeQueueKey(offer.ExpirationTime, nftID, offer.ID))
+	}
+	if offer.ExpirationHeight > 0 {
+		store.Delete(types.GetOfferExpiryHeightQueueKey(offer.ExpirationHeight, nftID, offer.ID))
+	}
+}
+
+// RefundExpiredOffers refunds and removes all offers that have expired by the current block time or height.
+func (k *Keeper) RefundExpiredOffers(ctx sdk.Context) {
+	store := ctx.KVStore(k.offerStoreKey)
+
+	// offers are collected first because refunding an offer deletes it from the queue being iterated,
+	// an offer due in both queues is collected once
+	var refs [][]byte
+	seen := make(map[string]bool)
+	for _, iterator := range []sdk.Iterator{
+		store.Iterator(types.OfferExpiryTimeQueuePrefix,
+			sdk.PrefixEndBytes(types.GetOfferExpiryTimeQueueTimeKey(ctx.BlockHeader().Time))),
+		store.Iterator(types.OfferExpiryHeightQueuePrefix,
+			sdk.PrefixEndBytes(types.GetOfferExpiryHeightQueueHeightKey(ctx.BlockHeight()))),
+	} {
+		for ; iterator.Valid(); iterator.Next() {
+			if ref := iterator.Value(); !seen[string(ref)] {
+				seen[string(ref)] = true
+				refs = append(refs, ref)
+			}
+		}
+		iterator.Close()
+	}
+
+	for _, ref := range refs {
+		nftID, offerID := types.SplitOfferRef(ref)
+		err := runAtomically(ctx, func(ctx sdk.Context) error {
+			return k.refundOffer(ctx, nftID, offerID)
+		})
+		if err != nil {
+			ctx.Logger().Error("failed to refund expired offer", "nft", nftID, "offer", offerID, "error", err)
+		}
+	}
+}
+
+func (k *Keeper) refundOffer(ctx sdk.Context, nftID, offerID string) error {
+	offer, err := k.GetOffer(ctx, nftID, offerID)
+	if err != nil {
+		return err
+	}
+	if err := k.UnlockCoins(ctx, offer.Buyer, offer.Price); err != nil {
+		return err
+	}
+	k.DeleteOffer(ctx, offer)
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeRefundOffer,
+		sdk.NewAttribute(types.AttributeKeyNFTID, nftID),
+		sdk.NewAttribute(types.AttributeKeyOfferID, offerID),
+		sdk.NewAttribute(types.AttributeKeyBuyer, offer.Buyer.String()),
+		sdk.NewAttribute(types.AttributeKeyPrice, offer.Price.String()),
+	))
+	return nil
+}
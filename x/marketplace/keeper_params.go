@@ -0,0 +1,30 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetParams returns the total set of marketplace parameters.
+func (k *Keeper) GetParams(ctx sdk.Context) (params types.Params) {
+	k.paramSpace.GetParamSet(ctx, &params)
+	return params
+}
+
+// SetParams sets the total set of marketplace parameters.
+func (k *Keeper) SetParams(ctx sdk.Context, params types.Params) {
+	k.paramSpace.SetParamSet(ctx, &params)
+}
+
+// checkBeneficiaryCommission returns an error if the commission is not between 0 and the MaxBeneficiaryCommission param.
+func (k *Keeper) checkBeneficiaryCommission(ctx sdk.Context, commission sdk.Dec) error {
+	if commission.IsNil() || commission.IsNegative() {
+		return fmt.Errorf("invalid beneficiary commission")
+	}
+	if commission.GT(k.GetParams(ctx).MaxBeneficiaryCommission) {
+		return fmt.Errorf("beneficiary commission is too high")
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package marketplace
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetRentalListing returns the rental listing of the NFT with the given ID.
+func (k *Keeper) GetRentalListing(ctx sdk.Context, id string) (*types.RentalListing, error) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetRentalListingKey(id))
+	if bz == nil {
+		return nil, fmt.Errorf("NFT #%s is not listed for rent", id)
+	}
+
+	var listing types.RentalListing
+	k.cdc.MustUnmarshalJSON(bz, &listing)
+	return &listing, nil
+}
+
+// SetRentalListing stores the rental listing.
+func (k *Keeper) SetRentalListing(ctx sdk.Context, listing *types.RentalListing) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetRentalListingKey(listing.NFTID), k.cdc.MustMarshalJSON(listing))
+}
+
+// DeleteRentalListing removes the rental listing of the NFT with the given ID.
+func (k *Keeper) DeleteRentalListing(ctx sdk.Context, id string) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetRentalListingKey(id))
+}
+
+// GetRentalListingsIterator returns an iterator over all rental listings.
+func (k *Keeper) GetRentalListingsIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.RentalListingPrefix)
+}
+
+// ListNFTForRent lists the NFT of the owner for rent, replacing its previous listing.
+func (k *Keeper) ListNFTForRent(ctx sdk.Context, listing *types.RentalListing) error {
+	token, err := k.GetNFT(ctx, listing.NFTID)
+	if err != nil {
+		return fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	if !token.Owner.Equals(listing.Owner) {
+		return fmt.Errorf("%s is not the owner of NFT #%s", listing.Owner.String(), listing.NFTID)
+	}
+
+	k.SetRentalListing(ctx, listing)
+	return nil
+}
+
+// RentNFT gives the usage rights of the listed NFT to the renter for the duration, the renter pays
+// the price of the listing for every unit of the duration to the owner.
+func (k *Keeper) RentNFT(ctx sdk.Context, id string, renter sdk.AccAddress, duration uint64) (*types.Rental, error) {
+	listing, err := k.GetRentalListing(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if duration > listing.MaxDuration {
+		return nil, fmt.Errorf("NFT #%s can be rented for at most %d %s(s)", id, listing.MaxDuration, listing.Unit)
+	}
+	token, err := k.GetNFT(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	// the listing is left behind when the NFT changes hands, it is only valid for the owner that made it
+	if !token.Owner.Equals(listing.Owner) {
+		return nil, fmt.Errorf("rental listing of NFT #%s is out of date", id)
+	}
+	if token.Owner.Equals(renter) {
+		return nil, fmt.Errorf("%s is the owner of NFT #%s", renter.String(), id)
+	}
+	if token.Status != types.NFTStatusDefault {
+		return nil, fmt.Errorf("NFT #%s is %s", id, token.Status)
+	}
+
+	price := sdk.Coins{}
+	for _, coin := range listing.Price {
+		price = price.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, coin.Amount.MulRaw(int64(duration)))))
+	}
+	if err := k.coinKeeper.SendCoins(ctx, renter, token.Owner, price); err != nil {
+		return nil, err
+	}
+
+	rental := &types.Rental{
+		NFTID:       id,
+		Owner:       token.Owner,
+		User:        renter,
+		Price:       price,
+		Duration:    duration,
+		Unit:        listing.Unit,
+		StartTime:   ctx.BlockHeader().Time,
+		StartHeight: ctx.BlockHeight(),
+	}
+	if listing.Unit == types.RentalUnitHour {
+		rental.ExpirationTime = rental.StartTime.Add(time.Duration(duration) * time.Hour)
+	} else {
+		rental.ExpirationHeight = rental.StartHeight + int64(duration)
+	}
+
+	token.SetStatus(types.NFTStatusRented)
+	token.User = renter
+	token.UserExpirationTime = rental.ExpirationTime
+	token.UserExpirationHeight = rental.ExpirationHeight
+	if err := k.UpdateNFT(ctx, token); err != nil {
+		return nil, err
+	}
+	k.setRentalExpiry(ctx, token)
+	k.appendRentalHistory(ctx, rental)
+	return rental, nil
+}
+
+// ExpireRentals returns the usage rights of the NFTs whose rentals have expired by the current block
+// time or height to their owners.
+func (k *Keeper) ExpireRentals(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+
+	// NFTs are collected first because ending a rental deletes it from the queue being iterated
+	var ids []string
+	iterator := store.Iterator(types.RentalExpiryTimeQueuePrefix,
+		sdk.PrefixEndBytes(types.GetRentalExpiryTimeQueueTimeKey(ctx.BlockHeader().Time)))
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, string(iterator.Value()))
+	}
+	iterator.Close()
+	iterator = store.Iterator(types.RentalExpiryHeightQueuePrefix,
+		sdk.PrefixEndBytes(types.GetRentalExpiryHeightQueueHeightKey(ctx.BlockHeight())))
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, string(iterator.Value()))
+	}
+	iterator.Close()
+
+	for _, id := range ids {
+		err := runAtomically(ctx, func(ctx sdk.Context) error {
+			token, err := k.GetNFT(ctx, id)
+			if err != nil {
+				return fmt.Errorf("failed to GetNFT: %v", err)
+			}
+			user := token.User
+			k.deleteRentalExpiry(ctx, token)
+			token.SetStatus(types.NFTStatusDefault)
+			token.User = nil
+			token.UserExpirationTime = time.Time{}
+			token.UserExpirationHeight = 0
+			if err := k.UpdateNFT(ctx, token); err != nil {
+				return err
+			}
+
+			ctx.EventManager().EmitEvent(sdk.NewEvent(
+				types.EventTypeExpireRental,
+				sdk.NewAttribute(types.AttributeKeyNFTID, id),
+				sdk.NewAttribute(types.AttributeKeyOwner, token.Owner.String()),
+				sdk.NewAttribute(types.AttributeKeyUser, user.String()),
+			))
+			return nil
+		})
+		if err != nil {
+			ctx.Logger().Error("failed to expire rental", "nft", id, "error", err)
+		}
+	}
+}
+
+// setRentalExpiry adds the rented NFT to the expiry queue of its unit.
+func (k *Keeper) setRentalExpiry(ctx sdk.Context, token *NFT) {
+	store := ctx.KVStore(k.storeKey)
+	if !token.UserExpirationTime.IsZero() {
+		store.Set(types.GetRentalExpiryTimeQueueKey(token.UserExpirationTime, token.ID), []byte(token.ID))
+	} else {
+		store.Set(types.GetRentalExpiryHeightQueueKey(token.UserExpirationHeight, token.ID), []byte(token.ID))
+	}
+}
+
+// deleteRentalExpiry removes the rented NFT from the expiry queue of its unit.
+func (k *Keeper) deleteRentalExpiry(ctx sdk.Context, token *NFT) {
+	store := ctx.KVStore(k.storeKey)
+	if !token.UserExpirationTime.IsZero() {
+		store.Delete(types.GetRentalExpiryTimeQueueKey(token.UserExpirationTime, token.ID))
+	} else {
+		store.Delete(types.GetRentalExpiryHeightQueueKey(token.UserExpirationHeight, token.ID))
+	}
+}
+
+// appendRentalHistory appends the rental to the rental history of its NFT.
+func (k *Keeper) appendRentalHistory(ctx sdk.Context, rental *types.Rental) {
+	store := ctx.KVStore(k.storeKey)
+
+	var index uint64
+	iterator := sdk.KVStoreReversePrefixIterator(store, types.GetRentalHistoryPrefix(rental.NFTID))
+	if iterator.Valid() {
+		key := iterator.Key()
+		index = binary.BigEndian.Uint64(key[len(key)-8:]) + 1
+	}
+	iterator.Close()
+
+	store.Set(types.GetRentalHistoryKey(rental.NFTID, index), k.cdc.MustMarshalJSON(rental))
+}
+
+// GetRentalHistory returns the rentals of the NFT in the order they were made.
+func (k *Keeper) GetRentalHistory(ctx sdk.Context, id string) []*types.Rental {
+	rentals := []*types.Rental{}
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.GetRentalHistoryPrefix(id))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var rental types.Rental
+		k.cdc.MustUnmarshalJSON(iterator.Value(), &rental)
+		rentals = append(rentals, &rental)
+	}
+	return rentals
+}
+
+// GetRentalHistoriesIterator returns an iterator over the rental histories of all NFTs.
+func (k *Keeper) GetRentalHistoriesIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.RentalHistoryPrefix)
+}
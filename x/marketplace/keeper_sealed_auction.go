@@ -0,0 +1,120 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func (k *Keeper) SetSealedBid(ctx sdk.Context, bid *types.SealedBid) {
+	store := ctx.KVStore(k.auctionStoreKey)
+	store.Set(types.GetSealedBidKey(bid.NFTID, bid.Bidder), k.cdc.MustMarshalJSON(bid))
+}
+
+func (k *Keeper) GetSealedBid(ctx sdk.Context, id string, bidder sdk.AccAddress) (*types.SealedBid, error) {
+	store := ctx.KVStore(k.auctionStoreKey)
+	key := types.GetSealedBidKey(id, bidder)
+	if !store.Has(key) {
+		return nil, fmt.Errorf("%s has no sealed bid on lot %s", bidder, id)
+	}
+	var bid types.SealedBid
+	k.cdc.MustUnmarshalJSON(store.Get(key), &bid)
+	return &bid, nil
+}
+
+// GetSealedBids returns the sealed bids on the lot of the given NFT ordered by bidder.
+func (k *Keeper) GetSealedBids(ctx sdk.Context, id string) []*types.SealedBid {
+	var bids []*types.SealedBid
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.auctionStoreKey), types.GetSealedBidsPrefix(id))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var bid types.SealedBid
+		k.cdc.MustUnmarshalJSON(iterator.Value(), &bid)
+		bids = append(bids, &bid)
+	}
+	return bids
+}
+
+// GetSealedBidsIterator returns an iterator over the sealed bids on all lots.
+func (k *Keeper) GetSealedBidsIterator(ctx sdk.Context) sdk.Iterator {
+	return sdk.KVStorePrefixIterator(ctx.KVStore(k.auctionStoreKey), types.SealedBidPrefix)
+}
+
+func (k *Keeper) deleteSealedBid(ctx sdk.Context, bid *types.SealedBid) {
+	ctx.KVStore(k.auctionStoreKey).Delete(types.GetSealedBidKey(bid.NFTID, bid.Bidder))
+}
+
+// settleSealedAuction settles a sealed-bid auction. The highest revealed bid wins, ties go to the
+// earliest commitment. The winner pays its bid, or the second highest revealed bid or the opening price
+// if the lot is second-price. Revealed bids and deposits are returned to the bidders, except for
+// the UnrevealedBidForfeit share of the deposits of unrevealed bids which is paid to the owner.
+// Returns the resulting owner of the NFT.
+func (k *Keeper) settleSealedAuction(ctx sdk.Context, lot *types.AuctionLot) (sdk.AccAddress, error) {
+	nft, err := k.GetNFT(ctx, lot.NFTID)
+	if err != nil {
+		return nil, err
+	}
+
+	forfeitRate := k.GetParams(ctx).UnrevealedBidForfeit
+	var winner, runnerUp *types.SealedBid
+	for _, bid := range k.GetSealedBids(ctx, lot.NFTID) {
+		if err := k.UnlockCoins(ctx, bid.Bidder, bid.Deposit.Add(bid.Bid)); err != nil {
+			return nil, err
+		}
+		k.deleteSealedBid(ctx, bid)
+
+		if !bid.IsRevealed() {
+			forfeit := GetCommission(bid.Deposit, forfeitRate)
+			if forfeit.IsZero() {
+				continue
+			}
+			if err := k.coinKeeper.SendCoins(ctx, bid.Bidder, nft.Owner, forfeit); err != nil {
+				return nil, err
+			}
+			ctx.EventManager().EmitEvent(sdk.NewEvent(
+				types.EventTypeForfeitDeposit,
+				sdk.NewAttribute(types.AttributeKeyNFTID, lot.NFTID),
+				sdk.NewAttribute(types.AttributeKeyBidder, bid.Bidder.String()),
+				sdk.NewAttribute(types.AttributeKeyForfeit, forfeit.String()),
+			))
+			continue
+		}
+
+		switch {
+		case winner == nil || outbids(bid, winner):
+			winner, runnerUp = bid, winner
+		case runnerUp == nil || outbids(bid, runnerUp):
+			runnerUp = bid
+		}
+	}
+
+	if winner == nil {
+		if err := k.removeNFTFromAuction(ctx, nft); err != nil {
+			return nil, err
+		}
+		return nft.Owner, nil
+	}
+
+	price := winner.Bid
+	if lot.SecondPrice {
+		price = lot.OpeningPrice
+		if runnerUp != nil {
+			price = runnerUp.Bid
+		}
+	}
+	if err := k.BuyLotOnAuction(ctx, winner.Bidder, winner.BuyerBeneficiary, price, lot,
+		winner.BeneficiaryCommission); err != nil {
+		return nil, err
+	}
+	return winner.Bidder, nil
+}
+
+// outbids reports whether the revealed bid a beats b. Revealed bids are in the single denom of the lot.
+func outbids(a, b *types.SealedBid) bool {
+	amountA, amountB := a.Bid[0].Amount, b.Bid[0].Amount
+	if !amountA.Equal(amountB) {
+		return amountA.GT(amountB)
+	}
+	return a.TimeCreated.Before(b.TimeCreated)
+}
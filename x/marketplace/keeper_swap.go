@@ -0,0 +1,217 @@
+package marketplace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetSwap returns the swap with the given ID.
+func (k *Keeper) GetSwap(ctx sdk.Context, id string) (*types.Swap, error) {
+	store := ctx.KVStore(k.offerStoreKey)
+	bz := store.Get(types.GetSwapKey(id))
+	if bz == nil {
+		return nil, fmt.Errorf("no swap with ID %s", id)
+	}
+
+	var swap types.Swap
+	k.cdc.MustUnmarshalJSON(bz, &swap)
+	return &swap, nil
+}
+
+// SetSwap stores the swap and adds it to the party index and the expiry queue.
+func (k *Keeper) SetSwap(ctx sdk.Context, swap *types.Swap) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Set(types.GetSwapKey(swap.ID), k.cdc.MustMarshalJSON(swap))
+	store.Set(types.GetSwapPartyIndexKey(swap.Proposer, swap.ID), []byte(swap.ID))
+	store.Set(types.GetSwapPartyIndexKey(swap.Counterparty, swap.ID), []byte(swap.ID))
+	if !swap.ExpirationTime.IsZero() {
+		store.Set(types.GetSwapExpiryQueueKey(swap.ExpirationTime, swap.ID), []byte(swap.ID))
+	}
+}
+
+// DeleteSwap removes the swap from the store, the party index and the expiry queue.
+// The NFTs and coins held by the swap are not released.
+func (k *Keeper) DeleteSwap(ctx sdk.Context, swap *types.Swap) {
+	store := ctx.KVStore(k.offerStoreKey)
+	store.Delete(types.GetSwapKey(swap.ID))
+	store.Delete(types.GetSwapPartyIndexKey(swap.Proposer, swap.ID))
+	store.Delete(types.GetSwapPartyIndexKey(swap.Counterparty, swap.ID))
+	if !swap.ExpirationTime.IsZero() {
+		store.Delete(types.GetSwapExpiryQueueKey(swap.ExpirationTime, swap.ID))
+	}
+}
+
+// GetSwapsByParty returns all swaps the address is the proposer or the counterparty of.
+func (k *Keeper) GetSwapsByParty(ctx sdk.Context, party sdk.AccAddress) []*types.Swap {
+	var swaps []*types.Swap
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.offerStoreKey), types.GetSwapPartyIndexPrefix(party))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		swap, err := k.GetSwap(ctx, string(iterator.Value()))
+		if err != nil {
+			panic(fmt.Sprintf("swap party index refers to a missing swap: %v", err))
+		}
+		swaps = append(swaps, swap)
+	}
+	return swaps
+}
+
+// GetSwapsIterator returns an iterator over all swaps.
+func (k *Keeper) GetSwapsIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.offerStoreKey)
+	return sdk.KVStorePrefixIterator(store, types.SwapPrefix)
+}
+
+// ProposeSwap escrows the NFTs and coins of the proposer and stores the swap.
+func (k *Keeper) ProposeSwap(ctx sdk.Context, swap *types.Swap) error {
+	if swap.IsExpired(ctx.BlockHeader().Time) {
+		return fmt.Errorf("expiration time %v has passed", swap.ExpirationTime)
+	}
+	for _, id := range swap.ProposerNFTs {
+		token, err := k.getSwappableNFT(ctx, id, swap.Proposer)
+		if err != nil {
+			return err
+		}
+		token.SetStatus(types.NFTStatusInSwap)
+		if err := k.UpdateNFT(ctx, token); err != nil {
+			return err
+		}
+	}
+	for _, id := range swap.CounterpartyNFTs {
+		if _, err := k.getSwappableNFT(ctx, id, swap.Counterparty); err != nil {
+			return err
+		}
+	}
+	if !swap.ProposerCoins.Empty() {
+		if err := k.LockCoins(ctx, swap.Proposer, swap.ProposerCoins); err != nil {
+			return err
+		}
+	}
+
+	k.SetSwap(ctx, swap)
+	return nil
+}
+
+// AcceptSwap exchanges the NFTs and coins of both sides of the swap and deletes it.
+func (k *Keeper) AcceptSwap(ctx sdk.Context, swap *types.Swap) error {
+	if swap.IsExpired(ctx.BlockHeader().Time) {
+		return fmt.Errorf("swap %s has expired", swap.ID)
+	}
+	for _, id := range swap.CounterpartyNFTs {
+		token, err := k.getSwappableNFT(ctx, id, swap.Counterparty)
+		if err != nil {
+			return err
+		}
+		token.Owner = swap.Proposer
+		token.SetSellerBeneficiary(sdk.AccAddress{})
+		if err := k.UpdateNFT(ctx, token); err != nil {
+			return err
+		}
+	}
+	if err := k.releaseSwapNFTs(ctx, swap, swap.Counterparty); err != nil {
+		return err
+	}
+	if !swap.CounterpartyCoins.Empty() {
+		if err := k.coinKeeper.SendCoins(ctx, swap.Counterparty, swap.Proposer, swap.CounterpartyCoins); err != nil {
+			return err
+		}
+	}
+	if !swap.ProposerCoins.Empty() {
+		if err := k.UnlockCoins(ctx, swap.Counterparty, swap.ProposerCoins); err != nil {
+			return err
+		}
+	}
+
+	k.DeleteSwap(ctx, swap)
+	return nil
+}
+
+// CancelSwap returns the NFTs and coins held by the swap to the proposer and deletes it.
+func (k *Keeper) CancelSwap(ctx sdk.Context, swap *types.Swap) error {
+	if err := k.releaseSwapNFTs(ctx, swap, swap.Proposer); err != nil {
+		return err
+	}
+	if !swap.ProposerCoins.Empty() {
+		if err := k.UnlockCoins(ctx, swap.Proposer, swap.ProposerCoins); err != nil {
+			return err
+		}
+	}
+
+	k.DeleteSwap(ctx, swap)
+	return nil
+}
+
+// CancelExpiredSwaps cancels all swaps that have expired by the current block time.
+func (k *Keeper) CancelExpiredSwaps(ctx sdk.Context) {
+	store := ctx.KVStore(k.offerStoreKey)
+
+	// swaps are collected first because cancelling a swap deletes it from the queue being iterated
+	var ids []string
+	iterator := store.Iterator(types.SwapExpiryQueuePrefix,
+		sdk.PrefixEndBytes(types.GetSwapExpiryQueueTimeKey(ctx.BlockHeader().Time)))
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, string(iterator.Value()))
+	}
+	iterator.Close()
+
+	for _, id := range ids {
+		err := runAtomically(ctx, func(ctx sdk.Context) error {
+			swap, err := k.GetSwap(ctx, id)
+			if err != nil {
+				return err
+			}
+			if err := k.CancelSwap(ctx, swap); err != nil {
+				return err
+			}
+
+			ctx.EventManager().EmitEvent(sdk.NewEvent(
+				types.EventTypeExpireSwap,
+				sdk.NewAttribute(types.AttributeKeySwapID, swap.ID),
+				sdk.NewAttribute(types.AttributeKeyProposer, swap.Proposer.String()),
+				sdk.NewAttribute(types.AttributeKeyProposerNFTs, strings.Join(swap.ProposerNFTs, ",")),
+				sdk.NewAttribute(types.AttributeKeyProposerCoins, swap.ProposerCoins.String()),
+			))
+			return nil
+		})
+		if err != nil {
+			ctx.Logger().Error("failed to cancel expired swap", "swap", id, "error", err)
+		}
+	}
+}
+
+// getSwappableNFT returns the NFT if it is owned by the address and is neither on sale nor locked.
+func (k *Keeper) getSwappableNFT(ctx sdk.Context, id string, owner sdk.AccAddress) (*NFT, error) {
+	token, err := k.GetNFT(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetNFT: %v", err)
+	}
+	if !token.Owner.Equals(owner) {
+		return nil, fmt.Errorf("%s is not the owner of NFT #%s", owner.String(), id)
+	}
+	if token.Status != types.NFTStatusDefault {
+		return nil, fmt.Errorf("NFT #%s is %s", id, token.Status)
+	}
+	return token, nil
+}
+
+// releaseSwapNFTs releases the NFTs of the proposer held by the swap to the recipient.
+func (k *Keeper) releaseSwapNFTs(ctx sdk.Context, swap *types.Swap, recipient sdk.AccAddress) error {
+	for _, id := range swap.ProposerNFTs {
+		token, err := k.GetNFT(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetNFT: %v", err)
+		}
+		if !recipient.Equals(token.Owner) {
+			token.SetSellerBeneficiary(sdk.AccAddress{})
+		}
+		token.Owner = recipient
+		token.SetStatus(types.NFTStatusDefault)
+		if err := k.UpdateNFT(ctx, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestLoan(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	borrower, lender, other := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+	now := ctx.BlockHeader().Time
+
+	requestLoan := func(id string) string {
+		result := handler(ctx, *types.NewMsgRequestLoan(borrower, id, coins(100), coins(10), 24*time.Hour))
+		require.True(t, result.IsOK(), result.Log)
+		return string(result.Data)
+	}
+	queryLoans := func(party sdk.AccAddress) []string {
+		bz, sdkErr := querier(ctx, []string{marketplace.QueryLoans, party.String()}, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		var res types.QueryResLoans
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		var ids []string
+		for _, loan := range res.Loans {
+			ids = append(ids, loan.ID)
+		}
+		return ids
+	}
+
+	// the collateral is locked until the loan is repaid
+	sword := mintNFT(t, mpKeeperTest, borrower)
+	require.False(t, handler(ctx, *types.NewMsgRequestLoan(other, sword, coins(100), coins(10), time.Hour)).IsOK())
+	loanID := requestLoan(sword)
+	requireOwner(t, mpKeeperTest, borrower, types.NFTStatusCollateral, sword)
+	require.False(t, handler(ctx, *types.NewMsgPutOnMarketNFT(borrower, borrower, sword, coins(100))).IsOK())
+	require.NotNil(t, keeper.TransferNFT(ctx, sword, borrower, other))
+	require.Equal(t, []string{loanID}, queryLoans(borrower))
+	require.Empty(t, queryLoans(lender))
+
+	// the principal is paid to the borrower and the deadline starts running
+	require.False(t, handler(ctx, *types.NewMsgRepayLoan(borrower, loanID)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgFundLoan(borrower, loanID)).IsOK())
+	before := getBalances(mpKeeperTest, borrower, lender)
+	result := handler(ctx, *types.NewMsgFundLoan(lender, loanID))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, []int64{before[0] + 100, before[1] - 100}, getBalances(mpKeeperTest, borrower, lender))
+	require.False(t, handler(ctx, *types.NewMsgFundLoan(other, loanID)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgCancelLoanRequest(borrower, loanID)).IsOK())
+	require.Equal(t, []string{loanID}, queryLoans(lender))
+
+	bz, sdkErr := querier(ctx, []string{marketplace.QueryLoan, loanID}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	var loan types.Loan
+	types.ModuleCdc.MustUnmarshalJSON(bz, &loan)
+	require.True(t, loan.Lender.Equals(lender))
+	require.True(t, loan.Deadline.Equal(now.Add(24*time.Hour)))
+
+	// repaying before the deadline returns the collateral, the lender cannot claim it
+	require.False(t, handler(ctx, *types.NewMsgClaimLoanCollateral(lender, loanID)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgRepayLoan(other, loanID)).IsOK())
+	result = handler(ctx, *types.NewMsgRepayLoan(borrower, loanID))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, []int64{before[0] - 10, before[1] + 10}, getBalances(mpKeeperTest, borrower, lender))
+	requireOwner(t, mpKeeperTest, borrower, types.NFTStatusDefault, sword)
+	require.Empty(t, queryLoans(borrower))
+	require.Empty(t, queryLoans(lender))
+
+	// a request that has not been funded is cancelled by the borrower
+	loanID = requestLoan(sword)
+	require.False(t, handler(ctx, *types.NewMsgCancelLoanRequest(other, loanID)).IsOK())
+	result = handler(ctx, *types.NewMsgCancelLoanRequest(borrower, loanID))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, borrower, types.NFTStatusDefault, sword)
+
+	// after the deadline the borrower cannot repay and the lender claims the collateral
+	loanID = requestLoan(sword)
+	result = handler(ctx, *types.NewMsgFundLoan(lender, loanID))
+	require.True(t, result.IsOK(), result.Log)
+	ctx = ctx.WithBlockTime(now.Add(24 * time.Hour))
+	require.False(t, handler(ctx, *types.NewMsgRepayLoan(borrower, loanID)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgClaimLoanCollateral(other, loanID)).IsOK())
+	result = handler(ctx, *types.NewMsgClaimLoanCollateral(lender, loanID))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, lender, types.NFTStatusDefault, sword)
+	_, sdkErr = querier(ctx, []string{marketplace.QueryLoan, loanID}, abci.RequestQuery{})
+	require.NotNil(t, sdkErr)
+
+	msgInv, broken := marketplace.NFTIndexesInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestRequestLoanValidateBasic(t *testing.T) {
+	borrower := sdk.AccAddress([]byte("borrower"))
+	principal := sdk.NewCoins(sdk.NewInt64Coin(types.DefaultTokenDenom, 100))
+
+	require.Nil(t, types.NewMsgRequestLoan(borrower, "token", principal, nil, time.Hour).ValidateBasic())
+	require.Nil(t, types.NewMsgRequestLoan(borrower, "token", principal, principal, time.Hour).ValidateBasic())
+	for name, msg := range map[string]*types.MsgRequestLoan{
+		"no borrower":      types.NewMsgRequestLoan(sdk.AccAddress{}, "token", principal, nil, time.Hour),
+		"no token":         types.NewMsgRequestLoan(borrower, "", principal, nil, time.Hour),
+		"no principal":     types.NewMsgRequestLoan(borrower, "token", sdk.Coins{}, nil, time.Hour),
+		"invalid interest": types.NewMsgRequestLoan(borrower, "token", principal, sdk.Coins{sdk.NewInt64Coin(types.DefaultTokenDenom, 0)}, time.Hour),
+		"no duration":      types.NewMsgRequestLoan(borrower, "token", principal, nil, 0),
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+}
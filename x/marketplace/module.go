@@ -85,7 +85,9 @@ func (AppModule) Name() string {
 	return ModuleName
 }
 
-func (am AppModule) RegisterInvariants(ir sdk.InvariantRegistry) {}
+func (am AppModule) RegisterInvariants(ir sdk.InvariantRegistry) {
+	RegisterInvariants(ir, am.keeper)
+}
 
 func (am AppModule) Route() string {
 	return RouterKey
@@ -106,7 +108,12 @@ func (am AppModule) BeginBlock(_ sdk.Context, _ abci.RequestBeginBlock) {
 	return
 }
 
-func (am AppModule) EndBlock(sdk.Context, abci.RequestEndBlock) []abci.ValidatorUpdate {
+func (am AppModule) EndBlock(ctx sdk.Context, _ abci.RequestEndBlock) []abci.ValidatorUpdate {
+	am.keeper.CheckFinishedAuctions(ctx)
+	am.keeper.PruneBidHistories(ctx)
+	am.keeper.RefundExpiredOffers(ctx)
+	am.keeper.CancelExpiredSwaps(ctx)
+	am.keeper.ExpireRentals(ctx)
 	return []abci.ValidatorUpdate{}
 }
 
@@ -72,6 +72,19 @@ func HandleMsgTransferNFTMarketplace(ctx sdk.Context, msg nft.MsgTransferNFT, nf
 
 // HandleMsgMintNFTMarketplace handles MsgMintNFT
 func HandleMsgMintNFTMarketplace(ctx sdk.Context, msg nft.MsgMintNFT, nftKeeper *nft.Keeper, mpKeeper *Keeper) sdk.Result {
+	return mintNFT(ctx, msg, sdk.ZeroDec(), nftKeeper, mpKeeper)
+}
+
+func handleMsgMintNFTWithRoyalty(ctx sdk.Context, mpKeeper *Keeper, msg MsgMintNFTWithRoyalty) sdk.Result {
+	if msg.Royalty.GT(mpKeeper.GetParams(ctx).MaxRoyalty) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to MintNFT: royalty is too high")).Result()
+	}
+	return mintNFT(ctx, msg.MintMsg(), msg.Royalty, mpKeeper.nftKeeper, mpKeeper)
+}
+
+// mintNFT mints the NFT in the nft module and registers it in the marketplace,
+// the sender of the message becomes the creator of the NFT.
+func mintNFT(ctx sdk.Context, msg nft.MsgMintNFT, royalty sdk.Dec, nftKeeper *nft.Keeper, mpKeeper *Keeper) sdk.Result {
 	mpKeeper.increaseCounter(common.PrometheusValueReceived, common.PrometheusValueMsgMintNFT)
 
 	deletedStore := ctx.KVStore(mpKeeper.deletedStoreKey)
@@ -89,9 +102,11 @@ func HandleMsgMintNFTMarketplace(ctx sdk.Context, msg nft.MsgMintNFT, nftKeeper
 		return res
 	}
 
-	mpNFToken := NewNFT(msg.ID, msg.Denom, msg.Recipient, sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(0))))
+	mpNFToken := NewNFT(msg.ID, msg.Denom, msg.Recipient, sdk.NewCoins(sdk.NewCoin(types.DefaultTokenDenom, sdk.NewInt(0))), ctx.BlockHeader().Time)
+	mpNFToken.Creator = msg.Sender
+	mpNFToken.Royalty = royalty
 	if err := mpKeeper.MintNFT(ctx, mpNFToken); err != nil {
-		sdk.ErrUnknownRequest(err.Error()).Result()
+		return sdk.ErrUnknownRequest(err.Error()).Result()
 	}
 
 	mpKeeper.increaseCounter(common.PrometheusValueAccepted, common.PrometheusValueMsgMintNFT)
@@ -104,10 +119,14 @@ func HandleMsgBurnNFTMarketplace(ctx sdk.Context, msg nft.MsgBurnNFT, nftKeeper
 	if err != nil {
 		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BurnNFT: no token with ID %s", msg.ID)).Result()
 	}
-	for _, offer := range token.Offers {
-		if _, err := mpKeeper.coinKeeper.AddCoins(ctx, offer.Buyer, offer.Price); err != nil {
+	if token.IsLocked() {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("failed to BurnNFT: token %s is %s", msg.ID, token.Status)).Result()
+	}
+	for _, offer := range mpKeeper.GetOffers(ctx, msg.ID) {
+		if err := mpKeeper.UnlockCoins(ctx, offer.Buyer, offer.Price); err != nil {
 			return wrapError("failed to BurnNFT", err)
 		}
+		mpKeeper.DeleteOffer(ctx, offer)
 	}
 	res := nft.HandleMsgBurnNFT(ctx, msg, *nftKeeper)
 	if !res.IsOK() {
@@ -0,0 +1,141 @@
+package marketplace_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestOfferExpiry(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, first, second := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx.WithBlockHeight(10)
+
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+	require.True(t, result.IsOK())
+
+	before := getBalances(mpKeeperTest, first, second)
+	now := ctx.BlockHeader().Time
+	offer := func(buyer sdk.AccAddress, amount int64, expirationTime time.Time, expirationHeight int64) sdk.Result {
+		msg := types.NewMsgMakeOffer(buyer, owner, coins(amount), mintMsg.ID, defaultCommission)
+		msg.ExpirationTime, msg.ExpirationHeight = expirationTime, expirationHeight
+		return handler(ctx, *msg)
+	}
+	refundedOffers := func(ctx sdk.Context) []string {
+		var refunded []string
+		for _, event := range ctx.EventManager().Events() {
+			if event.Type != types.EventTypeRefundOffer {
+				continue
+			}
+			for _, attr := range event.Attributes {
+				if string(attr.Key) == types.AttributeKeyOfferID {
+					refunded = append(refunded, string(attr.Value))
+				}
+			}
+		}
+		return refunded
+	}
+	requireOffers := func(count int) {
+		require.Len(t, keeper.GetOffers(ctx, mintMsg.ID), count)
+
+		msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+		require.False(t, broken, msgInv)
+	}
+
+	// an offer that has already expired is rejected
+	require.False(t, offer(first, 100, now, 0).IsOK())
+	require.False(t, offer(first, 100, time.Time{}, 10).IsOK())
+
+	result = offer(first, 100, now.Add(time.Hour), 0)
+	require.True(t, result.IsOK(), result.Log)
+	timeOfferID := string(result.Data)
+	result = offer(second, 200, time.Time{}, 20)
+	require.True(t, result.IsOK(), result.Log)
+	heightOfferID := string(result.Data)
+	result = offer(second, 300, time.Time{}, 0)
+	require.True(t, result.IsOK(), result.Log)
+	requireOffers(3)
+
+	// nothing is refunded before the expiry
+	keeper.RefundExpiredOffers(ctx)
+	requireOffers(3)
+
+	// an expired offer cannot be accepted and is refunded at the end of the block
+	ctx = ctx.WithBlockTime(now.Add(time.Hour))
+	require.False(t, handler(ctx, *types.NewMsgAcceptOffer(owner, owner, mintMsg.ID, timeOfferID, defaultCommission)).IsOK())
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	keeper.RefundExpiredOffers(ctx)
+	requireOffers(2)
+	require.Equal(t, before[0], getBalances(mpKeeperTest, first)[0])
+
+	require.Equal(t, []string{timeOfferID}, refundedOffers(ctx))
+
+	// the height offer expires at its height, the offer without expiry stays
+	ctx = ctx.WithBlockHeight(20)
+	keeper.RefundExpiredOffers(ctx)
+	requireOffers(1)
+	require.Equal(t, before[1]-300, getBalances(mpKeeperTest, second)[0])
+	require.False(t, handler(ctx, *types.NewMsgAcceptOffer(owner, owner, mintMsg.ID, heightOfferID, defaultCommission)).IsOK())
+
+	// a removed offer leaves no expiry behind
+	result = offer(first, 100, time.Time{}, 30)
+	require.True(t, result.IsOK(), result.Log)
+	result = handler(ctx, *types.NewMsgRemoveOffer(first, mintMsg.ID, string(result.Data)))
+	require.True(t, result.IsOK(), result.Log)
+	ctx = ctx.WithBlockHeight(30).WithEventManager(sdk.NewEventManager())
+	keeper.RefundExpiredOffers(ctx)
+	require.Empty(t, ctx.EventManager().Events())
+	requireOffers(1)
+	require.Equal(t, before[0], getBalances(mpKeeperTest, first)[0])
+
+	// an offer due in both expiry queues is refunded once
+	bothOffer := &types.Offer{ID: keeper.GetNextOfferID(ctx), NFTID: mintMsg.ID, Buyer: first, Price: coins(100),
+		BuyerBeneficiary: first, BeneficiaryCommission: defaultCommission, ExpirationTime: now.Add(2 * time.Hour),
+		ExpirationHeight: 40}
+	require.Nil(t, keeper.LockCoins(ctx, first, bothOffer.Price))
+	keeper.SetOffer(ctx, bothOffer)
+	requireOffers(2)
+
+	var logs bytes.Buffer
+	ctx = ctx.WithBlockHeight(40).WithBlockTime(now.Add(2 * time.Hour)).
+		WithEventManager(sdk.NewEventManager()).WithLogger(log.NewTMLogger(&logs))
+	keeper.RefundExpiredOffers(ctx)
+	require.Empty(t, logs.String())
+	require.Equal(t, []string{bothOffer.ID}, refundedOffers(ctx))
+	requireOffers(1)
+	require.Equal(t, before[0], getBalances(mpKeeperTest, first)[0])
+}
+
+func TestMakeOfferExpiryValidateBasic(t *testing.T) {
+	denom := types.DefaultTokenDenom
+	addr := sdk.AccAddress([]byte("buyer"))
+	newMsg := func(expirationTime time.Time, expirationHeight int64) *types.MsgMakeOffer {
+		msg := types.NewMsgMakeOffer(addr, addr, sdk.NewCoins(sdk.NewInt64Coin(denom, 100)), "token", defaultCommission)
+		msg.ExpirationTime, msg.ExpirationHeight = expirationTime, expirationHeight
+		return msg
+	}
+
+	require.Nil(t, newMsg(time.Time{}, 0).ValidateBasic())
+	require.Nil(t, newMsg(time.Now(), 0).ValidateBasic())
+	require.Nil(t, newMsg(time.Time{}, 100).ValidateBasic())
+	require.NotNil(t, newMsg(time.Time{}, -1).ValidateBasic())
+	require.NotNil(t, newMsg(time.Now(), 100).ValidateBasic())
+}
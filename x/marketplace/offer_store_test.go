@@ -0,0 +1,177 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestOfferQueries(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	owner, first, second := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	ctx := mpKeeperTest.ctx
+
+	var tokenIDs []string
+	for i := 0; i < 2; i++ {
+		msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+		result := marketplace.HandleMsgMintNFTMarketplace(ctx, msg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+		require.True(t, result.IsOK())
+		tokenIDs = append(tokenIDs, msg.ID)
+	}
+
+	offer := func(buyer sdk.AccAddress, tokenID string) string {
+		result := handler(ctx, *types.NewMsgMakeOffer(buyer, owner, coins(10), tokenID, defaultCommission))
+		require.True(t, result.IsOK(), result.Log)
+		return string(result.Data)
+	}
+	query := func(path ...string) []string {
+		bz, sdkErr := querier(ctx, path, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		var res types.QueryResOffers
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		var ids []string
+		for _, offer := range res.Offers {
+			ids = append(ids, offer.NFTID+"/"+offer.ID)
+		}
+		return ids
+	}
+
+	firstOffer := offer(first, tokenIDs[0])
+	secondOffer := offer(second, tokenIDs[0])
+	otherOffer := offer(first, tokenIDs[1])
+
+	require.ElementsMatch(t, []string{tokenIDs[0] + "/" + firstOffer, tokenIDs[0] + "/" + secondOffer},
+		query(marketplace.QueryOffers, tokenIDs[0]))
+	require.ElementsMatch(t, []string{tokenIDs[0] + "/" + firstOffer, tokenIDs[1] + "/" + otherOffer},
+		query(marketplace.QueryBuyerOffers, first.String()))
+
+	// the NFT record does not hold the offers
+	bz, sdkErr := querier(ctx, []string{marketplace.QueryNFT, tokenIDs[0]}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	require.NotContains(t, string(bz), "offers")
+
+	// only the buyer removes an offer, the indexes follow
+	require.False(t, handler(ctx, *types.NewMsgRemoveOffer(second, tokenIDs[0], firstOffer)).IsOK())
+	result := handler(ctx, *types.NewMsgRemoveOffer(first, tokenIDs[0], firstOffer))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, []string{tokenIDs[0] + "/" + secondOffer}, query(marketplace.QueryOffers, tokenIDs[0]))
+	require.Equal(t, []string{tokenIDs[1] + "/" + otherOffer}, query(marketplace.QueryBuyerOffers, first.String()))
+
+	_, sdkErr = querier(ctx, []string{marketplace.QueryBuyerOffers, "invalid"}, abci.RequestQuery{})
+	require.NotNil(t, sdkErr)
+
+	msgInv, broken := marketplace.EscrowInvariant(mpKeeperTest.marketKeeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestMigrateOffers(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(sdk.NewCoins(sdk.NewInt64Coin(denom, 1000))))
+
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+	owner, buyer := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	expiration := ctx.BlockHeader().Time.Add(time.Hour)
+
+	// a record exported before offers moved to their own store
+	record := types.NewNFT(uuid.New().String(), denom, owner, sdk.Coins{}, ctx.BlockHeader().Time)
+	record.Offers = []*types.Offer{
+		{ID: "1", Buyer: buyer, Price: sdk.NewCoins(sdk.NewInt64Coin(denom, 10)), BeneficiaryCommission: sdk.ZeroDec()},
+		{ID: "2", Buyer: buyer, Price: sdk.NewCoins(sdk.NewInt64Coin(denom, 20)), BeneficiaryCommission: sdk.ZeroDec(),
+			ExpirationTime: expiration},
+	}
+	// the older version took the coins of offers and bids with SubtractCoins, the module account lacks them
+	_, err = mpKeeperTest.bankKeeper.SubtractCoins(ctx, buyer, sdk.NewCoins(sdk.NewInt64Coin(denom, 30)))
+	require.Nil(t, err)
+	bidder := mpKeeperTest.addrs[2]
+	lotID := mintNFT(t, mpKeeperTest, owner)
+	handler := marketplace.NewHandler(keeper)
+	require.True(t, handler(ctx, *types.NewMsgPutNFTOnAuction(owner, owner, lotID, coins(100), sdk.Coins{}, expiration)).IsOK())
+	require.True(t, handler(ctx, *types.NewMsgMakeBidOnAuction(bidder, owner, lotID, coins(100), defaultCommission)).IsOK())
+	_, err = mpKeeperTest.bankKeeper.SubtractCoins(ctx, supply.NewModuleAddress(types.ModuleName), coins(100))
+	require.Nil(t, err)
+
+	genesis := marketplace.DefaultGenesisState()
+	genesis.NFTRecords = []*types.NFT{record}
+	genesis.OfferSequence = 3
+	marketplace.InitGenesis(ctx, keeper, genesis)
+
+	token, err := keeper.GetNFT(ctx, record.ID)
+	require.Nil(t, err)
+	require.Empty(t, token.Offers)
+	require.Len(t, keeper.GetOffers(ctx, record.ID), 2)
+	require.Len(t, keeper.GetOffersByBuyer(ctx, buyer), 2)
+	offer, err := keeper.GetOffer(ctx, record.ID, "2")
+	require.Nil(t, err)
+	require.Equal(t, record.ID, offer.NFTID)
+
+	exported := marketplace.ExportGenesis(ctx, keeper)
+	require.Len(t, exported.Offers, 2)
+	require.Empty(t, exported.NFTRecords[0].Offers)
+
+	msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+
+	// the expiry index is rebuilt for the migrated offers, the legacy coins are refunded from the module account
+	keeper.RefundExpiredOffers(ctx.WithBlockTime(expiration))
+	require.Len(t, keeper.GetOffers(ctx, record.ID), 1)
+	require.Equal(t, []int64{990}, getBalances(mpKeeperTest, buyer))
+	require.True(t, handler(ctx, *types.NewMsgRemoveNFTFromAuction(owner, lotID)).IsOK())
+	require.Equal(t, []int64{1000}, getBalances(mpKeeperTest, bidder))
+
+	msgInv, broken = marketplace.EscrowInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestAcceptOfferNotOwner(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	owner, buyer, stranger := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	ctx := mpKeeperTest.ctx
+
+	tokenID := mintNFT(t, mpKeeperTest, owner)
+	result := handler(ctx, *types.NewMsgMakeOffer(buyer, owner, coins(10), tokenID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	offerID := string(result.Data)
+
+	// only the owner of the NFT accepts an offer for it
+	result = handler(ctx, *types.NewMsgAcceptOffer(stranger, stranger, tokenID, offerID, defaultCommission))
+	require.False(t, result.IsOK())
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusDefault, tokenID)
+	require.Len(t, mpKeeperTest.marketKeeper.GetOffers(ctx, tokenID), 1)
+	require.Equal(t, []int64{1000, 990, 1000}, getBalances(mpKeeperTest, owner, buyer, stranger))
+
+	result = handler(ctx, *types.NewMsgAcceptOffer(owner, owner, tokenID, offerID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(t, mpKeeperTest, buyer, types.NFTStatusDefault, tokenID)
+
+	msgInv, broken := marketplace.EscrowInvariant(mpKeeperTest.marketKeeper)(ctx)
+	require.False(t, broken, msgInv)
+}
@@ -0,0 +1,48 @@
+package marketplace_test
+
+import (
+	"testing"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeOfferIDs(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	owner, buyer := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+
+	var tokenIDs []string
+	for i := 0; i < 2; i++ {
+		msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+		result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, msg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+		require.True(t, result.IsOK())
+		tokenIDs = append(tokenIDs, msg.ID)
+	}
+
+	// offer IDs come from a single sequence shared by all NFTs
+	price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(10)))
+	expectedIDs := []string{"1", "2", "3"}
+	for i, expectedID := range expectedIDs {
+		msg := types.NewMsgMakeOffer(buyer, mpKeeperTest.addrs[2], price, tokenIDs[i%2], defaultCommission)
+		result := handler(mpKeeperTest.ctx, *msg)
+		require.True(t, result.IsOK(), result.Log)
+		require.Equal(t, expectedID, string(result.Data))
+
+		_, err := mpKeeperTest.marketKeeper.GetOffer(mpKeeperTest.ctx, tokenIDs[i%2], expectedID)
+		require.Nil(t, err)
+	}
+	require.Equal(t, uint64(4), mpKeeperTest.marketKeeper.GetOfferSequence(mpKeeperTest.ctx))
+}
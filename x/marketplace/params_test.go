@@ -0,0 +1,149 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestQueryParams(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	params := types.DefaultParams()
+	params.MaxOffersPerNFT = 7
+	mpKeeperTest.marketKeeper.SetParams(mpKeeperTest.ctx, params)
+
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	bz, sdkErr := querier(mpKeeperTest.ctx, []string{marketplace.QueryParams}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+
+	var res types.Params
+	types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+	require.Equal(t, params, res)
+}
+
+func TestValidateGenesisParams(t *testing.T) {
+	require.Nil(t, marketplace.ValidateGenesis(marketplace.DefaultGenesisState()))
+
+	for name, update := range map[string]func(p *types.Params){
+		"negative validators commission": func(p *types.Params) { p.ValidatorsCommission = sdk.NewDec(-1) },
+		"default above maximum":          func(p *types.Params) { p.BeneficiariesCommission = sdk.NewDecWithPrec(6, 2) },
+		"commissions above one":          func(p *types.Params) { p.MaxBeneficiaryCommission = sdk.OneDec() },
+		"no offers allowed":              func(p *types.Params) { p.MaxOffersPerNFT = 0 },
+		"max duration below min":         func(p *types.Params) { p.MaxAuctionDuration = p.MinAuctionDuration - 1 },
+		"forfeit above one":              func(p *types.Params) { p.UnrevealedBidForfeit = sdk.NewDec(2) },
+	} {
+		genesis := marketplace.DefaultGenesisState()
+		update(&genesis.Params)
+		require.NotNil(t, marketplace.ValidateGenesis(genesis), name)
+	}
+}
+
+func TestMaxOffersPerNFT(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	params := types.DefaultParams()
+	params.MaxOffersPerNFT = 2
+	mpKeeperTest.marketKeeper.SetParams(mpKeeperTest.ctx, params)
+
+	owner, buyer := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	require.True(t, result.IsOK())
+
+	price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(10)))
+	var offerIDs []string
+	for i := 0; i < 2; i++ {
+		result = handler(mpKeeperTest.ctx, *types.NewMsgMakeOffer(buyer, owner, price, mintMsg.ID, defaultCommission))
+		require.True(t, result.IsOK(), result.Log)
+		offerIDs = append(offerIDs, string(result.Data))
+	}
+	result = handler(mpKeeperTest.ctx, *types.NewMsgMakeOffer(buyer, owner, price, mintMsg.ID, defaultCommission))
+	require.False(t, result.IsOK())
+
+	// removing an offer makes room for a new one
+	result = handler(mpKeeperTest.ctx, *types.NewMsgRemoveOffer(buyer, mintMsg.ID, offerIDs[0]))
+	require.True(t, result.IsOK(), result.Log)
+	result = handler(mpKeeperTest.ctx, *types.NewMsgMakeOffer(buyer, owner, price, mintMsg.ID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+}
+
+func TestAuctionDurationBounds(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	params := types.DefaultParams()
+	params.MinAuctionDuration = time.Hour
+	params.MaxAuctionDuration = 24 * time.Hour
+	mpKeeperTest.marketKeeper.SetParams(mpKeeperTest.ctx, params)
+
+	owner := mpKeeperTest.addrs[0]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	require.True(t, result.IsOK())
+
+	blockTime := mpKeeperTest.ctx.BlockHeader().Time
+	openingPrice := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(100)))
+	putOnAuction := func(duration time.Duration) sdk.Result {
+		return handler(mpKeeperTest.ctx, *types.NewMsgPutNFTOnAuction(owner, owner, mintMsg.ID,
+			openingPrice, sdk.Coins{}, blockTime.Add(duration)))
+	}
+
+	require.False(t, putOnAuction(time.Hour-time.Second).IsOK())
+	require.False(t, putOnAuction(24*time.Hour+time.Second).IsOK())
+	result = putOnAuction(24 * time.Hour)
+	require.True(t, result.IsOK(), result.Log)
+}
+
+func TestParamChangeProposal(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	ctx := mpKeeperTest.ctx
+	keeper := mpKeeperTest.marketKeeper
+	handler := marketplace.NewParamChangeProposalHandler(keeper, mpKeeperTest.paramsKeeper)
+	proposal := func(key []byte, value interface{}) params.ParameterChangeProposal {
+		return params.NewParameterChangeProposal("marketplace params", "change marketplace params", []params.ParamChange{
+			params.NewParamChange(types.DefaultParamspace, string(key), string(types.ModuleCdc.MustMarshalJSON(value))),
+		})
+	}
+
+	// changes that leave the params invalid are rejected and not applied
+	for name, p := range map[string]params.ParameterChangeProposal{
+		"negative validators commission":  proposal(types.KeyValidatorsCommission, sdk.NewDecWithPrec(-5, 1)),
+		"validators commission above one": proposal(types.KeyValidatorsCommission, sdk.NewDec(2)),
+		"no offers allowed":               proposal(types.KeyMaxOffersPerNFT, uint64(0)),
+	} {
+		require.NotNil(t, handler(ctx, p), name)
+		require.Equal(t, types.DefaultParams(), keeper.GetParams(ctx), name)
+	}
+
+	require.Nil(t, handler(ctx, proposal(types.KeyMaxOffersPerNFT, uint64(7))))
+	require.Equal(t, uint64(7), keeper.GetParams(ctx).MaxOffersPerNFT)
+}
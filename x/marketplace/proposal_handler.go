@@ -0,0 +1,40 @@
+package marketplace
+
+import (
+	"fmt"
+
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+)
+
+// NewParamChangeProposalHandler returns a governance handler of parameter change proposals that rejects
+// the proposal if it leaves the marketplace parameters invalid. The params module stores the new values
+// as they are, so the marketplace parameters are validated as a whole once the changes are applied.
+func NewParamChangeProposalHandler(k *Keeper, paramsKeeper params.Keeper) govtypes.Handler {
+	handler := params.NewParamChangeProposalHandler(paramsKeeper)
+	return func(ctx sdk.Context, content govtypes.Content) sdk.Error {
+		proposal, ok := content.(params.ParameterChangeProposal)
+		if !ok {
+			return handler(ctx, content)
+		}
+
+		cacheCtx, writeCache := ctx.CacheContext()
+		if err := handler(cacheCtx, content); err != nil {
+			return err
+		}
+		for _, change := range proposal.Changes {
+			if change.Subspace != types.DefaultParamspace {
+				continue
+			}
+			if err := types.ValidateParams(k.GetParams(cacheCtx)); err != nil {
+				return sdk.ErrUnknownRequest(fmt.Sprintf("invalid marketplace parameter change: %v", err))
+			}
+			break
+		}
+
+		writeCache()
+		return nil
+	}
+}
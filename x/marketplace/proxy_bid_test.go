@@ -0,0 +1,132 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestProxyBid(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, first, manual, second := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+	require.True(t, result.IsOK())
+
+	start := ctx.BlockHeader().Time
+	msg := types.NewMsgPutNFTOnAuction(owner, owner, mintMsg.ID, coins(100), sdk.Coins{}, start.Add(time.Hour))
+	msg.MinBidIncrement = coins(10)
+	result = handler(ctx, *msg)
+	require.True(t, result.IsOK(), result.Log)
+
+	before := getBalances(mpKeeperTest, first, manual, second)
+	bid := func(bidder sdk.AccAddress, amount int64, proxy bool) sdk.Result {
+		if proxy {
+			return handler(ctx, *types.NewMsgMakeProxyBidOnAuction(bidder, owner, mintMsg.ID, coins(amount), defaultCommission))
+		}
+		return handler(ctx, *types.NewMsgMakeBidOnAuction(bidder, owner, mintMsg.ID, coins(amount), defaultCommission))
+	}
+	requireLastBid := func(bidder sdk.AccAddress, amount int64) {
+		bz, sdkErr := querier(ctx, []string{marketplace.QueryAuctionLot, mintMsg.ID}, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		require.NotContains(t, string(bz), "max_bid")
+		var lot types.AuctionLot
+		types.ModuleCdc.MustUnmarshalJSON(bz, &lot)
+		require.True(t, lot.LastBid.Bidder.Equals(bidder))
+		require.Equal(t, coins(amount), lot.LastBid.Bid)
+
+		msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+		require.False(t, broken, msgInv)
+	}
+
+	// the proxy bid starts at the opening price and locks the whole maximum
+	result = bid(first, 300, true)
+	require.True(t, result.IsOK(), result.Log)
+	requireLastBid(first, 100)
+	require.Equal(t, before[0]-300, getBalances(mpKeeperTest, first)[0])
+
+	// a manual bid below the maximum is raised over by one step
+	result = bid(manual, 150, false)
+	require.True(t, result.IsOK(), result.Log)
+	requireLastBid(first, 160)
+	require.Equal(t, before[1], getBalances(mpKeeperTest, manual)[0])
+
+	// proxy against proxy in one transaction, the lower maximum loses
+	result = bid(second, 250, true)
+	require.True(t, result.IsOK(), result.Log)
+	requireLastBid(first, 260)
+
+	result = bid(second, 400, true)
+	require.True(t, result.IsOK(), result.Log)
+	requireLastBid(second, 310)
+	require.Equal(t, before[0], getBalances(mpKeeperTest, first)[0])
+	require.Equal(t, before[2]-400, getBalances(mpKeeperTest, second)[0])
+
+	// an equal maximum loses to the earlier bid
+	result = bid(first, 400, true)
+	require.True(t, result.IsOK(), result.Log)
+	requireLastBid(second, 400)
+
+	// raising the own maximum keeps the visible bid, lowering it fails
+	result = bid(second, 500, true)
+	require.True(t, result.IsOK(), result.Log)
+	requireLastBid(second, 400)
+	result = bid(second, 450, true)
+	require.False(t, result.IsOK())
+
+	bz, sdkErr := querier(ctx, []string{marketplace.QueryAuctionBids, mintMsg.ID}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	var res types.QueryResAuctionBids
+	types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+	var history []int64
+	for _, b := range res.Bids {
+		history = append(history, b.Bid.AmountOf(denom).Int64())
+	}
+	require.Equal(t, []int64{100, 150, 160, 250, 260, 310, 400, 400, 400}, history)
+
+	// the winner pays the final price and gets the rest of the maximum back
+	ctx = ctx.WithBlockTime(start.Add(2 * time.Hour))
+	keeper.CheckFinishedAuctions(ctx)
+
+	token, err := keeper.GetNFT(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(second))
+	require.Equal(t, before[:2], getBalances(mpKeeperTest, first, manual))
+	require.Equal(t, before[2]-400, getBalances(mpKeeperTest, second)[0])
+
+	msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestMakeProxyBidValidateBasic(t *testing.T) {
+	denom := types.DefaultTokenDenom
+	addr := sdk.AccAddress([]byte("bidder"))
+
+	require.Nil(t, types.NewMsgMakeProxyBidOnAuction(addr, addr, "token", coins(100), defaultCommission).ValidateBasic())
+
+	withBid := types.NewMsgMakeProxyBidOnAuction(addr, addr, "token", coins(100), defaultCommission)
+	withBid.Bid = coins(50)
+	require.NotNil(t, withBid.ValidateBasic())
+
+	invalid := types.NewMsgMakeProxyBidOnAuction(addr, addr, "token", sdk.Coins{sdk.NewInt64Coin(denom, 0)}, defaultCommission)
+	require.NotNil(t, invalid.ValidateBasic())
+}
@@ -2,6 +2,7 @@ package marketplace
 
 import (
 	"fmt"
+	"strconv"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/modules/incubator/nft"
@@ -11,12 +12,26 @@ import (
 
 // query endpoints supported by the marketplace Querier
 const (
-	QueryNFT            = "nft"
-	QueryNFTs           = "nfts"
-	QueryFungibleToken  = "fungible_token"
-	QueryFungibleTokens = "fungible_tokens"
-	QueryAuctionLot     = "auction_lot"
-	QueryAuctionLots    = "auction_lots"
+	QueryNFT              = "nft"
+	QueryNFTs             = "nfts"
+	QueryFungibleToken    = "fungible_token"
+	QueryFungibleTokens   = "fungible_tokens"
+	QueryAuctionLot       = "auction_lot"
+	QueryAuctionLots      = "auction_lots"
+	QueryAuctionPrice     = "auction_price"
+	QueryAuctionBids      = "auction_bids"
+	QueryOffers           = "offers"
+	QueryBuyerOffers      = "buyer_offers"
+	QueryCollectionOffers = "collection_offers"
+	QuerySwap             = "swap"
+	QuerySwaps            = "swaps"
+	QueryHashLock         = "hash_lock"
+	QueryRentalListing    = "rental_listing"
+	QueryNFTUser          = "nft_user"
+	QueryRentalHistory    = "rental_history"
+	QueryLoan             = "loan"
+	QueryLoans            = "loans"
+	QueryParams           = "params"
 )
 
 // NewQuerier is the module level router for state queries
@@ -35,6 +50,34 @@ func NewQuerier(keeper *Keeper, nftKeeper *nft.Keeper) sdk.Querier {
 			return queryAuctionLot(ctx, path[1:], req, keeper)
 		case QueryAuctionLots:
 			return queryAuctionLots(ctx, req, keeper)
+		case QueryAuctionPrice:
+			return queryAuctionPrice(ctx, path[1:], keeper)
+		case QueryAuctionBids:
+			return queryAuctionBids(ctx, path[1:], keeper)
+		case QueryOffers:
+			return queryOffers(ctx, path[1:], keeper)
+		case QueryBuyerOffers:
+			return queryBuyerOffers(ctx, path[1:], keeper)
+		case QueryCollectionOffers:
+			return queryCollectionOffers(ctx, path[1:], keeper)
+		case QuerySwap:
+			return querySwap(ctx, path[1:], keeper)
+		case QuerySwaps:
+			return querySwaps(ctx, path[1:], keeper)
+		case QueryHashLock:
+			return queryHashLock(ctx, path[1:], keeper)
+		case QueryRentalListing:
+			return queryRentalListing(ctx, path[1:], keeper)
+		case QueryNFTUser:
+			return queryNFTUser(ctx, path[1:], keeper)
+		case QueryRentalHistory:
+			return queryRentalHistory(ctx, path[1:], keeper)
+		case QueryLoan:
+			return queryLoan(ctx, path[1:], keeper)
+		case QueryLoans:
+			return queryLoans(ctx, path[1:], keeper)
+		case QueryParams:
+			return queryParams(ctx, keeper)
 		default:
 			return nil, sdk.ErrUnknownRequest("unknown marketplace query endpoint")
 		}
@@ -49,36 +92,58 @@ func queryNFT(ctx sdk.Context, path []string, req abci.RequestQuery, keeper *Kee
 		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find NFT in mpKeeper with id %s: %v", id, err))
 	}
 
-	token, err := nftKeeper.GetNFT(ctx, nftMp.Denom, nftMp.ID)
+	value, err := getNFTInfo(ctx, nftKeeper, nftMp)
 	if err != nil {
 		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find NFT in NFTKeeper with id %s: %v", id, err))
 	}
 
-	value := types.NewNFTInfo(nftMp, token)
 	bz := keeper.cdc.MustMarshalJSON(value)
 	return bz, nil
 }
 
 func queryNFTs(ctx sdk.Context, req abci.RequestQuery, keeper *Keeper, nftKeeper *nft.Keeper) ([]byte, sdk.Error) {
-	var (
-		nfts     types.QueryResNFTs
-		iterator = keeper.GetNFTsIterator(ctx)
-	)
-	defer iterator.Close()
-	for ; iterator.Valid(); iterator.Next() {
-		var nftMp types.NFT
-		keeper.cdc.MustUnmarshalJSON(iterator.Value(), &nftMp)
-		token, err := nftKeeper.GetNFT(ctx, nftMp.Denom, nftMp.ID)
+	var params types.QueryNFTsParams
+	if len(req.Data) != 0 {
+		if err := keeper.cdc.UnmarshalJSON(req.Data, &params); err != nil {
+			return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("failed to parse params: %v", err))
+		}
+	}
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.Limit == 0 {
+		params.Limit = types.DefaultQueryNFTsLimit
+	}
+	if err := params.Validate(); err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("invalid params: %v", err))
+	}
+
+	var nfts types.QueryResNFTs
+	page, total := keeper.GetFilteredNFTs(ctx, params)
+	for _, nftMp := range page {
+		info, err := getNFTInfo(ctx, nftKeeper, nftMp)
 		if err != nil {
 			return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find NFT in NFTKeeper with id %s: %v", nftMp.ID, err))
 		}
-		value := types.NewNFTInfo(&nftMp, token)
-		nfts.NFTs = append(nfts.NFTs, value)
+		nfts.NFTs = append(nfts.NFTs, info)
 	}
+	nfts.Total = total
 
 	return keeper.cdc.MustMarshalJSON(nfts), nil
 }
 
+// getNFTInfo returns the NFT with its metadata from the nft module, a bundle has no metadata.
+func getNFTInfo(ctx sdk.Context, nftKeeper *nft.Keeper, nftMp *NFT) (*types.NFTInfo, error) {
+	if nftMp.IsBundle() {
+		return &types.NFTInfo{MPNFTInfo: nftMp}, nil
+	}
+	token, err := nftKeeper.GetNFT(ctx, nftMp.Denom, nftMp.ID)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewNFTInfo(nftMp, token), nil
+}
+
 func queryFungibleToken(ctx sdk.Context, path []string, req abci.RequestQuery, keeper *Keeper) ([]byte, sdk.Error) {
 	name := path[0]
 	value, err := keeper.GetFungibleToken(ctx, name)
@@ -115,6 +180,34 @@ func queryAuctionLot(ctx sdk.Context, path []string, req abci.RequestQuery, keep
 	return bz, nil
 }
 
+// queryAuctionPrice returns the current price of a Dutch auction lot, or the last bid or
+// opening price of other lots, at the time of the last block
+func queryAuctionPrice(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	lot, err := keeper.GetAuctionLot(ctx, id)
+	if err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find AuctionLot with id %s: %v", id, err))
+	}
+
+	return keeper.cdc.MustMarshalJSON(lot.CurrentPrice(ctx.BlockHeader().Time)), nil
+}
+
+// queryAuctionBids returns the bid history of the auction of the NFT with the number given after the ID,
+// of the current or the last finished auction of the NFT if no number is given
+func queryAuctionBids(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	auction := keeper.GetLatestAuction(ctx, id)
+	if len(path) > 1 {
+		var err error
+		if auction, err = strconv.ParseUint(path[1], 10, 64); err != nil {
+			return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("invalid auction number %s: %v", path[1], err))
+		}
+	}
+
+	res := types.QueryResAuctionBids{Auction: auction, Bids: keeper.GetBidHistory(ctx, id, auction)}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
 func queryAuctionLots(ctx sdk.Context, req abci.RequestQuery, keeper *Keeper) ([]byte, sdk.Error) {
 	var (
 		lots     types.QueryResAuctionLots
@@ -129,3 +222,121 @@ func queryAuctionLots(ctx sdk.Context, req abci.RequestQuery, keeper *Keeper) ([
 
 	return keeper.cdc.MustMarshalJSON(lots), nil
 }
+
+// queryOffers returns the open offers for the NFT
+func queryOffers(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	res := types.QueryResOffers{Offers: keeper.GetOffers(ctx, id)}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+// queryBuyerOffers returns the open offers of the buyer
+func queryBuyerOffers(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	buyer, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return []byte{}, sdk.ErrInvalidAddress(fmt.Sprintf("failed to parse buyer address: %v", err))
+	}
+
+	res := types.QueryResOffers{Offers: keeper.GetOffersByBuyer(ctx, buyer)}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+// queryCollectionOffers returns the collection offers for the denom, highest price first
+func queryCollectionOffers(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	denom := path[0]
+	res := types.QueryResCollectionOffers{Offers: keeper.GetCollectionOffers(ctx, denom)}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+// querySwap returns the swap with the given ID
+func querySwap(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	swap, err := keeper.GetSwap(ctx, id)
+	if err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find Swap with id %s: %v", id, err))
+	}
+
+	return keeper.cdc.MustMarshalJSON(swap), nil
+}
+
+// querySwaps returns the open swaps the address is the proposer or the counterparty of
+func querySwaps(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	party, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return []byte{}, sdk.ErrInvalidAddress(fmt.Sprintf("failed to parse address: %v", err))
+	}
+
+	res := types.QueryResSwaps{Swaps: keeper.GetSwapsByParty(ctx, party)}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+// queryHashLock returns the hash lock of the NFT
+func queryHashLock(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	lock, err := keeper.GetHashLock(ctx, id)
+	if err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find HashLock with id %s: %v", id, err))
+	}
+
+	return keeper.cdc.MustMarshalJSON(lock), nil
+}
+
+// queryRentalListing returns the rental listing of the NFT
+func queryRentalListing(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	listing, err := keeper.GetRentalListing(ctx, id)
+	if err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find RentalListing with id %s: %v", id, err))
+	}
+
+	return keeper.cdc.MustMarshalJSON(listing), nil
+}
+
+// queryNFTUser returns the current user of the NFT, the user is empty if the NFT is not rented
+func queryNFTUser(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	token, err := keeper.GetNFT(ctx, id)
+	if err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find NFT with id %s: %v", id, err))
+	}
+
+	res := types.QueryResNFTUser{
+		NFTID:            id,
+		User:             token.User,
+		ExpirationTime:   token.UserExpirationTime,
+		ExpirationHeight: token.UserExpirationHeight,
+	}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+// queryRentalHistory returns the rentals of the NFT in the order they were made
+func queryRentalHistory(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	res := types.QueryResRentals{Rentals: keeper.GetRentalHistory(ctx, path[0])}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+// queryLoan returns the loan with the given ID
+func queryLoan(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	id := path[0]
+	loan, err := keeper.GetLoan(ctx, id)
+	if err != nil {
+		return []byte{}, sdk.ErrUnknownRequest(fmt.Sprintf("could not find Loan with id %s: %v", id, err))
+	}
+
+	return keeper.cdc.MustMarshalJSON(loan), nil
+}
+
+// queryLoans returns the open loans the address is the borrower or the lender of
+func queryLoans(ctx sdk.Context, path []string, keeper *Keeper) ([]byte, sdk.Error) {
+	party, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return []byte{}, sdk.ErrInvalidAddress(fmt.Sprintf("failed to parse address: %v", err))
+	}
+
+	res := types.QueryResLoans{Loans: keeper.GetLoansByParty(ctx, party)}
+	return keeper.cdc.MustMarshalJSON(res), nil
+}
+
+func queryParams(ctx sdk.Context, keeper *Keeper) ([]byte, sdk.Error) {
+	return keeper.cdc.MustMarshalJSON(keeper.GetParams(ctx)), nil
+}
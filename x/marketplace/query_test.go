@@ -0,0 +1,128 @@
+package marketplace_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func queryNFTs(t *testing.T, mp *marketplaceKeeperTest, params types.QueryNFTsParams) types.QueryResNFTs {
+	querier := marketplace.NewQuerier(mp.marketKeeper, mp.nftKeeper)
+	bz, err := querier(mp.ctx, []string{marketplace.QueryNFTs},
+		abci.RequestQuery{Data: types.ModuleCdc.MustMarshalJSON(params)})
+	require.Nil(t, err)
+
+	var res types.QueryResNFTs
+	types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+	return res
+}
+
+func nftIDs(res types.QueryResNFTs) []string {
+	var ids []string
+	for _, info := range res.NFTs {
+		ids = append(ids, info.MPNFTInfo.ID)
+	}
+	return ids
+}
+
+func TestQueryNFTsFilters(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	owner, other := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	blockTime := mpKeeperTest.ctx.BlockHeader().Time
+
+	// five NFTs of the owner created one second apart, the first three are on the market
+	// for 30, 10 and 20 tokens, and one NFT of another owner and collection
+	var ids []string
+	for i := 0; i < 5; i++ {
+		mpKeeperTest.ctx = mpKeeperTest.ctx.WithBlockTime(blockTime.Add(time.Duration(i) * time.Second))
+		msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), "art", "")
+		require.True(t, marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, msg, mpKeeperTest.nftKeeper,
+			mpKeeperTest.marketKeeper).IsOK())
+		ids = append(ids, msg.ID)
+	}
+	for i, amount := range []int64{30, 10, 20} {
+		price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(amount)))
+		result := handler(mpKeeperTest.ctx, *types.NewMsgPutOnMarketNFT(owner, owner, ids[i], price))
+		require.True(t, result.IsOK(), result.Log)
+	}
+	otherMsg := nft.NewMsgMintNFT(other, other, uuid.New().String(), "music", "")
+	require.True(t, marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, otherMsg, mpKeeperTest.nftKeeper,
+		mpKeeperTest.marketKeeper).IsOK())
+
+	res := queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{})
+	require.Equal(t, 6, res.Total)
+	require.ElementsMatch(t, append(ids, otherMsg.ID), nftIDs(res))
+
+	res = queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{Owner: other})
+	require.Equal(t, []string{otherMsg.ID}, nftIDs(res))
+
+	res = queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{Denom: "art"})
+	require.ElementsMatch(t, ids, nftIDs(res))
+
+	res = queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{Status: "on_market", SortBy: types.SortByPrice})
+	require.Equal(t, []string{ids[1], ids[2], ids[0]}, nftIDs(res))
+
+	res = queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{
+		Status:   "on_market",
+		MinPrice: sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(15))),
+		MaxPrice: sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(25))),
+	})
+	require.Equal(t, []string{ids[2]}, nftIDs(res))
+
+	// pages of NFTs sorted by the time of creation
+	var paged []string
+	for page := 1; page <= 3; page++ {
+		res = queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{
+			Page: page, Limit: 2, Owner: owner, SortBy: types.SortByTimeCreated,
+		})
+		require.Equal(t, 5, res.Total)
+		paged = append(paged, nftIDs(res)...)
+	}
+	require.Equal(t, ids, paged)
+
+	res = queryNFTs(t, mpKeeperTest, types.QueryNFTsParams{Page: 4, Limit: 2, Owner: owner})
+	require.Empty(t, res.NFTs)
+	require.Equal(t, 5, res.Total)
+}
+
+func TestQueryNFTsInvalidParams(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	for _, params := range []types.QueryNFTsParams{
+		{Page: -1},
+		{Limit: types.MaxQueryNFTsLimit + 1},
+		{Status: "sold"},
+		{SortBy: "owner"},
+		{Page: math.MaxInt64/types.MaxQueryNFTsLimit + 2, Limit: types.MaxQueryNFTsLimit},
+	} {
+		_, sdkErr := querier(mpKeeperTest.ctx, []string{marketplace.QueryNFTs},
+			abci.RequestQuery{Data: types.ModuleCdc.MustMarshalJSON(params)})
+		require.NotNil(t, sdkErr, "%+v", params)
+	}
+
+	// a page past the last one is empty however large it is
+	nfts := []*types.NFT{types.NewNFT("a", "", nil, nil, time.Now()), types.NewNFT("b", "", nil, nil, time.Now())}
+	require.Len(t, types.QueryNFTsParams{Page: 1, Limit: 1000}.Paginate(nfts), 2)
+	require.Empty(t, types.QueryNFTsParams{Page: 2, Limit: 1000}.Paginate(nfts))
+	require.Empty(t, types.QueryNFTsParams{Page: math.MaxInt64/1000 + 2, Limit: 1000}.Paginate(nfts))
+}
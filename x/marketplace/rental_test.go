@@ -0,0 +1,136 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestRental(t *testing.T) {
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	owner, renter, other := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+	now := ctx.BlockHeader().Time
+
+	queryUser := func(id string) types.QueryResNFTUser {
+		bz, sdkErr := querier(ctx, []string{marketplace.QueryNFTUser, id}, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		var res types.QueryResNFTUser
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		return res
+	}
+	queryHistory := func(id string) []*types.Rental {
+		bz, sdkErr := querier(ctx, []string{marketplace.QueryRentalHistory, id}, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		var res types.QueryResRentals
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		return res.Rentals
+	}
+
+	// only the owner lists, an NFT is rented only while listed and for at most the max duration
+	sword := mintNFT(t, mpKeeperTest, owner)
+	require.False(t, handler(ctx, *types.NewMsgListNFTForRent(other, sword, coins(5), types.RentalUnitHour, 48)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgRentNFT(renter, sword, 10)).IsOK())
+	result := handler(ctx, *types.NewMsgListNFTForRent(owner, sword, coins(5), types.RentalUnitHour, 48))
+	require.True(t, result.IsOK(), result.Log)
+	require.False(t, handler(ctx, *types.NewMsgRentNFT(renter, sword, 49)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgRentNFT(owner, sword, 10)).IsOK())
+
+	// the renter pays the owner and becomes the user, the NFT is locked meanwhile
+	before := getBalances(mpKeeperTest, owner, renter)
+	result = handler(ctx, *types.NewMsgRentNFT(renter, sword, 10))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, []int64{before[0] + 50, before[1] - 50}, getBalances(mpKeeperTest, owner, renter))
+
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusRented, sword)
+	user := queryUser(sword)
+	require.True(t, user.User.Equals(renter))
+	require.True(t, user.ExpirationTime.Equal(now.Add(10*time.Hour)))
+
+	require.False(t, handler(ctx, *types.NewMsgPutOnMarketNFT(owner, owner, sword, coins(100))).IsOK())
+	require.NotNil(t, keeper.TransferNFT(ctx, sword, owner, other))
+	require.False(t, handler(ctx, *types.NewMsgRentNFT(other, sword, 1)).IsOK())
+
+	// the user right expires at the end of the block reaching the expiration time
+	ctx = ctx.WithBlockTime(now.Add(10*time.Hour - time.Second)).WithEventManager(sdk.NewEventManager())
+	keeper.ExpireRentals(ctx)
+	require.True(t, queryUser(sword).User.Equals(renter))
+	ctx = ctx.WithBlockTime(now.Add(10 * time.Hour))
+	keeper.ExpireRentals(ctx)
+	require.True(t, queryUser(sword).User.Empty())
+	requireOwner(t, mpKeeperTest, owner, types.NFTStatusDefault, sword)
+
+	var expired []string
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type != types.EventTypeExpireRental {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == types.AttributeKeyNFTID {
+				expired = append(expired, string(attr.Value))
+			}
+		}
+	}
+	require.Equal(t, []string{sword}, expired)
+
+	// a rental priced per block expires by height, the history keeps every rental
+	result = handler(ctx, *types.NewMsgListNFTForRent(owner, sword, coins(1), types.RentalUnitBlock, 100))
+	require.True(t, result.IsOK(), result.Log)
+	result = handler(ctx, *types.NewMsgRentNFT(other, sword, 20))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, ctx.BlockHeight()+20, queryUser(sword).ExpirationHeight)
+	ctx = ctx.WithBlockHeight(ctx.BlockHeight() + 20)
+	keeper.ExpireRentals(ctx)
+	require.True(t, queryUser(sword).User.Empty())
+
+	history := queryHistory(sword)
+	require.Len(t, history, 2)
+	require.True(t, history[0].User.Equals(renter))
+	require.Equal(t, coins(50), history[0].Price)
+	require.True(t, history[1].User.Equals(other))
+	require.Equal(t, coins(20), history[1].Price)
+
+	// a listing left behind by a previous owner cannot be rented
+	require.Nil(t, keeper.TransferNFT(ctx, sword, owner, other))
+	require.False(t, handler(ctx, *types.NewMsgRentNFT(renter, sword, 1)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgRemoveRentalListing(other, sword)).IsOK())
+	result = handler(ctx, *types.NewMsgRemoveRentalListing(owner, sword))
+	require.True(t, result.IsOK(), result.Log)
+	_, sdkErr := querier(ctx, []string{marketplace.QueryRentalListing, sword}, abci.RequestQuery{})
+	require.NotNil(t, sdkErr)
+
+	msgInv, broken := marketplace.NFTIndexesInvariant(keeper)(ctx)
+	require.False(t, broken, msgInv)
+}
+
+func TestListNFTForRentValidateBasic(t *testing.T) {
+	owner := sdk.AccAddress([]byte("owner"))
+	price := sdk.NewCoins(sdk.NewInt64Coin(types.DefaultTokenDenom, 5))
+
+	require.Nil(t, types.NewMsgListNFTForRent(owner, "token", price, types.RentalUnitBlock, 10).ValidateBasic())
+	for name, msg := range map[string]*types.MsgListNFTForRent{
+		"no owner":        types.NewMsgListNFTForRent(sdk.AccAddress{}, "token", price, types.RentalUnitHour, 10),
+		"no token":        types.NewMsgListNFTForRent(owner, "", price, types.RentalUnitHour, 10),
+		"no price":        types.NewMsgListNFTForRent(owner, "token", sdk.Coins{}, types.RentalUnitHour, 10),
+		"unknown unit":    types.NewMsgListNFTForRent(owner, "token", price, "day", 10),
+		"no max duration": types.NewMsgListNFTForRent(owner, "token", price, types.RentalUnitHour, 0),
+		"too long":        types.NewMsgListNFTForRent(owner, "token", price, types.RentalUnitHour, types.MaxRentalDuration+1),
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+
+	require.NotNil(t, types.NewMsgRentNFT(owner, "token", 0).ValidateBasic())
+}
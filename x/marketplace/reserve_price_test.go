@@ -0,0 +1,185 @@
+package marketplace_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// eventsString returns the keys and values of the attributes of the events.
+func eventsString(events sdk.Events) string {
+	var attrs []string
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			attrs = append(attrs, string(attr.Key)+"="+string(attr.Value))
+		}
+	}
+	return strings.Join(attrs, " ")
+}
+
+func TestAuctionReservePrice(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	for name, tc := range map[string]struct {
+		bid    int64
+		reveal bool
+		sold   bool
+	}{
+		"reserve not met": {bid: 776, reveal: true, sold: false},
+		"reserve met":     {bid: 778, reveal: true, sold: true},
+		"not revealed":    {bid: 776, reveal: false, sold: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mpKeeperTest, err := createMarketplaceKeeperTest()
+			defer mpKeeperTest.clear()
+			require.Nil(t, err)
+
+			require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+			owner, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+			handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+			querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+			keeper := mpKeeperTest.marketKeeper
+
+			mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+			result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+			require.True(t, result.IsOK())
+
+			start := mpKeeperTest.ctx.BlockHeader().Time
+			msg := types.NewMsgPutNFTOnAuction(owner, owner, mintMsg.ID, coins(100), sdk.Coins{}, start.Add(time.Hour))
+			msg.ReserveHash = types.ReservePriceHash(coins(777), "salt")
+			msg.RevealDuration = time.Hour
+			result = handler(mpKeeperTest.ctx, *msg)
+			require.True(t, result.IsOK(), result.Log)
+			require.NotContains(t, eventsString(result.Events), "777")
+
+			before := getBalances(mpKeeperTest, bidder)
+			result = handler(mpKeeperTest.ctx, *types.NewMsgMakeBidOnAuction(bidder, bidder, mintMsg.ID, coins(tc.bid), defaultCommission))
+			require.True(t, result.IsOK(), result.Log)
+			require.NotContains(t, eventsString(result.Events), "reserve_met")
+
+			// neither the lot query nor the store tell anything about the reserve price but its hash
+			bz, sdkErr := querier(mpKeeperTest.ctx, []string{marketplace.QueryAuctionLot, mintMsg.ID}, abci.RequestQuery{})
+			require.Nil(t, sdkErr)
+			var lot types.AuctionLot
+			types.ModuleCdc.MustUnmarshalJSON(bz, &lot)
+			require.True(t, lot.HasReserve())
+			require.False(t, strings.Contains(string(bz), "777"))
+
+			// only the owner can settle the lot while the reserve price may be revealed
+			ctx := mpKeeperTest.ctx.WithBlockTime(start.Add(90 * time.Minute))
+			result = handler(ctx, *types.NewMsgFinishAuction(bidder, mintMsg.ID))
+			require.False(t, result.IsOK())
+
+			result = handler(ctx, *types.NewMsgRevealReservePrice(owner, mintMsg.ID, coins(777), "wrong"))
+			require.False(t, result.IsOK())
+			result = handler(ctx, *types.NewMsgRevealReservePrice(bidder, mintMsg.ID, coins(777), "salt"))
+			require.False(t, result.IsOK())
+
+			if tc.reveal {
+				result = handler(ctx, *types.NewMsgRevealReservePrice(owner, mintMsg.ID, coins(777), "salt"))
+				require.True(t, result.IsOK(), result.Log)
+			} else {
+				ctx = mpKeeperTest.ctx.WithBlockTime(start.Add(3 * time.Hour))
+				keeper.CheckFinishedAuctions(ctx)
+			}
+
+			token, err := keeper.GetNFT(ctx, mintMsg.ID)
+			require.Nil(t, err)
+			require.False(t, token.IsOnAuction())
+			if tc.sold {
+				require.True(t, token.Owner.Equals(bidder))
+			} else {
+				require.True(t, token.Owner.Equals(owner))
+				require.Equal(t, before, getBalances(mpKeeperTest, bidder))
+			}
+
+			msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+			require.False(t, broken, msgInv)
+		})
+	}
+}
+
+func TestPutOnAuctionReserveValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("owner"))
+	finish := time.Now().UTC().Add(time.Hour)
+
+	msg := types.NewMsgPutNFTOnAuction(addr, addr, "token", coins(100), sdk.Coins{}, finish)
+	msg.ReserveHash = types.ReservePriceHash(coins(100), "salt")
+	require.NotNil(t, msg.ValidateBasic())
+
+	msg.RevealDuration = time.Hour
+	require.Nil(t, msg.ValidateBasic())
+
+	msg.ReserveHash = "777token"
+	require.NotNil(t, msg.ValidateBasic())
+
+	dutch := types.NewMsgPutNFTOnDutchAuction(addr, addr, "token", coins(100), coins(10), 0, finish)
+	dutch.ReserveHash = types.ReservePriceHash(coins(200), "salt")
+	dutch.RevealDuration = time.Hour
+	require.NotNil(t, dutch.ValidateBasic())
+}
+
+func TestAuctionReservePriceProxyBid(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	for name, maxBid := range map[string]int64{"maximum below reserve": 776, "maximum meets reserve": 1000} {
+		t.Run(name, func(t *testing.T) {
+			mpKeeperTest, err := createMarketplaceKeeperTest()
+			defer mpKeeperTest.clear()
+			require.Nil(t, err)
+
+			require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+			owner, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+			handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+			keeper := mpKeeperTest.marketKeeper
+
+			mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+			result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+			require.True(t, result.IsOK())
+
+			start := mpKeeperTest.ctx.BlockHeader().Time
+			msg := types.NewMsgPutNFTOnAuction(owner, owner, mintMsg.ID, coins(100), sdk.Coins{}, start.Add(time.Hour))
+			msg.ReserveHash = types.ReservePriceHash(coins(777), "salt")
+			msg.RevealDuration = time.Hour
+			result = handler(mpKeeperTest.ctx, *msg)
+			require.True(t, result.IsOK(), result.Log)
+
+			// the visible bid does not depend on the reserve price
+			before := getBalances(mpKeeperTest, bidder)
+			result = handler(mpKeeperTest.ctx,
+				*types.NewMsgMakeProxyBidOnAuction(bidder, mpKeeperTest.addrs[2], mintMsg.ID, coins(maxBid), defaultCommission))
+			require.True(t, result.IsOK(), result.Log)
+			lot, err := keeper.GetAuctionLot(mpKeeperTest.ctx, mintMsg.ID)
+			require.Nil(t, err)
+			require.Equal(t, coins(100), lot.LastBid.Bid)
+
+			ctx := mpKeeperTest.ctx.WithBlockTime(start.Add(90 * time.Minute))
+			result = handler(ctx, *types.NewMsgRevealReservePrice(owner, mintMsg.ID, coins(777), "salt"))
+			require.True(t, result.IsOK(), result.Log)
+
+			// the maximum is checked against the reserve price, the winner pays the reserve price
+			token, err := keeper.GetNFT(ctx, mintMsg.ID)
+			require.Nil(t, err)
+			if maxBid >= 777 {
+				require.True(t, token.Owner.Equals(bidder))
+				require.Equal(t, []int64{before[0] - 777}, getBalances(mpKeeperTest, bidder))
+			} else {
+				require.True(t, token.Owner.Equals(owner))
+				require.Equal(t, before, getBalances(mpKeeperTest, bidder))
+			}
+
+			msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+			require.False(t, broken, msgInv)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// royaltyEvents returns the royalty amounts of the pay_royalty events for the given NFT.
+func royaltyEvents(events sdk.Events, nftID string) []string {
+	var out []string
+	for _, event := range events {
+		if event.Type != types.EventTypePayRoyalty {
+			continue
+		}
+		var id, royalty string
+		for _, attr := range event.Attributes {
+			switch string(attr.Key) {
+			case types.AttributeKeyNFTID:
+				id = string(attr.Value)
+			case types.AttributeKeyRoyalty:
+				royalty = string(attr.Value)
+			}
+		}
+		if id == nftID {
+			out = append(out, royalty)
+		}
+	}
+	return out
+}
+
+func TestCreatorRoyalty(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(100000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	creator, first, second, beneficiary := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	ctx := mpKeeperTest.ctx
+
+	mintMsg := types.NewMsgMintNFTWithRoyalty(creator, creator, uuid.New().String(), denom, "", sdk.NewDecWithPrec(5, 2))
+	result := handler(ctx, *mintMsg)
+	require.True(t, result.IsOK(), result.Log)
+
+	// the royalty is shown in the NFT query
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	bz, sdkErr := querier(ctx, []string{marketplace.QueryNFT, mintMsg.ID}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	var info types.NFTInfo
+	types.ModuleCdc.MustUnmarshalJSON(bz, &info)
+	require.True(t, info.MPNFTInfo.Creator.Equals(creator))
+	require.Equal(t, mintMsg.Royalty, info.MPNFTInfo.Royalty)
+
+	// the creator is not paid a royalty for the first sale
+	result = handler(ctx, *types.NewMsgPutOnMarketNFT(creator, beneficiary, mintMsg.ID, price))
+	require.True(t, result.IsOK(), result.Log)
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	result = handler(ctx, *types.NewMsgBuyNFT(first, beneficiary, mintMsg.ID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	require.Empty(t, royaltyEvents(result.Events, mintMsg.ID))
+
+	// resale on the market
+	result = handler(ctx, *types.NewMsgPutOnMarketNFT(first, beneficiary, mintMsg.ID, price))
+	require.True(t, result.IsOK(), result.Log)
+	before := getBalances(mpKeeperTest, creator)
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	result = handler(ctx, *types.NewMsgBuyNFT(second, beneficiary, mintMsg.ID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, int64(50), getBalances(mpKeeperTest, creator)[0]-before[0])
+	require.Equal(t, []string{"50" + denom}, royaltyEvents(result.Events, mintMsg.ID))
+
+	// resale by an accepted offer
+	result = handler(ctx, *types.NewMsgMakeOffer(first, beneficiary, price, mintMsg.ID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	offerID := string(result.Data)
+	before = getBalances(mpKeeperTest, creator)
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	result = handler(ctx, *types.NewMsgAcceptOffer(second, beneficiary, mintMsg.ID, offerID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, int64(50), getBalances(mpKeeperTest, creator)[0]-before[0])
+	require.Equal(t, []string{"50" + denom}, royaltyEvents(result.Events, mintMsg.ID))
+
+	// resale on an auction, with the royalty capped by a lowered MaxRoyalty param
+	params := mpKeeperTest.marketKeeper.GetParams(ctx)
+	params.MaxRoyalty = sdk.NewDecWithPrec(2, 2)
+	mpKeeperTest.marketKeeper.SetParams(ctx, params)
+
+	expirationTime := ctx.BlockHeader().Time.Add(time.Hour)
+	result = handler(ctx, *types.NewMsgPutNFTOnAuction(first, beneficiary, mintMsg.ID, price, sdk.Coins{}, expirationTime))
+	require.True(t, result.IsOK(), result.Log)
+	result = handler(ctx, *types.NewMsgMakeBidOnAuction(second, beneficiary, mintMsg.ID, price, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+
+	before = getBalances(mpKeeperTest, creator)
+	ctx = ctx.WithBlockTime(expirationTime.Add(time.Minute)).WithEventManager(sdk.NewEventManager())
+	mpKeeperTest.marketKeeper.CheckFinishedAuctions(ctx)
+	token, err := mpKeeperTest.marketKeeper.GetNFT(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(second))
+	require.Equal(t, int64(20), getBalances(mpKeeperTest, creator)[0]-before[0])
+	require.Equal(t, []string{"20" + denom}, royaltyEvents(ctx.EventManager().Events(), mintMsg.ID))
+}
+
+func TestMaxRoyalty(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+
+	creator := mpKeeperTest.addrs[0]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	maxRoyalty := mpKeeperTest.marketKeeper.GetParams(mpKeeperTest.ctx).MaxRoyalty
+
+	for _, royalty := range []sdk.Dec{{}, sdk.NewDecWithPrec(-1, 2), sdk.NewDecWithPrec(101, 2)} {
+		require.NotNil(t, types.NewMsgMintNFTWithRoyalty(creator, creator, "token", denom, "", royalty).ValidateBasic())
+	}
+
+	msg := types.NewMsgMintNFTWithRoyalty(creator, creator, uuid.New().String(), denom, "", maxRoyalty.Add(sdk.NewDecWithPrec(1, 2)))
+	require.False(t, handler(mpKeeperTest.ctx, *msg).IsOK())
+	_, err = mpKeeperTest.marketKeeper.GetNFT(mpKeeperTest.ctx, msg.ID)
+	require.NotNil(t, err)
+
+	msg.Royalty = maxRoyalty
+	result := handler(mpKeeperTest.ctx, *msg)
+	require.True(t, result.IsOK(), result.Log)
+
+	genesis := marketplace.DefaultGenesisState()
+	genesis.Params.MaxRoyalty = sdk.OneDec()
+	require.NotNil(t, marketplace.ValidateGenesis(genesis))
+}
@@ -0,0 +1,175 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealedBidAuction(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	for name, secondPrice := range map[string]bool{"first price": false, "second price": true} {
+		t.Run(name, func(t *testing.T) {
+			mpKeeperTest, err := createMarketplaceKeeperTest()
+			defer mpKeeperTest.clear()
+			require.Nil(t, err)
+
+			require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+			addrs := mpKeeperTest.addrs
+			owner, winner, loser, silent := addrs[0], addrs[1], addrs[2], addrs[3]
+			handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+			keeper := mpKeeperTest.marketKeeper
+
+			mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+			result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, keeper)
+			require.True(t, result.IsOK())
+
+			start := mpKeeperTest.ctx.BlockHeader().Time
+			msg := types.NewMsgPutNFTOnSealedAuction(owner, owner, mintMsg.ID, coins(100), coins(10), secondPrice,
+				start.Add(time.Hour), time.Hour)
+			require.Nil(t, msg.ValidateBasic())
+			result = handler(mpKeeperTest.ctx, *msg)
+			require.True(t, result.IsOK(), result.Log)
+
+			before := getBalances(mpKeeperTest, addrs...)
+
+			// public bids are not accepted
+			result = handler(mpKeeperTest.ctx, *types.NewMsgMakeBidOnAuction(winner, owner, mintMsg.ID, coins(300), defaultCommission))
+			require.False(t, result.IsOK())
+
+			commit := func(ctx sdk.Context, bidder sdk.AccAddress, bid int64, salt string) sdk.Result {
+				bidHash := types.SealedBidHash(bidder, coins(bid), salt)
+				return handler(ctx, *types.NewMsgCommitSealedBid(bidder, owner, mintMsg.ID, bidHash, defaultCommission))
+			}
+			reveal := func(ctx sdk.Context, bidder sdk.AccAddress, bid int64, salt string) sdk.Result {
+				return handler(ctx, *types.NewMsgRevealSealedBid(bidder, mintMsg.ID, coins(bid), salt))
+			}
+
+			// a second commitment replaces the first one without locking another deposit
+			ctx := mpKeeperTest.ctx
+			require.True(t, commit(ctx, winner, 250, "winner").IsOK())
+			require.True(t, commit(ctx, winner, 300, "winner").IsOK())
+			require.True(t, commit(ctx, loser, 200, "loser").IsOK())
+			require.True(t, commit(ctx, silent, 500, "silent").IsOK())
+			require.Equal(t, before[1]-10, getBalances(mpKeeperTest, winner)[0])
+
+			// bids are revealed only after bidding closes
+			require.False(t, reveal(ctx, winner, 300, "winner").IsOK())
+
+			ctx = ctx.WithBlockTime(start.Add(90 * time.Minute))
+			require.False(t, commit(ctx, owner, 400, "owner").IsOK())
+			require.False(t, reveal(ctx, winner, 250, "winner").IsOK())
+			require.False(t, reveal(ctx, loser, 200, "other salt").IsOK())
+			require.True(t, reveal(ctx, winner, 300, "winner").IsOK())
+			require.True(t, reveal(ctx, loser, 200, "loser").IsOK())
+			require.False(t, reveal(ctx, loser, 200, "loser").IsOK())
+
+			msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+			require.False(t, broken, msgInv)
+
+			// neither the owner nor anyone else can end the auction during the reveal window
+			require.False(t, handler(ctx, *types.NewMsgRemoveNFTFromAuction(owner, mintMsg.ID)).IsOK())
+			require.False(t, handler(ctx, *types.NewMsgFinishAuction(owner, mintMsg.ID)).IsOK())
+			require.False(t, handler(ctx, *types.NewMsgSettleSealedAuction(loser, mintMsg.ID)).IsOK())
+			keeper.CheckFinishedAuctions(ctx)
+			_, err = keeper.GetAuctionLot(ctx, mintMsg.ID)
+			require.Nil(t, err)
+
+			// settled by the end blocker once the reveal window is over
+			ctx = ctx.WithBlockTime(start.Add(3 * time.Hour))
+			keeper.CheckFinishedAuctions(ctx)
+
+			_, err = keeper.GetAuctionLot(ctx, mintMsg.ID)
+			require.NotNil(t, err)
+			require.Empty(t, keeper.GetSealedBids(ctx, mintMsg.ID))
+			token, err := keeper.GetNFT(ctx, mintMsg.ID)
+			require.Nil(t, err)
+			require.True(t, token.Owner.Equals(winner))
+
+			price := int64(300)
+			if secondPrice {
+				price = 200
+			}
+			after := getBalances(mpKeeperTest, addrs...)
+			require.Equal(t, price, before[1]-after[1])
+			require.Equal(t, before[2], after[2])
+			require.Equal(t, before[3]-5, after[3])
+
+			msgInv, broken = marketplace.EscrowInvariant(keeper)(ctx)
+			require.False(t, broken, msgInv)
+		})
+	}
+}
+
+func TestSealedBidAuctionWithoutReveals(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(10000)))
+
+	owner, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+
+	mintMsg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	require.True(t, result.IsOK())
+
+	start := mpKeeperTest.ctx.BlockHeader().Time
+	result = handler(mpKeeperTest.ctx, *types.NewMsgPutNFTOnSealedAuction(owner, owner, mintMsg.ID, coins(100), coins(15),
+		false, start.Add(time.Hour), time.Hour))
+	require.True(t, result.IsOK(), result.Log)
+
+	before := getBalances(mpKeeperTest, owner, bidder)
+	bidHash := types.SealedBidHash(bidder, coins(150), "salt")
+	result = handler(mpKeeperTest.ctx, *types.NewMsgCommitSealedBid(bidder, bidder, mintMsg.ID, bidHash, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+
+	// anyone settles the auction after the reveal window, the NFT goes back to the owner
+	ctx := mpKeeperTest.ctx.WithBlockTime(start.Add(2 * time.Hour))
+	result = handler(ctx, *types.NewMsgSettleSealedAuction(bidder, mintMsg.ID))
+	require.True(t, result.IsOK(), result.Log)
+
+	token, err := mpKeeperTest.marketKeeper.GetNFT(ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(owner))
+	require.False(t, token.IsOnAuction())
+
+	// half of the deposit, rounded down, is forfeited to the owner
+	after := getBalances(mpKeeperTest, owner, bidder)
+	require.Equal(t, before[0]+7, after[0])
+	require.Equal(t, before[1]-7, after[1])
+}
+
+func TestPutOnSealedAuctionValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("owner"))
+	finish := time.Now().UTC().Add(time.Hour)
+
+	require.Nil(t, types.NewMsgPutNFTOnSealedAuction(addr, addr, "token", coins(100), coins(10), true, finish, time.Hour).ValidateBasic())
+
+	twoDenoms := coins(100).Add(sdk.NewCoins(sdk.NewCoin("other", sdk.NewInt(10))))
+	withBuyout := types.NewMsgPutNFTOnSealedAuction(addr, addr, "token", coins(100), coins(10), false, finish, time.Hour)
+	withBuyout.BuyoutPrice = coins(1000)
+	for name, msg := range map[string]*types.MsgPutNFTOnAuction{
+		"no deposit":           types.NewMsgPutNFTOnSealedAuction(addr, addr, "token", coins(100), sdk.Coins{}, false, finish, time.Hour),
+		"no reveal window":     types.NewMsgPutNFTOnSealedAuction(addr, addr, "token", coins(100), coins(10), false, finish, 0),
+		"two denoms":           types.NewMsgPutNFTOnSealedAuction(addr, addr, "token", twoDenoms, coins(10), false, finish, time.Hour),
+		"buyout price":         withBuyout,
+		"english with deposit": {Owner: addr, TokenID: "token", OpeningPrice: coins(100), BidDeposit: coins(10), TimeToSell: finish},
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+
+	require.NotNil(t, types.NewMsgCommitSealedBid(addr, addr, "token", "not a hash", defaultCommission).ValidateBasic())
+	require.NotNil(t, types.NewMsgRevealSealedBid(addr, "token", coins(100), "").ValidateBasic())
+}
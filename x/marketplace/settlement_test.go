@@ -0,0 +1,131 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	distr "github.com/cosmos/cosmos-sdk/x/distribution"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// faultyBankKeeper rejects payments to failSendTo, including payments to the module account
+// with the address (which is how validators commission is taken).
+type faultyBankKeeper struct {
+	bank.Keeper
+	failSendTo sdk.AccAddress
+}
+
+func (k *faultyBankKeeper) SendCoins(ctx sdk.Context, fromAddr, toAddr sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	if k.failSendTo != nil && toAddr.Equals(k.failSendTo) {
+		return sdk.ErrUnauthorized("payment rejected")
+	}
+	return k.Keeper.SendCoins(ctx, fromAddr, toAddr, amt)
+}
+
+func getBalances(mp *marketplaceKeeperTest, addrs ...sdk.AccAddress) []int64 {
+	var out []int64
+	for _, addr := range addrs {
+		out = append(out, mp.bankKeeper.GetCoins(mp.ctx, addr).AmountOf(types.DefaultTokenDenom).Int64())
+	}
+	return out
+}
+
+func TestBuyNFTBeneficiaryPaymentFails(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+	_, err = mpKeeperTest.updateVoteInfos(1, coins)
+	require.Nil(t, err)
+
+	seller, buyer := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	sellerBeneficiary, buyerBeneficiary := mpKeeperTest.addrs[2], mpKeeperTest.addrs[3]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+
+	mintMsg := nft.NewMsgMintNFT(seller, seller, uuid.New().String(), denom, "")
+	result := marketplace.HandleMsgMintNFTMarketplace(mpKeeperTest.ctx, mintMsg, mpKeeperTest.nftKeeper, mpKeeperTest.marketKeeper)
+	require.True(t, result.IsOK())
+	price := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(500)))
+	result = handler(mpKeeperTest.ctx, *types.NewMsgPutOnMarketNFT(seller, sellerBeneficiary, mintMsg.ID, price))
+	require.True(t, result.IsOK(), result.Log)
+
+	// the buyer beneficiary is paid first, then the seller beneficiary payment fails
+	mpKeeperTest.faultyBank.failSendTo = sellerBeneficiary
+	balancesBefore := getBalances(mpKeeperTest, mpKeeperTest.addrs...)
+
+	result = handler(mpKeeperTest.ctx, *types.NewMsgBuyNFT(buyer, buyerBeneficiary, mintMsg.ID, defaultCommission))
+	require.False(t, result.IsOK())
+	require.Equal(t, balancesBefore, getBalances(mpKeeperTest, mpKeeperTest.addrs...))
+
+	token, err := mpKeeperTest.marketKeeper.GetNFT(mpKeeperTest.ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(seller))
+	require.True(t, token.IsOnMarket())
+
+	// a failed purchase in a batch does not leave partial payments either
+	result = handler(mpKeeperTest.ctx, *types.NewMsgBatchBuyOnMarket(buyer, buyerBeneficiary, defaultCommission, []string{mintMsg.ID}))
+	require.True(t, result.IsOK(), result.Log)
+	require.Equal(t, balancesBefore, getBalances(mpKeeperTest, mpKeeperTest.addrs...))
+
+	mpKeeperTest.faultyBank.failSendTo = nil
+	result = handler(mpKeeperTest.ctx, *types.NewMsgBuyNFT(buyer, buyerBeneficiary, mintMsg.ID, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+	token, err = mpKeeperTest.marketKeeper.GetNFT(mpKeeperTest.ctx, mintMsg.ID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(buyer))
+}
+
+func TestFinishAuctionValidatorPaymentFails(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	coins := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(1000)))
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins))
+	_, err = mpKeeperTest.updateVoteInfos(1, coins)
+	require.Nil(t, err)
+
+	owner, bidder := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	module := marketplace.NewAppModule(mpKeeperTest.marketKeeper, mpKeeperTest.bankKeeper, mpKeeperTest.nftKeeper)
+	invariant := marketplace.EscrowInvariant(mpKeeperTest.marketKeeper)
+
+	expirationTime := time.Now().UTC().Add(time.Hour)
+	lotID := putNFTsOnAuction(t, mpKeeperTest, 1, expirationTime)[0]
+	bid := sdk.NewCoins(sdk.NewCoin(denom, sdk.NewInt(500)))
+	result := handler(mpKeeperTest.ctx, *types.NewMsgMakeBidOnAuction(bidder, mpKeeperTest.addrs[3], lotID, bid, defaultCommission))
+	require.True(t, result.IsOK(), result.Log)
+
+	// beneficiaries are paid first, then taking the validators commission fails
+	mpKeeperTest.faultyBank.failSendTo = supply.NewModuleAddress(distr.ModuleName)
+	balancesBefore := getBalances(mpKeeperTest, mpKeeperTest.addrs...)
+
+	ctx := mpKeeperTest.ctx.WithBlockTime(expirationTime.Add(time.Minute)).WithEventManager(sdk.NewEventManager())
+	module.EndBlock(ctx, abci.RequestEndBlock{})
+
+	// none of the payments is made, the bid is refunded and the NFT returned to its owner
+	balancesBefore[1] += 500
+	require.Equal(t, balancesBefore, getBalances(mpKeeperTest, mpKeeperTest.addrs...))
+	_, err = mpKeeperTest.marketKeeper.GetAuctionLot(ctx, lotID)
+	require.NotNil(t, err)
+	token, err := mpKeeperTest.marketKeeper.GetNFT(ctx, lotID)
+	require.Nil(t, err)
+	require.True(t, token.Owner.Equals(owner))
+	require.False(t, token.IsOnAuction())
+	msg, broken := invariant(ctx)
+	require.False(t, broken, msg)
+}
@@ -0,0 +1,176 @@
+package marketplace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestario/marketplace/x/marketplace"
+	"github.com/corestario/marketplace/x/marketplace/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestSwap(t *testing.T) {
+	denom := types.DefaultTokenDenom
+
+	mpKeeperTest, err := createMarketplaceKeeperTest()
+	defer mpKeeperTest.clear()
+	require.Nil(t, err)
+
+	require.Nil(t, mpKeeperTest.updateAccountsWithCoins(coins(1000)))
+
+	proposer, counterparty, other := mpKeeperTest.addrs[0], mpKeeperTest.addrs[1], mpKeeperTest.addrs[2]
+	handler := marketplace.NewHandler(mpKeeperTest.marketKeeper)
+	querier := marketplace.NewQuerier(mpKeeperTest.marketKeeper, mpKeeperTest.nftKeeper)
+	keeper := mpKeeperTest.marketKeeper
+	ctx := mpKeeperTest.ctx
+
+	mint := func(owner sdk.AccAddress) string {
+		msg := nft.NewMsgMintNFT(owner, owner, uuid.New().String(), denom, "")
+		result := marketplace.HandleMsgMintNFTMarketplace(ctx, msg, mpKeeperTest.nftKeeper, keeper)
+		require.True(t, result.IsOK())
+		return msg.ID
+	}
+	sword, shield, helmet := mint(proposer), mint(proposer), mint(counterparty)
+
+	propose := func(msg *types.MsgProposeSwap) string {
+		result := handler(ctx, *msg)
+		require.True(t, result.IsOK(), result.Log)
+		return string(result.Data)
+	}
+	requireOwner := func(owner sdk.AccAddress, status types.NFTStatus, ids ...string) {
+		for _, id := range ids {
+			token, err := keeper.GetNFT(ctx, id)
+			require.Nil(t, err)
+			require.True(t, token.Owner.Equals(owner), id)
+			require.Equal(t, status, token.Status, id)
+			baseToken, err := mpKeeperTest.nftKeeper.GetNFT(ctx, denom, id)
+			require.Nil(t, err)
+			require.True(t, baseToken.GetOwner().Equals(owner), id)
+		}
+
+		msgInv, broken := marketplace.EscrowInvariant(keeper)(ctx)
+		require.False(t, broken, msgInv)
+	}
+	querySwaps := func(party sdk.AccAddress) []string {
+		bz, sdkErr := querier(ctx, []string{marketplace.QuerySwaps, party.String()}, abci.RequestQuery{})
+		require.Nil(t, sdkErr)
+		var res types.QueryResSwaps
+		types.ModuleCdc.MustUnmarshalJSON(bz, &res)
+		var ids []string
+		for _, swap := range res.Swaps {
+			ids = append(ids, swap.ID)
+		}
+		return ids
+	}
+
+	// the counterparty NFTs must be theirs
+	require.False(t, handler(ctx, *types.NewMsgProposeSwap(proposer, counterparty,
+		[]string{sword}, nil, []string{shield}, nil)).IsOK())
+
+	// the NFTs and coins of the proposer are held by the swap
+	before := getBalances(mpKeeperTest, proposer, counterparty)
+	swapID := propose(types.NewMsgProposeSwap(proposer, counterparty,
+		[]string{sword, shield}, coins(10), []string{helmet}, coins(20)))
+	requireOwner(proposer, types.NFTStatusInSwap, sword, shield)
+	require.Equal(t, before[0]-10, getBalances(mpKeeperTest, proposer)[0])
+	require.False(t, handler(ctx, *types.NewMsgPutOnMarketNFT(proposer, proposer, sword, coins(100))).IsOK())
+	require.NotNil(t, keeper.TransferNFT(ctx, sword, proposer, other))
+
+	// either party finds the swap
+	require.Equal(t, []string{swapID}, querySwaps(proposer))
+	require.Equal(t, []string{swapID}, querySwaps(counterparty))
+	require.Empty(t, querySwaps(other))
+	bz, sdkErr := querier(ctx, []string{marketplace.QuerySwap, swapID}, abci.RequestQuery{})
+	require.Nil(t, sdkErr)
+	var swap types.Swap
+	types.ModuleCdc.MustUnmarshalJSON(bz, &swap)
+	require.Equal(t, []string{helmet}, swap.CounterpartyNFTs)
+
+	// only the counterparty accepts, only while its NFTs are not on sale
+	require.False(t, handler(ctx, *types.NewMsgAcceptSwap(other, swapID)).IsOK())
+	require.False(t, handler(ctx, *types.NewMsgCancelSwap(counterparty, swapID)).IsOK())
+	result := handler(ctx, *types.NewMsgPutOnMarketNFT(counterparty, counterparty, helmet, coins(100)))
+	require.True(t, result.IsOK(), result.Log)
+	require.False(t, handler(ctx, *types.NewMsgAcceptSwap(counterparty, swapID)).IsOK())
+	requireOwner(proposer, types.NFTStatusInSwap, sword, shield)
+	result = handler(ctx, *types.NewMsgRemoveNFTFromMarket(counterparty, helmet))
+	require.True(t, result.IsOK(), result.Log)
+
+	// both sides change hands at once
+	result = handler(ctx, *types.NewMsgAcceptSwap(counterparty, swapID))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(counterparty, types.NFTStatusDefault, sword, shield)
+	requireOwner(proposer, types.NFTStatusDefault, helmet)
+	require.Equal(t, []int64{before[0] + 10, before[1] - 10}, getBalances(mpKeeperTest, proposer, counterparty))
+	require.Empty(t, querySwaps(proposer))
+	require.False(t, handler(ctx, *types.NewMsgAcceptSwap(counterparty, swapID)).IsOK())
+
+	// a cancelled swap returns the NFTs and coins to the proposer
+	swapID = propose(types.NewMsgProposeSwap(proposer, counterparty, []string{helmet}, coins(5), nil, coins(50)))
+	requireOwner(proposer, types.NFTStatusInSwap, helmet)
+	result = handler(ctx, *types.NewMsgCancelSwap(proposer, swapID))
+	require.True(t, result.IsOK(), result.Log)
+	requireOwner(proposer, types.NFTStatusDefault, helmet)
+	require.Equal(t, before[0]+10, getBalances(mpKeeperTest, proposer)[0])
+	require.Empty(t, querySwaps(counterparty))
+
+	// an expired swap cannot be accepted and is cancelled at the end of the block
+	msg := types.NewMsgProposeSwap(proposer, counterparty, []string{helmet}, nil, []string{sword}, nil)
+	msg.ExpirationTime = ctx.BlockHeader().Time
+	require.False(t, handler(ctx, *msg).IsOK())
+	msg.ExpirationTime = ctx.BlockHeader().Time.Add(time.Hour)
+	swapID = propose(msg)
+
+	ctx = ctx.WithBlockTime(msg.ExpirationTime).WithEventManager(sdk.NewEventManager())
+	require.False(t, handler(ctx, *types.NewMsgAcceptSwap(counterparty, swapID)).IsOK())
+	keeper.CancelExpiredSwaps(ctx)
+	requireOwner(proposer, types.NFTStatusDefault, helmet)
+	requireOwner(counterparty, types.NFTStatusDefault, sword)
+	require.Empty(t, querySwaps(proposer))
+
+	var expired []string
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type != types.EventTypeExpireSwap {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == types.AttributeKeySwapID {
+				expired = append(expired, string(attr.Value))
+			}
+		}
+	}
+	require.Equal(t, []string{swapID}, expired)
+}
+
+func TestProposeSwapValidateBasic(t *testing.T) {
+	denom := types.DefaultTokenDenom
+	proposer, counterparty := sdk.AccAddress([]byte("proposer")), sdk.AccAddress([]byte("counterparty"))
+	price := sdk.NewCoins(sdk.NewInt64Coin(denom, 50))
+
+	require.Nil(t, types.NewMsgProposeSwap(proposer, counterparty, []string{"a"}, nil, []string{"b"}, nil).ValidateBasic())
+	require.Nil(t, types.NewMsgProposeSwap(proposer, counterparty, []string{"a"}, nil, nil, price).ValidateBasic())
+	require.Nil(t, types.NewMsgProposeSwap(proposer, counterparty, nil, price, []string{"b"}, nil).ValidateBasic())
+
+	many := make([]string, types.MaxSwapSize+1)
+	for i := range many {
+		many[i] = uuid.New().String()
+	}
+	for name, msg := range map[string]*types.MsgProposeSwap{
+		"no proposer":      types.NewMsgProposeSwap(sdk.AccAddress{}, counterparty, []string{"a"}, nil, []string{"b"}, nil),
+		"no counterparty":  types.NewMsgProposeSwap(proposer, sdk.AccAddress{}, []string{"a"}, nil, []string{"b"}, nil),
+		"same parties":     types.NewMsgProposeSwap(proposer, proposer, []string{"a"}, nil, []string{"b"}, nil),
+		"nothing given":    types.NewMsgProposeSwap(proposer, counterparty, nil, nil, []string{"b"}, nil),
+		"nothing wanted":   types.NewMsgProposeSwap(proposer, counterparty, []string{"a"}, nil, nil, nil),
+		"no NFTs":          types.NewMsgProposeSwap(proposer, counterparty, nil, price, nil, price),
+		"invalid coins":    types.NewMsgProposeSwap(proposer, counterparty, []string{"a"}, sdk.Coins{sdk.NewInt64Coin(denom, 0)}, []string{"b"}, nil),
+		"NFT listed twice": types.NewMsgProposeSwap(proposer, counterparty, []string{"a"}, nil, []string{"a"}, nil),
+		"empty NFT ID":     types.NewMsgProposeSwap(proposer, counterparty, []string{""}, nil, []string{"b"}, nil),
+		"too many NFTs":    types.NewMsgProposeSwap(proposer, counterparty, many, nil, []string{"b"}, nil),
+	} {
+		require.NotNil(t, msg.ValidateBasic(), name)
+	}
+}
@@ -38,4 +38,29 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgAcceptOffer{}, "marketplace/AcceptOffer", nil)
 	cdc.RegisterConcrete(MsgRemoveOffer{}, "marketplace/RemoveOffer", nil)
 	cdc.RegisterConcrete(MsgTransferNFTByIBC{}, "marketplace/MsgTransferNFT", nil)
+	cdc.RegisterConcrete(MsgMintNFTWithRoyalty{}, "marketplace/MintNFTWithRoyalty", nil)
+	cdc.RegisterConcrete(SealedBid{}, "marketplace/SealedBid", nil)
+	cdc.RegisterConcrete(MsgCommitSealedBid{}, "marketplace/MsgCommitSealedBid", nil)
+	cdc.RegisterConcrete(MsgRevealSealedBid{}, "marketplace/MsgRevealSealedBid", nil)
+	cdc.RegisterConcrete(MsgSettleSealedAuction{}, "marketplace/MsgSettleSealedAuction", nil)
+	cdc.RegisterConcrete(MsgRevealReservePrice{}, "marketplace/MsgRevealReservePrice", nil)
+	cdc.RegisterConcrete(MsgCreateBundle{}, "marketplace/MsgCreateBundle", nil)
+	cdc.RegisterConcrete(MsgRemoveBundle{}, "marketplace/MsgRemoveBundle", nil)
+	cdc.RegisterConcrete(MsgMakeCollectionOffer{}, "marketplace/MsgMakeCollectionOffer", nil)
+	cdc.RegisterConcrete(MsgFillCollectionOffer{}, "marketplace/MsgFillCollectionOffer", nil)
+	cdc.RegisterConcrete(MsgRemoveCollectionOffer{}, "marketplace/MsgRemoveCollectionOffer", nil)
+	cdc.RegisterConcrete(MsgProposeSwap{}, "marketplace/MsgProposeSwap", nil)
+	cdc.RegisterConcrete(MsgAcceptSwap{}, "marketplace/MsgAcceptSwap", nil)
+	cdc.RegisterConcrete(MsgCancelSwap{}, "marketplace/MsgCancelSwap", nil)
+	cdc.RegisterConcrete(MsgHashLockNFT{}, "marketplace/MsgHashLockNFT", nil)
+	cdc.RegisterConcrete(MsgClaimHashLockedNFT{}, "marketplace/MsgClaimHashLockedNFT", nil)
+	cdc.RegisterConcrete(MsgReclaimHashLockedNFT{}, "marketplace/MsgReclaimHashLockedNFT", nil)
+	cdc.RegisterConcrete(MsgListNFTForRent{}, "marketplace/MsgListNFTForRent", nil)
+	cdc.RegisterConcrete(MsgRemoveRentalListing{}, "marketplace/MsgRemoveRentalListing", nil)
+	cdc.RegisterConcrete(MsgRentNFT{}, "marketplace/MsgRentNFT", nil)
+	cdc.RegisterConcrete(MsgRequestLoan{}, "marketplace/MsgRequestLoan", nil)
+	cdc.RegisterConcrete(MsgCancelLoanRequest{}, "marketplace/MsgCancelLoanRequest", nil)
+	cdc.RegisterConcrete(MsgFundLoan{}, "marketplace/MsgFundLoan", nil)
+	cdc.RegisterConcrete(MsgRepayLoan{}, "marketplace/MsgRepayLoan", nil)
+	cdc.RegisterConcrete(MsgClaimLoanCollateral{}, "marketplace/MsgClaimLoanCollateral", nil)
 }
@@ -3,22 +3,67 @@ package types
 var (
 	AttributeValueCategory = ModuleName
 
-	AttributeKeyAmount       = "amount"
-	AttributeKeyBid          = "bid"
-	AttributeKeyBidder       = "bidder"
-	AttributeKeyBuyer        = "buyer"
-	AttributeKeyBuyoutPrice  = "buyout_price"
-	AttributeKeyBeneficiary  = "beneficiary"
-	AttributeKeyCommission   = "commission"
-	AttributeKeyDenom        = "denom"
-	AttributeKeyFinishTime   = "finish_time"
-	AttributeKeyNFTID        = "nft_id"
-	AttributeKeyOwner        = "owner"
-	AttributeKeyOpeningPrice = "opening_price"
-	AttributeKeyPrice        = "price"
-	AttributeKeyRecipient    = "recipient"
-	AttributeKeySender       = "sender"
-	AttributeKeyNFTTokenURI  = "token_uri"
-	AttributeKeyOfferID      = "offer_id"
-	AttributeKeyIsBuyout     = "is_buyout"
+	AttributeKeyAmount            = "amount"
+	AttributeKeyBid               = "bid"
+	AttributeKeyBidder            = "bidder"
+	AttributeKeyBuyer             = "buyer"
+	AttributeKeyBuyoutPrice       = "buyout_price"
+	AttributeKeyBeneficiary       = "beneficiary"
+	AttributeKeyCommission        = "commission"
+	AttributeKeyDenom             = "denom"
+	AttributeKeyFinishTime        = "finish_time"
+	AttributeKeyNFTID             = "nft_id"
+	AttributeKeyOwner             = "owner"
+	AttributeKeyOpeningPrice      = "opening_price"
+	AttributeKeyPrice             = "price"
+	AttributeKeyRecipient         = "recipient"
+	AttributeKeySender            = "sender"
+	AttributeKeyNFTTokenURI       = "token_uri"
+	AttributeKeyOfferID           = "offer_id"
+	AttributeKeyIsBuyout          = "is_buyout"
+	AttributeKeyCreator           = "creator"
+	AttributeKeyRoyalty           = "royalty"
+	AttributeKeyAuctionType       = "auction_type"
+	AttributeKeyFloorPrice        = "floor_price"
+	AttributeKeyBidHash           = "bid_hash"
+	AttributeKeyDeposit           = "deposit"
+	AttributeKeyForfeit           = "forfeit"
+	AttributeKeyMinBidIncrement   = "min_bid_increment"
+	AttributeKeyExtensionWindow   = "extension_window"
+	AttributeKeyHasReserve        = "has_reserve"
+	AttributeKeyReservePrice      = "reserve_price"
+	AttributeKeyIsProxy           = "is_proxy"
+	AttributeKeyLastBidder        = "last_bidder"
+	AttributeKeyItems             = "items"
+	AttributeKeyExpirationTime    = "expiration_time"
+	AttributeKeyExpirationHeight  = "expiration_height"
+	AttributeKeyQuantity          = "quantity"
+	AttributeKeySwapID            = "swap_id"
+	AttributeKeyProposer          = "proposer"
+	AttributeKeyCounterparty      = "counterparty"
+	AttributeKeyProposerNFTs      = "proposer_nfts"
+	AttributeKeyProposerCoins     = "proposer_coins"
+	AttributeKeyCounterpartyNFTs  = "counterparty_nfts"
+	AttributeKeyCounterpartyCoins = "counterparty_coins"
+	AttributeKeyHashLock          = "hash_lock"
+	AttributeKeyPreimage          = "preimage"
+	AttributeKeyTimeout           = "timeout"
+	AttributeKeyUser              = "user"
+	AttributeKeyUnit              = "unit"
+	AttributeKeyDuration          = "duration"
+	AttributeKeyMaxDuration       = "max_duration"
+	AttributeKeyLoanID            = "loan_id"
+	AttributeKeyBorrower          = "borrower"
+	AttributeKeyLender            = "lender"
+	AttributeKeyPrincipal         = "principal"
+	AttributeKeyInterest          = "interest"
+	AttributeKeyDeadline          = "deadline"
+	AttributeKeyError             = "error"
+
+	EventTypePayRoyalty          = "pay_royalty"
+	EventTypeForfeitDeposit      = "forfeit_deposit"
+	EventTypeRefundOffer         = "refund_offer"
+	EventTypeExpireSwap          = "expire_swap"
+	EventTypeExpireRental        = "expire_rental"
+	EventTypeFailAuctionSettling = "fail_auction_settling"
 )
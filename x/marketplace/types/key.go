@@ -1,7 +1,11 @@
 package types
 
 import (
+	"encoding/binary"
 	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 type NFTStatus int8
@@ -18,6 +22,16 @@ func (s NFTStatus) String() string {
 		return "deleted"
 	case NFTStatusUndefined:
 		return "undefined"
+	case NFTStatusInBundle:
+		return "in_bundle"
+	case NFTStatusInSwap:
+		return "in_swap"
+	case NFTStatusHashLocked:
+		return "hash_locked"
+	case NFTStatusRented:
+		return "rented"
+	case NFTStatusCollateral:
+		return "collateral"
 	}
 	return "undefined"
 }
@@ -42,6 +56,16 @@ func (s *NFTStatus) UnmarshalJSON(b []byte) error {
 		e = NFTStatus(3)
 	case "\"undefined\"":
 		e = NFTStatus(4)
+	case "\"in_bundle\"":
+		e = NFTStatus(5)
+	case "\"in_swap\"":
+		e = NFTStatus(6)
+	case "\"hash_locked\"":
+		e = NFTStatus(7)
+	case "\"rented\"":
+		e = NFTStatus(8)
+	case "\"collateral\"":
+		e = NFTStatus(9)
 	default:
 		e = NFTStatus(0)
 	}
@@ -56,6 +80,11 @@ const (
 	NFTStatusOnAuction
 	NFTStatusDeleted
 	NFTStatusUndefined
+	NFTStatusInBundle
+	NFTStatusInSwap
+	NFTStatusHashLocked
+	NFTStatusRented
+	NFTStatusCollateral
 )
 
 const (
@@ -67,9 +96,9 @@ const (
 	RegisterCurrency = "register_currency"
 	AuctionKey       = "auction"
 	DeletedNFTKey    = "deleted_nft"
+	OfferKey         = "offer"
 
-	FungibleTokenCreationPrice = 10 // TODO: price or commission
-	FungibleCommissionAddress  = "" // TODO: create account for commissions
+	FungibleCommissionAddress = "" // TODO: create account for commissions
 
 	RouterKey = ModuleName
 
@@ -90,14 +119,43 @@ const (
 
 	FlagParamBuyoutPrice      = "buyout"
 	FlagParamBuyoutPriceShort = "u"
+	FlagAuctionType           = "type"
+	FlagFloorPrice            = "floor"
+	FlagPriceStep             = "price_step"
+	FlagBidDeposit            = "deposit"
+	FlagRevealDuration        = "reveal_duration"
+	FlagSecondPrice           = "second_price"
+	FlagMinBidIncrement       = "min_increment"
+	FlagExtensionWindow       = "extension_window"
+	FlagReservePrice          = "reserve"
+	FlagReserveSalt           = "reserve_salt"
+	FlagProxyBid              = "proxy"
+	FlagOfferExpiration       = "expiration"
+	FlagOfferExpirationHeight = "expiration_height"
+	FlagQuantity              = "quantity"
+	FlagSwapGiveNFTs          = "give_nfts"
+	FlagSwapGiveCoins         = "give_coins"
+	FlagSwapWantNFTs          = "want_nfts"
+	FlagSwapWantCoins         = "want_coins"
 
-	DefaultMaximumBeneficiaryCommission = 0.05
-	DefaultBeneficiariesCommission      = 0.015
-	DefaultValidatorsCommission         = 0.01
+	// filters of the NFTs query, also used as REST query parameters
+	FlagPage     = "page"
+	FlagLimit    = "limit"
+	FlagOwner    = "owner"
+	FlagStatus   = "status"
+	FlagDenom    = "denom"
+	FlagMinPrice = "min_price"
+	FlagMaxPrice = "max_price"
+	FlagSortBy   = "sort_by"
 
 	DefaultTokenDenom = "token"
 
 	MaxTokenIDLength     = 36
+	MaxBundleSize        = 100
+	MaxSwapSize          = 100
+	MaxPreimageSize      = 64
+	MaxRentalDuration    = 1000000
+	MaxOfferQuantity     = 1000
 	MaxNameLength        = 50
 	MaxDescriptionLength = 32000
 	MaxImageLength       = 32000
@@ -106,10 +164,351 @@ const (
 	MinDenomLength       = 3
 	IBCNFTPort           = "transfernft"
 
-	DefaultFinishAuctionHost    = "localhost"
-	DefaultFinishAuctionPort    = 1317
-	DefaultChainName            = "mpchain"
-	DefaultFinishingAccountName = "dgaming"
-	DefaultFinishingAccountPass = "12345678"
-	DefaultFinishingAccountAddr = "cosmos1tctr64k4en25uvet2k2tfkwkh0geyrv8fvuvet"
+	DefaultStartingOfferID uint64 = 1
+)
+
+// Keys for the NFT store:
+// - 0x01<nft_id>: NFT
+// - 0x02<owner><nft_id>: nft_id
+// - 0x03<status><nft_id>: nft_id
+// - 0x04<len(denom)><denom><nft_id>: nft_id
+// - 0x05<nft_id>: HashLock
+// - 0x06<nft_id>: RentalListing
+// - 0x07<len(nft_id)><nft_id><index>: Rental, the rental history of the NFT
+// - 0x08<expiration_time><nft_id>: nft_id, rentals expiring at the time
+// - 0x09<expiration_height><nft_id>: nft_id, rentals expiring at the height
+var (
+	NFTPrefix                     = []byte{0x01}
+	NFTOwnerIndexPrefix           = []byte{0x02}
+	NFTStatusIndexPrefix          = []byte{0x03}
+	NFTDenomIndexPrefix           = []byte{0x04}
+	HashLockPrefix                = []byte{0x05}
+	RentalListingPrefix           = []byte{0x06}
+	RentalHistoryPrefix           = []byte{0x07}
+	RentalExpiryTimeQueuePrefix   = []byte{0x08}
+	RentalExpiryHeightQueuePrefix = []byte{0x09}
+)
+
+// Keys for the auction store:
+// - 0x01<nft_id>: AuctionLot
+// - 0x02<settlement_time><nft_id>: nft_id
+// - 0x03<len(nft_id)><nft_id><bidder>: SealedBid
+// - 0x04<len(nft_id)><nft_id><auction><index>: AuctionBid, the bid history of the auction of the NFT with the number
+// - 0x05<height><len(nft_id)><nft_id><auction>: bid history prefix, bid histories of finished auctions pruned at the height
+// - 0x06<nft_id>: number of the latest auction of the NFT
+// - 0x07<nft_id>: reserve price of the lot, once revealed by the owner
+// - 0x08<nft_id>: maximum bid of the leading proxy bidder of the lot
+var (
+	AuctionLotPrefix           = []byte{0x01}
+	AuctionExpiryQueuePrefix   = []byte{0x02}
+	SealedBidPrefix            = []byte{0x03}
+	BidHistoryPrefix           = []byte{0x04}
+	BidHistoryPruneQueuePrefix = []byte{0x05}
+	LatestAuctionPrefix        = []byte{0x06}
+	ReservePricePrefix         = []byte{0x07}
+	ProxyMaxBidPrefix          = []byte{0x08}
+)
+
+// Keys for the offer store:
+// - 0x00: next offer ID
+// - 0x01<expiration_time><len(nft_id)><nft_id><offer_id>: <len(nft_id)><nft_id><offer_id>, offers expiring at the time
+// - 0x02<expiration_height><len(nft_id)><nft_id><offer_id>: <len(nft_id)><nft_id><offer_id>, offers expiring at the height
+// - 0x03<len(nft_id)><nft_id><offer_id>: Offer
+// - 0x04<buyer><len(nft_id)><nft_id><offer_id>: <len(nft_id)><nft_id><offer_id>, offers of the buyer
+// - 0x05<offer_id>: CollectionOffer
+// - 0x06<len(denom)><denom><offer_id>: offer_id, collection offers for the denom
+// - 0x07<swap_id>: Swap
+// - 0x08<party><swap_id>: swap_id, swaps the address is the proposer or the counterparty of
+// - 0x09<expiration_time><swap_id>: swap_id, swaps expiring at the time
+// - 0x0A<loan_id>: Loan
+// - 0x0B<party><loan_id>: loan_id, loans the address is the borrower or the lender of
+var (
+	OfferSequenceKey             = []byte{0x00}
+	OfferExpiryTimeQueuePrefix   = []byte{0x01}
+	OfferExpiryHeightQueuePrefix = []byte{0x02}
+	OfferPrefix                  = []byte{0x03}
+	OfferBuyerIndexPrefix        = []byte{0x04}
+	CollectionOfferPrefix        = []byte{0x05}
+	CollectionOfferDenomPrefix   = []byte{0x06}
+	SwapPrefix                   = []byte{0x07}
+	SwapPartyIndexPrefix         = []byte{0x08}
+	SwapExpiryQueuePrefix        = []byte{0x09}
+	LoanPrefix                   = []byte{0x0A}
+	LoanPartyIndexPrefix         = []byte{0x0B}
 )
+
+// GetNFTKey returns the key of the NFT with the given ID
+func GetNFTKey(id string) []byte {
+	return concatBytes(NFTPrefix, []byte(id))
+}
+
+// GetNFTOwnerIndexPrefix returns the prefix of the owner index entries of all NFTs of the owner
+func GetNFTOwnerIndexPrefix(owner sdk.AccAddress) []byte {
+	return concatBytes(NFTOwnerIndexPrefix, owner)
+}
+
+// GetNFTOwnerIndexKey returns the key of the owner index entry of the NFT
+func GetNFTOwnerIndexKey(owner sdk.AccAddress, id string) []byte {
+	return concatBytes(GetNFTOwnerIndexPrefix(owner), []byte(id))
+}
+
+// GetNFTStatusIndexPrefix returns the prefix of the status index entries of all NFTs with the status
+func GetNFTStatusIndexPrefix(status NFTStatus) []byte {
+	return concatBytes(NFTStatusIndexPrefix, []byte{byte(status)})
+}
+
+// GetNFTStatusIndexKey returns the key of the status index entry of the NFT
+func GetNFTStatusIndexKey(status NFTStatus, id string) []byte {
+	return concatBytes(GetNFTStatusIndexPrefix(status), []byte(id))
+}
+
+// GetNFTDenomIndexPrefix returns the prefix of the denom index entries of all NFTs of the denom.
+// The denom is length-prefixed so that one denom is never a prefix of another.
+func GetNFTDenomIndexPrefix(denom string) []byte {
+	return concatBytes(NFTDenomIndexPrefix, sdk.Uint64ToBigEndian(uint64(len(denom))), []byte(denom))
+}
+
+// GetNFTDenomIndexKey returns the key of the denom index entry of the NFT
+func GetNFTDenomIndexKey(denom, id string) []byte {
+	return concatBytes(GetNFTDenomIndexPrefix(denom), []byte(id))
+}
+
+// GetHashLockKey returns the key of the hash lock of the NFT with the given ID
+func GetHashLockKey(id string) []byte {
+	return concatBytes(HashLockPrefix, []byte(id))
+}
+
+// GetRentalListingKey returns the key of the rental listing of the NFT with the given ID
+func GetRentalListingKey(id string) []byte {
+	return concatBytes(RentalListingPrefix, []byte(id))
+}
+
+// GetRentalHistoryPrefix returns the prefix of the rental history of the NFT.
+// The ID is length-prefixed so that one ID is never a prefix of another.
+func GetRentalHistoryPrefix(id string) []byte {
+	return concatBytes(RentalHistoryPrefix, sdk.Uint64ToBigEndian(uint64(len(id))), []byte(id))
+}
+
+// GetRentalHistoryKey returns the key of the rental with the given index in the rental history of the NFT
+func GetRentalHistoryKey(id string, index uint64) []byte {
+	return concatBytes(GetRentalHistoryPrefix(id), sdk.Uint64ToBigEndian(index))
+}
+
+// GetRentalExpiryTimeQueueTimeKey returns the prefix of the rentals expiring at the time
+func GetRentalExpiryTimeQueueTimeKey(expirationTime time.Time) []byte {
+	return concatBytes(RentalExpiryTimeQueuePrefix, sdk.FormatTimeBytes(expirationTime))
+}
+
+// GetRentalExpiryTimeQueueKey returns the key of the expiry time queue entry of the rental of the NFT
+func GetRentalExpiryTimeQueueKey(expirationTime time.Time, id string) []byte {
+	return concatBytes(GetRentalExpiryTimeQueueTimeKey(expirationTime), []byte(id))
+}
+
+// GetRentalExpiryHeightQueueHeightKey returns the prefix of the rentals expiring at the height
+func GetRentalExpiryHeightQueueHeightKey(height int64) []byte {
+	return concatBytes(RentalExpiryHeightQueuePrefix, sdk.Uint64ToBigEndian(uint64(height)))
+}
+
+// GetRentalExpiryHeightQueueKey returns the key of the expiry height queue entry of the rental of the NFT
+func GetRentalExpiryHeightQueueKey(height int64, id string) []byte {
+	return concatBytes(GetRentalExpiryHeightQueueHeightKey(height), []byte(id))
+}
+
+// GetAuctionLotKey returns the key of the auction lot for the given NFT
+func GetAuctionLotKey(id string) []byte {
+	return concatBytes(AuctionLotPrefix, []byte(id))
+}
+
+// GetAuctionExpiryQueueTimeKey returns the prefix of all expiry queue entries expiring at the given time
+func GetAuctionExpiryQueueTimeKey(expirationTime time.Time) []byte {
+	return concatBytes(AuctionExpiryQueuePrefix, sdk.FormatTimeBytes(expirationTime))
+}
+
+// GetAuctionExpiryQueueKey returns the key of the expiry queue entry of the given lot
+func GetAuctionExpiryQueueKey(expirationTime time.Time, id string) []byte {
+	return concatBytes(GetAuctionExpiryQueueTimeKey(expirationTime), []byte(id))
+}
+
+// GetSealedBidsPrefix returns the prefix of the sealed bids on the lot of the given NFT.
+// The ID is length-prefixed so that one ID is never a prefix of another.
+func GetSealedBidsPrefix(id string) []byte {
+	return concatBytes(SealedBidPrefix, sdk.Uint64ToBigEndian(uint64(len(id))), []byte(id))
+}
+
+// GetSealedBidKey returns the key of the sealed bid of the bidder on the lot of the given NFT
+func GetSealedBidKey(id string, bidder sdk.AccAddress) []byte {
+	return concatBytes(GetSealedBidsPrefix(id), bidder)
+}
+
+// GetBidHistoryPrefix returns the prefix of the bid history of the auction of the given NFT with the given number.
+// The ID is length-prefixed so that one ID is never a prefix of another.
+func GetBidHistoryPrefix(id string, auction uint64) []byte {
+	return concatBytes(BidHistoryPrefix, sdk.Uint64ToBigEndian(uint64(len(id))), []byte(id),
+		sdk.Uint64ToBigEndian(auction))
+}
+
+// GetBidHistoryKey returns the key of the bid with the given index in the bid history of the auction
+func GetBidHistoryKey(id string, auction, index uint64) []byte {
+	return concatBytes(GetBidHistoryPrefix(id, auction), sdk.Uint64ToBigEndian(index))
+}
+
+// GetBidHistoryPruneQueueHeightKey returns the prefix of all prune queue entries due at the given height
+func GetBidHistoryPruneQueueHeightKey(height int64) []byte {
+	return concatBytes(BidHistoryPruneQueuePrefix, sdk.Uint64ToBigEndian(uint64(height)))
+}
+
+// GetBidHistoryPruneQueueKey returns the key of the prune queue entry of the bid history of the auction
+func GetBidHistoryPruneQueueKey(height int64, id string, auction uint64) []byte {
+	return concatBytes(GetBidHistoryPruneQueueHeightKey(height), sdk.Uint64ToBigEndian(uint64(len(id))), []byte(id),
+		sdk.Uint64ToBigEndian(auction))
+}
+
+// SplitBidHistoryKey returns the NFT ID and the number of the auction of a key in a bid history.
+func SplitBidHistoryKey(key []byte) (id string, auction uint64) {
+	return splitAuctionRef(key[len(BidHistoryPrefix):])
+}
+
+// SplitBidHistoryPruneQueueKey returns the height, the NFT ID and the number of the auction of a prune queue entry.
+func SplitBidHistoryPruneQueueKey(key []byte) (height int64, id string, auction uint64) {
+	key = key[len(BidHistoryPruneQueuePrefix):]
+	id, auction = splitAuctionRef(key[8:])
+	return int64(binary.BigEndian.Uint64(key[:8])), id, auction
+}
+
+// splitAuctionRef returns the NFT ID and the number of the auction of <len(nft_id)><nft_id><auction>.
+func splitAuctionRef(ref []byte) (id string, auction uint64) {
+	length := binary.BigEndian.Uint64(ref[:8])
+	return string(ref[8 : 8+length]), binary.BigEndian.Uint64(ref[8+length : 16+length])
+}
+
+// GetLatestAuctionKey returns the key of the number of the latest auction of the given NFT
+func GetLatestAuctionKey(id string) []byte {
+	return concatBytes(LatestAuctionPrefix, []byte(id))
+}
+
+// GetReservePriceKey returns the key of the revealed reserve price of the lot of the given NFT
+func GetReservePriceKey(id string) []byte {
+	return concatBytes(ReservePricePrefix, []byte(id))
+}
+
+// GetProxyMaxBidKey returns the key of the maximum bid of the leading proxy bidder of the lot of the given NFT
+func GetProxyMaxBidKey(id string) []byte {
+	return concatBytes(ProxyMaxBidPrefix, []byte(id))
+}
+
+// GetOfferRef returns the reference to the offer of the given NFT stored in the offer expiry queues and the buyer index.
+// The NFT ID is length-prefixed so that the reference is split unambiguously by SplitOfferRef.
+func GetOfferRef(nftID, offerID string) []byte {
+	return concatBytes(sdk.Uint64ToBigEndian(uint64(len(nftID))), []byte(nftID), []byte(offerID))
+}
+
+// SplitOfferRef returns the NFT ID and the offer ID of an offer reference.
+func SplitOfferRef(ref []byte) (nftID, offerID string) {
+	length := binary.BigEndian.Uint64(ref[:8])
+	return string(ref[8 : 8+length]), string(ref[8+length:])
+}
+
+// GetOffersPrefix returns the prefix of the offers for the given NFT.
+// The ID is length-prefixed so that one ID is never a prefix of another.
+func GetOffersPrefix(nftID string) []byte {
+	return concatBytes(OfferPrefix, sdk.Uint64ToBigEndian(uint64(len(nftID))), []byte(nftID))
+}
+
+// GetOfferKey returns the key of the offer for the given NFT
+func GetOfferKey(nftID, offerID string) []byte {
+	return concatBytes(OfferPrefix, GetOfferRef(nftID, offerID))
+}
+
+// GetOfferBuyerIndexPrefix returns the prefix of the buyer index entries of all offers of the buyer
+func GetOfferBuyerIndexPrefix(buyer sdk.AccAddress) []byte {
+	return concatBytes(OfferBuyerIndexPrefix, buyer)
+}
+
+// GetOfferBuyerIndexKey returns the key of the buyer index entry of the offer for the given NFT
+func GetOfferBuyerIndexKey(buyer sdk.AccAddress, nftID, offerID string) []byte {
+	return concatBytes(GetOfferBuyerIndexPrefix(buyer), GetOfferRef(nftID, offerID))
+}
+
+// GetOfferExpiryTimeQueueTimeKey returns the prefix of the offers expiring at the time
+func GetOfferExpiryTimeQueueTimeKey(expirationTime time.Time) []byte {
+	return concatBytes(OfferExpiryTimeQueuePrefix, sdk.FormatTimeBytes(expirationTime))
+}
+
+// GetOfferExpiryTimeQueueKey returns the key of the expiry time queue entry of the offer of the given NFT
+func GetOfferExpiryTimeQueueKey(expirationTime time.Time, nftID, offerID string) []byte {
+	return concatBytes(GetOfferExpiryTimeQueueTimeKey(expirationTime), GetOfferRef(nftID, offerID))
+}
+
+// GetOfferExpiryHeightQueueHeightKey returns the prefix of the offers expiring at the height
+func GetOfferExpiryHeightQueueHeightKey(height int64) []byte {
+	return concatBytes(OfferExpiryHeightQueuePrefix, sdk.Uint64ToBigEndian(uint64(height)))
+}
+
+// GetOfferExpiryHeightQueueKey returns the key of the expiry height queue entry of the offer of the given NFT
+func GetOfferExpiryHeightQueueKey(height int64, nftID, offerID string) []byte {
+	return concatBytes(GetOfferExpiryHeightQueueHeightKey(height), GetOfferRef(nftID, offerID))
+}
+
+// GetCollectionOfferKey returns the key of the collection offer with the given ID
+func GetCollectionOfferKey(id string) []byte {
+	return concatBytes(CollectionOfferPrefix, []byte(id))
+}
+
+// GetCollectionOfferDenomIndexPrefix returns the prefix of the denom index entries of all collection offers for the denom.
+// The denom is length-prefixed so that one denom is never a prefix of another.
+func GetCollectionOfferDenomIndexPrefix(denom string) []byte {
+	return concatBytes(CollectionOfferDenomPrefix, sdk.Uint64ToBigEndian(uint64(len(denom))), []byte(denom))
+}
+
+// GetCollectionOfferDenomIndexKey returns the key of the denom index entry of the collection offer
+func GetCollectionOfferDenomIndexKey(denom, id string) []byte {
+	return concatBytes(GetCollectionOfferDenomIndexPrefix(denom), []byte(id))
+}
+
+// GetSwapKey returns the key of the swap with the given ID
+func GetSwapKey(id string) []byte {
+	return concatBytes(SwapPrefix, []byte(id))
+}
+
+// GetSwapPartyIndexPrefix returns the prefix of the party index entries of all swaps of the address
+func GetSwapPartyIndexPrefix(party sdk.AccAddress) []byte {
+	return concatBytes(SwapPartyIndexPrefix, party)
+}
+
+// GetSwapPartyIndexKey returns the key of the party index entry of the swap
+func GetSwapPartyIndexKey(party sdk.AccAddress, id string) []byte {
+	return concatBytes(GetSwapPartyIndexPrefix(party), []byte(id))
+}
+
+// GetSwapExpiryQueueTimeKey returns the prefix of the swaps expiring at the time
+func GetSwapExpiryQueueTimeKey(expirationTime time.Time) []byte {
+	return concatBytes(SwapExpiryQueuePrefix, sdk.FormatTimeBytes(expirationTime))
+}
+
+// GetSwapExpiryQueueKey returns the key of the expiry queue entry of the swap
+func GetSwapExpiryQueueKey(expirationTime time.Time, id string) []byte {
+	return concatBytes(GetSwapExpiryQueueTimeKey(expirationTime), []byte(id))
+}
+
+// GetLoanKey returns the key of the loan with the given ID
+func GetLoanKey(id string) []byte {
+	return concatBytes(LoanPrefix, []byte(id))
+}
+
+// GetLoanPartyIndexPrefix returns the prefix of the party index entries of all loans of the address
+func GetLoanPartyIndexPrefix(party sdk.AccAddress) []byte {
+	return concatBytes(LoanPartyIndexPrefix, party)
+}
+
+// GetLoanPartyIndexKey returns the key of the party index entry of the loan
+func GetLoanPartyIndexKey(party sdk.AccAddress, id string) []byte {
+	return concatBytes(GetLoanPartyIndexPrefix(party), []byte(id))
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
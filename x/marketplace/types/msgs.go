@@ -1,11 +1,14 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/modules/incubator/nft"
 )
 
 // --------------------------------------------------------------------------
@@ -122,11 +125,11 @@ type MsgBuyNFT struct {
 	Buyer sdk.AccAddress `json:"buyer"`
 	// Beneficiary is the cosmos user who gets the commission for this transaction.
 	Beneficiary           sdk.AccAddress `json:"beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission,omitempty"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	TokenID               string         `json:"token_id"`
 }
 
-func NewMsgBuyNFT(owner, beneficiary sdk.AccAddress, tokenID string, commission string) *MsgBuyNFT {
+func NewMsgBuyNFT(owner, beneficiary sdk.AccAddress, tokenID string, commission sdk.Dec) *MsgBuyNFT {
 	return &MsgBuyNFT{
 		Buyer:                 owner,
 		Beneficiary:           beneficiary,
@@ -146,6 +149,9 @@ func (m MsgBuyNFT) ValidateBasic() sdk.Error {
 	if m.Buyer.Empty() {
 		return sdk.ErrInvalidAddress(m.Buyer.String())
 	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
 	if len(m.TokenID) == 0 {
 		return sdk.ErrUnknownRequest("TokenID cannot be empty")
 	}
@@ -380,17 +386,65 @@ type MsgPutNFTOnAuction struct {
 	OpeningPrice sdk.Coins      `json:"opening_price"`
 	BuyoutPrice  sdk.Coins      `json:"buyout_price"`
 	TimeToSell   time.Time      `json:"time_to_sell"`
+	AuctionType  AuctionType    `json:"auction_type"`
+	// Dutch auction only
+	FloorPrice sdk.Coins     `json:"floor_price"`
+	PriceStep  time.Duration `json:"price_step"`
+	// sealed-bid auction only, the reveal window starts at TimeToSell
+	BidDeposit     sdk.Coins     `json:"bid_deposit"`
+	RevealDuration time.Duration `json:"reveal_duration"`
+	SecondPrice    bool          `json:"second_price"`
+	// English auction only, optional
+	MinBidIncrement     sdk.Coins     `json:"min_bid_increment"`
+	MinBidIncrementRate sdk.Dec       `json:"min_bid_increment_rate"`
+	ExtensionWindow     time.Duration `json:"extension_window"`
+	// ReserveHash is ReservePriceHash of the reserve price and a secret salt, the owner reveals the reserve
+	// price in the reveal window of RevealDuration that starts at TimeToSell
+	ReserveHash string `json:"reserve_hash"`
 }
 
 func NewMsgPutNFTOnAuction(owner, beneficiary sdk.AccAddress, tokenID string,
 	openingPrice, buyoutPrice sdk.Coins, timeToSell time.Time) *MsgPutNFTOnAuction {
 	return &MsgPutNFTOnAuction{
-		Owner:        owner,
-		TokenID:      tokenID,
-		OpeningPrice: openingPrice,
-		BuyoutPrice:  buyoutPrice,
-		Beneficiary:  beneficiary,
-		TimeToSell:   timeToSell,
+		Owner:               owner,
+		TokenID:             tokenID,
+		OpeningPrice:        openingPrice,
+		BuyoutPrice:         buyoutPrice,
+		Beneficiary:         beneficiary,
+		TimeToSell:          timeToSell,
+		AuctionType:         AuctionTypeEnglish,
+		MinBidIncrementRate: sdk.ZeroDec(),
+	}
+}
+
+func NewMsgPutNFTOnDutchAuction(owner, beneficiary sdk.AccAddress, tokenID string,
+	openingPrice, floorPrice sdk.Coins, priceStep time.Duration, timeToSell time.Time) *MsgPutNFTOnAuction {
+	return &MsgPutNFTOnAuction{
+		Owner:               owner,
+		TokenID:             tokenID,
+		OpeningPrice:        openingPrice,
+		Beneficiary:         beneficiary,
+		TimeToSell:          timeToSell,
+		AuctionType:         AuctionTypeDutch,
+		MinBidIncrementRate: sdk.ZeroDec(),
+		FloorPrice:          floorPrice,
+		PriceStep:           priceStep,
+	}
+}
+
+func NewMsgPutNFTOnSealedAuction(owner, beneficiary sdk.AccAddress, tokenID string, openingPrice, bidDeposit sdk.Coins,
+	secondPrice bool, timeToSell time.Time, revealDuration time.Duration) *MsgPutNFTOnAuction {
+	return &MsgPutNFTOnAuction{
+		Owner:               owner,
+		TokenID:             tokenID,
+		OpeningPrice:        openingPrice,
+		Beneficiary:         beneficiary,
+		TimeToSell:          timeToSell,
+		AuctionType:         AuctionTypeSealed,
+		MinBidIncrementRate: sdk.ZeroDec(),
+		BidDeposit:          bidDeposit,
+		RevealDuration:      revealDuration,
+		SecondPrice:         secondPrice,
 	}
 }
 
@@ -417,6 +471,66 @@ func (m MsgPutNFTOnAuction) ValidateBasic() sdk.Error {
 	if m.TimeToSell.IsZero() {
 		return sdk.ErrUnknownRequest("Time cannot be zero")
 	}
+	hasIncrementRate := !m.MinBidIncrementRate.IsNil() && !m.MinBidIncrementRate.IsZero()
+	if m.AuctionType != "" && m.AuctionType != AuctionTypeEnglish &&
+		(!m.MinBidIncrement.Empty() || hasIncrementRate || m.ExtensionWindow != 0 || m.ReserveHash != "") {
+		return sdk.ErrUnknownRequest("Minimum bid increment, extension window and reserve price are only used by English auctions")
+	}
+	if m.AuctionType != AuctionTypeSealed && (!m.BidDeposit.Empty() || m.SecondPrice) {
+		return sdk.ErrUnknownRequest("Bid deposit and second price are only used by sealed-bid auctions")
+	}
+	if m.AuctionType != AuctionTypeSealed && m.ReserveHash == "" && m.RevealDuration != 0 {
+		return sdk.ErrUnknownRequest("Reveal duration is only used by sealed-bid auctions and auctions with a reserve price")
+	}
+	switch m.AuctionType {
+	case "", AuctionTypeEnglish:
+		if !m.FloorPrice.Empty() || m.PriceStep != 0 {
+			return sdk.ErrUnknownRequest("Floor price and price step are only used by Dutch auctions")
+		}
+		if !m.MinBidIncrement.IsValid() {
+			return sdk.ErrUnknownRequest("Minimum bid increment is invalid")
+		}
+		if hasIncrementRate && (!m.MinBidIncrement.Empty() || m.MinBidIncrementRate.IsNegative()) {
+			return sdk.ErrUnknownRequest("Minimum bid increment is either absolute or a positive share of the last bid")
+		}
+		if m.ExtensionWindow < 0 {
+			return sdk.ErrUnknownRequest("Extension window cannot be negative")
+		}
+		if m.ReserveHash != "" {
+			if hash, err := hex.DecodeString(m.ReserveHash); err != nil || len(hash) != sha256.Size {
+				return sdk.ErrUnknownRequest("Reserve hash must be a hex encoded SHA-256 hash")
+			}
+			if m.RevealDuration <= 0 {
+				return sdk.ErrUnknownRequest("Reveal duration must be positive")
+			}
+		}
+	case AuctionTypeSealed:
+		if !m.BuyoutPrice.Empty() || !m.FloorPrice.Empty() || m.PriceStep != 0 {
+			return sdk.ErrUnknownRequest("Sealed-bid auction cannot have a buyout price, floor price or price step")
+		}
+		// bids are compared by amount
+		if len(m.OpeningPrice) != 1 {
+			return sdk.ErrUnknownRequest("Opening price of a sealed-bid auction must be in a single denomination")
+		}
+		if !m.BidDeposit.IsValid() || m.BidDeposit.Empty() {
+			return sdk.ErrUnknownRequest("Bid deposit must be positive")
+		}
+		if m.RevealDuration <= 0 {
+			return sdk.ErrUnknownRequest("Reveal duration must be positive")
+		}
+	case AuctionTypeDutch:
+		if !m.BuyoutPrice.Empty() {
+			return sdk.ErrUnknownRequest("Dutch auction cannot have a buyout price")
+		}
+		if !m.FloorPrice.IsValid() || len(m.FloorPrice) != len(m.OpeningPrice) || !m.OpeningPrice.IsAllGT(m.FloorPrice) {
+			return sdk.ErrUnknownRequest("Floor price must be positive and lower than opening price in every denomination")
+		}
+		if m.PriceStep < 0 {
+			return sdk.ErrUnknownRequest("Price step cannot be negative")
+		}
+	default:
+		return sdk.ErrUnknownRequest(fmt.Sprintf("Unknown auction type %s", m.AuctionType))
+	}
 	return nil
 }
 
@@ -485,12 +599,14 @@ type MsgMakeBidOnAuction struct {
 	Bidder sdk.AccAddress `json:"bidder"`
 	// Beneficiary is the cosmos user who gets the commission for this transaction.
 	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission,omitempty"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	TokenID               string         `json:"token_id"`
 	Bid                   sdk.Coins      `json:"bid"`
+	// MaxBid makes a proxy bid: the module raises the visible bid over competing bids up to MaxBid.
+	MaxBid sdk.Coins `json:"max_bid"`
 }
 
-func NewMsgMakeBidOnAuction(bidder, buyerBeneficiary sdk.AccAddress, tokenID string, bid sdk.Coins, commission string) *MsgMakeBidOnAuction {
+func NewMsgMakeBidOnAuction(bidder, buyerBeneficiary sdk.AccAddress, tokenID string, bid sdk.Coins, commission sdk.Dec) *MsgMakeBidOnAuction {
 	return &MsgMakeBidOnAuction{
 		Bidder:                bidder,
 		BuyerBeneficiary:      buyerBeneficiary,
@@ -500,6 +616,21 @@ func NewMsgMakeBidOnAuction(bidder, buyerBeneficiary sdk.AccAddress, tokenID str
 	}
 }
 
+func NewMsgMakeProxyBidOnAuction(bidder, buyerBeneficiary sdk.AccAddress, tokenID string, maxBid sdk.Coins, commission sdk.Dec) *MsgMakeBidOnAuction {
+	return &MsgMakeBidOnAuction{
+		Bidder:                bidder,
+		BuyerBeneficiary:      buyerBeneficiary,
+		TokenID:               tokenID,
+		MaxBid:                maxBid,
+		BeneficiaryCommission: commission,
+	}
+}
+
+// IsProxy reports whether the message makes a proxy bid.
+func (m MsgMakeBidOnAuction) IsProxy() bool {
+	return !m.MaxBid.Empty()
+}
+
 // Route should return the name of the module
 func (m MsgMakeBidOnAuction) Route() string { return RouterKey }
 
@@ -511,12 +642,23 @@ func (m MsgMakeBidOnAuction) ValidateBasic() sdk.Error {
 	if m.Bidder.Empty() {
 		return sdk.ErrInvalidAddress(m.Bidder.String())
 	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
 	if len(m.TokenID) == 0 {
 		return sdk.ErrUnknownRequest("TokenID cannot be empty")
 	}
 	if len(m.TokenID) > MaxTokenIDLength {
 		return sdk.ErrUnknownRequest("TokenID has invalid format")
 	}
+	if m.IsProxy() {
+		if !m.Bid.Empty() {
+			return sdk.ErrUnknownRequest("proxy bid takes a maximum bid only")
+		}
+		if !m.MaxBid.IsValid() {
+			return sdk.ErrInvalidCoins(m.MaxBid.String())
+		}
+	}
 	return nil
 }
 
@@ -575,6 +717,232 @@ func (m MsgFinishAuction) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{m.Owner}
 }
 
+// --------------------------------------------------------------------------
+//
+// MsgCommitSealedBid
+//
+// --------------------------------------------------------------------------
+
+type MsgCommitSealedBid struct {
+	Bidder                sdk.AccAddress `json:"bidder"`
+	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
+	TokenID               string         `json:"token_id"`
+	// BidHash is SealedBidHash of the bidder, the bid and a secret salt
+	BidHash string `json:"bid_hash"`
+}
+
+func NewMsgCommitSealedBid(bidder, buyerBeneficiary sdk.AccAddress, tokenID, bidHash string, commission sdk.Dec) *MsgCommitSealedBid {
+	return &MsgCommitSealedBid{
+		Bidder:                bidder,
+		BuyerBeneficiary:      buyerBeneficiary,
+		BeneficiaryCommission: commission,
+		TokenID:               tokenID,
+		BidHash:               bidHash,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgCommitSealedBid) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgCommitSealedBid) Type() string { return "commit_sealed_bid" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgCommitSealedBid) ValidateBasic() sdk.Error {
+	if m.Bidder.Empty() {
+		return sdk.ErrInvalidAddress(m.Bidder.String())
+	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if hash, err := hex.DecodeString(m.BidHash); err != nil || len(hash) != sha256.Size {
+		return sdk.ErrUnknownRequest("Bid hash must be a hex encoded SHA-256 hash")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgCommitSealedBid) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgCommitSealedBid) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Bidder}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRevealSealedBid
+//
+// --------------------------------------------------------------------------
+
+type MsgRevealSealedBid struct {
+	Bidder  sdk.AccAddress `json:"bidder"`
+	TokenID string         `json:"token_id"`
+	Bid     sdk.Coins      `json:"bid"`
+	Salt    string         `json:"salt"`
+}
+
+func NewMsgRevealSealedBid(bidder sdk.AccAddress, tokenID string, bid sdk.Coins, salt string) *MsgRevealSealedBid {
+	return &MsgRevealSealedBid{
+		Bidder:  bidder,
+		TokenID: tokenID,
+		Bid:     bid,
+		Salt:    salt,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRevealSealedBid) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRevealSealedBid) Type() string { return "reveal_sealed_bid" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRevealSealedBid) ValidateBasic() sdk.Error {
+	if m.Bidder.Empty() {
+		return sdk.ErrInvalidAddress(m.Bidder.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if !m.Bid.IsValid() || m.Bid.Empty() {
+		return sdk.ErrUnknownRequest("Bid must be positive")
+	}
+	if len(m.Salt) == 0 {
+		return sdk.ErrUnknownRequest("Salt cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRevealSealedBid) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRevealSealedBid) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Bidder}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRevealReservePrice
+//
+// --------------------------------------------------------------------------
+
+type MsgRevealReservePrice struct {
+	Owner        sdk.AccAddress `json:"owner"`
+	TokenID      string         `json:"token_id"`
+	ReservePrice sdk.Coins      `json:"reserve_price"`
+	Salt         string         `json:"salt"`
+}
+
+func NewMsgRevealReservePrice(owner sdk.AccAddress, tokenID string, reservePrice sdk.Coins, salt string) *MsgRevealReservePrice {
+	return &MsgRevealReservePrice{
+		Owner:        owner,
+		TokenID:      tokenID,
+		ReservePrice: reservePrice,
+		Salt:         salt,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRevealReservePrice) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRevealReservePrice) Type() string { return "reveal_reserve_price" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRevealReservePrice) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if !m.ReservePrice.IsValid() || m.ReservePrice.Empty() {
+		return sdk.ErrUnknownRequest("Reserve price must be positive")
+	}
+	if len(m.Salt) == 0 {
+		return sdk.ErrUnknownRequest("Salt cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRevealReservePrice) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRevealReservePrice) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgSettleSealedAuction
+//
+// --------------------------------------------------------------------------
+
+// MsgSettleSealedAuction settles a sealed-bid auction after its reveal window, anyone can send it
+type MsgSettleSealedAuction struct {
+	Sender  sdk.AccAddress `json:"sender"`
+	TokenID string         `json:"token_id"`
+}
+
+func NewMsgSettleSealedAuction(sender sdk.AccAddress, tokenID string) *MsgSettleSealedAuction {
+	return &MsgSettleSealedAuction{
+		Sender:  sender,
+		TokenID: tokenID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgSettleSealedAuction) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgSettleSealedAuction) Type() string { return "settle_sealed_auction" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgSettleSealedAuction) ValidateBasic() sdk.Error {
+	if m.Sender.Empty() {
+		return sdk.ErrInvalidAddress(m.Sender.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgSettleSealedAuction) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgSettleSealedAuction) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Sender}
+}
+
 // --------------------------------------------------------------------------
 //
 // MsgBuyoutOnAuction
@@ -584,11 +952,11 @@ func (m MsgFinishAuction) GetSigners() []sdk.AccAddress {
 type MsgBuyoutOnAuction struct {
 	Buyer                 sdk.AccAddress `json:"buyer"`
 	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission,omitempty"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	TokenID               string         `json:"token_id"`
 }
 
-func NewMsgBuyOutOnAuction(bidder, buyerBeneficiary sdk.AccAddress, tokenID string, commission string) *MsgBuyoutOnAuction {
+func NewMsgBuyOutOnAuction(bidder, buyerBeneficiary sdk.AccAddress, tokenID string, commission sdk.Dec) *MsgBuyoutOnAuction {
 	return &MsgBuyoutOnAuction{
 		Buyer:                 bidder,
 		BuyerBeneficiary:      buyerBeneficiary,
@@ -608,6 +976,9 @@ func (m MsgBuyoutOnAuction) ValidateBasic() sdk.Error {
 	if m.Buyer.Empty() {
 		return sdk.ErrInvalidAddress(m.Buyer.String())
 	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
 	if len(m.TokenID) == 0 {
 		return sdk.ErrUnknownRequest("TokenID cannot be empty")
 	}
@@ -813,11 +1184,11 @@ func (m MsgBatchRemoveFromMarket) GetSigners() []sdk.AccAddress {
 type MsgBatchBuyOnMarket struct {
 	Buyer                 sdk.AccAddress `json:"owner"`
 	Beneficiary           sdk.AccAddress `json:"beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission,omitempty"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	TokenIDs              []string       `json:"token_ids"`
 }
 
-func NewMsgBatchBuyOnMarket(buyer, beneficiary sdk.AccAddress, commission string, tokenIDs []string) *MsgBatchBuyOnMarket {
+func NewMsgBatchBuyOnMarket(buyer, beneficiary sdk.AccAddress, commission sdk.Dec, tokenIDs []string) *MsgBatchBuyOnMarket {
 	return &MsgBatchBuyOnMarket{
 		Buyer:                 buyer,
 		Beneficiary:           beneficiary,
@@ -837,6 +1208,9 @@ func (m MsgBatchBuyOnMarket) ValidateBasic() sdk.Error {
 	if m.Buyer.Empty() {
 		return sdk.ErrInvalidAddress(m.Buyer.String())
 	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
 	if len(m.TokenIDs) == 0 {
 		return sdk.ErrUnknownRequest("TokenIDs cannot be empty")
 	}
@@ -870,11 +1244,14 @@ type MsgMakeOffer struct {
 	Buyer                 sdk.AccAddress `json:"buyer"`
 	Price                 sdk.Coins      `json:"price"`
 	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission,omitempty"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	TokenID               string         `json:"token_id"`
+	// the offer is refunded at the expiration time or height, if set
+	ExpirationTime   time.Time `json:"expiration_time"`
+	ExpirationHeight int64     `json:"expiration_height"`
 }
 
-func NewMsgMakeOffer(bidder, buyerBeneficiary sdk.AccAddress, price sdk.Coins, tokenID string, commission string) *MsgMakeOffer {
+func NewMsgMakeOffer(bidder, buyerBeneficiary sdk.AccAddress, price sdk.Coins, tokenID string, commission sdk.Dec) *MsgMakeOffer {
 	return &MsgMakeOffer{
 		Buyer:                 bidder,
 		Price:                 price,
@@ -895,12 +1272,21 @@ func (m MsgMakeOffer) ValidateBasic() sdk.Error {
 	if m.Buyer.Empty() {
 		return sdk.ErrInvalidAddress(m.Buyer.String())
 	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
 	if len(m.TokenID) == 0 {
 		return sdk.ErrUnknownRequest("TokenID cannot be empty")
 	}
 	if len(m.TokenID) > MaxTokenIDLength {
 		return sdk.ErrUnknownRequest("TokenID has invalid format")
 	}
+	if m.ExpirationHeight < 0 {
+		return sdk.ErrUnknownRequest("expiration height cannot be negative")
+	}
+	if !m.ExpirationTime.IsZero() && m.ExpirationHeight != 0 {
+		return sdk.ErrUnknownRequest("offer expires either at a time or at a height")
+	}
 	return nil
 }
 
@@ -923,12 +1309,12 @@ func (m MsgMakeOffer) GetSigners() []sdk.AccAddress {
 type MsgAcceptOffer struct {
 	Seller                sdk.AccAddress `json:"seller"`
 	SellerBeneficiary     sdk.AccAddress `json:"seller_beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission,omitempty"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	TokenID               string         `json:"token_id"`
 	OfferID               string         `json:"offer_id"`
 }
 
-func NewMsgAcceptOffer(seller, sellerBeneficiary sdk.AccAddress, tokenID, offerID string, commission string) *MsgAcceptOffer {
+func NewMsgAcceptOffer(seller, sellerBeneficiary sdk.AccAddress, tokenID, offerID string, commission sdk.Dec) *MsgAcceptOffer {
 	return &MsgAcceptOffer{
 		Seller:                seller,
 		SellerBeneficiary:     sellerBeneficiary,
@@ -949,6 +1335,9 @@ func (m MsgAcceptOffer) ValidateBasic() sdk.Error {
 	if m.Seller.Empty() {
 		return sdk.ErrInvalidAddress(m.Seller.String())
 	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
 	if len(m.TokenID) == 0 {
 		return sdk.ErrUnknownRequest("TokenID cannot be empty")
 	}
@@ -1021,6 +1410,70 @@ func (m MsgRemoveOffer) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{m.Buyer}
 }
 
+// --------------------------------------------------------------------------
+//
+// MsgMintNFTWithRoyalty
+//
+// --------------------------------------------------------------------------
+
+// MsgMintNFTWithRoyalty mints an NFT like nft.MsgMintNFT and makes the sender its creator,
+// who is paid the given share of the price on every resale.
+type MsgMintNFTWithRoyalty struct {
+	Sender    sdk.AccAddress `json:"sender"`
+	Recipient sdk.AccAddress `json:"recipient"`
+	ID        string         `json:"id"`
+	Denom     string         `json:"denom"`
+	TokenURI  string         `json:"token_uri"`
+	Royalty   sdk.Dec        `json:"royalty"`
+}
+
+func NewMsgMintNFTWithRoyalty(sender, recipient sdk.AccAddress, id, denom, tokenURI string,
+	royalty sdk.Dec) *MsgMintNFTWithRoyalty {
+	return &MsgMintNFTWithRoyalty{
+		Sender:    sender,
+		Recipient: recipient,
+		ID:        id,
+		Denom:     denom,
+		TokenURI:  tokenURI,
+		Royalty:   royalty,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgMintNFTWithRoyalty) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgMintNFTWithRoyalty) Type() string { return "mint_nft_with_royalty" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgMintNFTWithRoyalty) ValidateBasic() sdk.Error {
+	if err := m.MintMsg().ValidateBasic(); err != nil {
+		return err
+	}
+	if m.Royalty.IsNil() {
+		return sdk.ErrUnknownRequest("royalty cannot be empty")
+	}
+	if m.Royalty.IsNegative() || m.Royalty.GT(sdk.OneDec()) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("royalty must be between 0 and 1, is %s", m.Royalty))
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgMintNFTWithRoyalty) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgMintNFTWithRoyalty) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Sender}
+}
+
+// MintMsg returns the nft module message minting the same NFT.
+func (m MsgMintNFTWithRoyalty) MintMsg() nft.MsgMintNFT {
+	return nft.NewMsgMintNFT(m.Sender, m.Recipient, m.ID, m.Denom, m.TokenURI)
+}
+
 type MsgTransferNFTByIBC struct {
 	SourcePort    string         `json:"source_port" yaml:"source_port"`       // the port on which the packet will be sent
 	SourceChannel string         `json:"source_channel" yaml:"source_channel"` // the channel by which the packet will be sent
@@ -1067,3 +1520,1075 @@ func (msg MsgTransferNFTByIBC) GetSignBytes() []byte {
 func (msg MsgTransferNFTByIBC) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Sender}
 }
+
+// --------------------------------------------------------------------------
+//
+// MsgCreateBundle
+//
+// --------------------------------------------------------------------------
+
+// MsgCreateBundle bundles NFTs of the owner into a new NFT with the given ID, which can be put
+// on the market or on auction as a single NFT.
+type MsgCreateBundle struct {
+	Owner    sdk.AccAddress `json:"owner"`
+	BundleID string         `json:"bundle_id"`
+	TokenIDs []string       `json:"token_ids"`
+}
+
+func NewMsgCreateBundle(owner sdk.AccAddress, bundleID string, tokenIDs []string) *MsgCreateBundle {
+	return &MsgCreateBundle{
+		Owner:    owner,
+		BundleID: bundleID,
+		TokenIDs: tokenIDs,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgCreateBundle) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgCreateBundle) Type() string { return "create_bundle" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgCreateBundle) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if len(strings.TrimSpace(m.BundleID)) == 0 || len(m.BundleID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("BundleID has invalid format")
+	}
+	if len(m.TokenIDs) < 2 {
+		return sdk.ErrUnknownRequest("bundle must hold at least two NFTs")
+	}
+	if len(m.TokenIDs) > MaxBundleSize {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("bundle cannot hold more than %d NFTs", MaxBundleSize))
+	}
+	seen := make(map[string]bool, len(m.TokenIDs))
+	for _, tokenID := range m.TokenIDs {
+		if len(tokenID) == 0 || len(tokenID) > MaxTokenIDLength {
+			return sdk.ErrUnknownRequest("TokenID has invalid format")
+		}
+		if seen[tokenID] || tokenID == m.BundleID {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("NFT #%s is listed twice", tokenID))
+		}
+		seen[tokenID] = true
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgCreateBundle) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgCreateBundle) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRemoveBundle
+//
+// --------------------------------------------------------------------------
+
+// MsgRemoveBundle deletes a bundle that is not on sale and releases its NFTs to the owner.
+type MsgRemoveBundle struct {
+	Owner    sdk.AccAddress `json:"owner"`
+	BundleID string         `json:"bundle_id"`
+}
+
+func NewMsgRemoveBundle(owner sdk.AccAddress, bundleID string) *MsgRemoveBundle {
+	return &MsgRemoveBundle{
+		Owner:    owner,
+		BundleID: bundleID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRemoveBundle) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRemoveBundle) Type() string { return "remove_bundle" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRemoveBundle) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if len(m.BundleID) == 0 || len(m.BundleID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("BundleID has invalid format")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRemoveBundle) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRemoveBundle) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgMakeCollectionOffer
+//
+// --------------------------------------------------------------------------
+
+// MsgMakeCollectionOffer offers the price for each of Quantity NFTs of the denom, a quantity of 0 is taken as 1.
+type MsgMakeCollectionOffer struct {
+	Buyer                 sdk.AccAddress `json:"buyer"`
+	Denom                 string         `json:"denom"`
+	Price                 sdk.Coins      `json:"price"`
+	Quantity              uint64         `json:"quantity"`
+	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
+}
+
+func NewMsgMakeCollectionOffer(buyer, buyerBeneficiary sdk.AccAddress, denom string, price sdk.Coins, quantity uint64,
+	commission sdk.Dec) *MsgMakeCollectionOffer {
+	return &MsgMakeCollectionOffer{
+		Buyer:                 buyer,
+		Denom:                 denom,
+		Price:                 price,
+		Quantity:              quantity,
+		BuyerBeneficiary:      buyerBeneficiary,
+		BeneficiaryCommission: commission,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgMakeCollectionOffer) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgMakeCollectionOffer) Type() string { return "make_collection_offer" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgMakeCollectionOffer) ValidateBasic() sdk.Error {
+	if m.Buyer.Empty() {
+		return sdk.ErrInvalidAddress(m.Buyer.String())
+	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(m.Denom)) == 0 {
+		return sdk.ErrUnknownRequest("Denom cannot be empty")
+	}
+	if m.Price.Empty() || !m.Price.IsValid() {
+		return sdk.ErrInvalidCoins(m.Price.String())
+	}
+	if m.Quantity > MaxOfferQuantity {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("quantity cannot be more than %d", MaxOfferQuantity))
+	}
+	return nil
+}
+
+// GetQuantity returns the number of NFTs wanted.
+func (m MsgMakeCollectionOffer) GetQuantity() uint64 {
+	if m.Quantity == 0 {
+		return 1
+	}
+	return m.Quantity
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgMakeCollectionOffer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgMakeCollectionOffer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Buyer}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgFillCollectionOffer
+//
+// --------------------------------------------------------------------------
+
+// MsgFillCollectionOffer sells an NFT of the seller to a collection offer for its denom.
+type MsgFillCollectionOffer struct {
+	Seller                sdk.AccAddress `json:"seller"`
+	SellerBeneficiary     sdk.AccAddress `json:"seller_beneficiary"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
+	TokenID               string         `json:"token_id"`
+	OfferID               string         `json:"offer_id"`
+}
+
+func NewMsgFillCollectionOffer(seller, sellerBeneficiary sdk.AccAddress, tokenID, offerID string,
+	commission sdk.Dec) *MsgFillCollectionOffer {
+	return &MsgFillCollectionOffer{
+		Seller:                seller,
+		SellerBeneficiary:     sellerBeneficiary,
+		TokenID:               tokenID,
+		OfferID:               offerID,
+		BeneficiaryCommission: commission,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgFillCollectionOffer) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgFillCollectionOffer) Type() string { return "fill_collection_offer" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgFillCollectionOffer) ValidateBasic() sdk.Error {
+	if m.Seller.Empty() {
+		return sdk.ErrInvalidAddress(m.Seller.String())
+	}
+	if err := validateCommission(m.BeneficiaryCommission); err != nil {
+		return err
+	}
+	if len(m.OfferID) == 0 {
+		return sdk.ErrUnknownRequest("OfferID cannot be empty")
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgFillCollectionOffer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgFillCollectionOffer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Seller}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRemoveCollectionOffer
+//
+// --------------------------------------------------------------------------
+
+// MsgRemoveCollectionOffer removes a collection offer and refunds the coins locked for the NFTs still wanted.
+type MsgRemoveCollectionOffer struct {
+	Buyer   sdk.AccAddress `json:"buyer"`
+	OfferID string         `json:"offer_id"`
+}
+
+func NewMsgRemoveCollectionOffer(buyer sdk.AccAddress, offerID string) *MsgRemoveCollectionOffer {
+	return &MsgRemoveCollectionOffer{
+		Buyer:   buyer,
+		OfferID: offerID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRemoveCollectionOffer) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRemoveCollectionOffer) Type() string { return "remove_collection_offer" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRemoveCollectionOffer) ValidateBasic() sdk.Error {
+	if m.Buyer.Empty() {
+		return sdk.ErrInvalidAddress(m.Buyer.String())
+	}
+	if len(m.OfferID) == 0 {
+		return sdk.ErrUnknownRequest("OfferID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRemoveCollectionOffer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRemoveCollectionOffer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Buyer}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgProposeSwap
+//
+// --------------------------------------------------------------------------
+
+// MsgProposeSwap proposes to exchange NFTs and coins of the proposer for NFTs and coins of the counterparty.
+// The swap is cancelled at the expiration time, if set.
+type MsgProposeSwap struct {
+	Proposer          sdk.AccAddress `json:"proposer"`
+	Counterparty      sdk.AccAddress `json:"counterparty"`
+	ProposerNFTs      []string       `json:"proposer_nfts"`
+	ProposerCoins     sdk.Coins      `json:"proposer_coins"`
+	CounterpartyNFTs  []string       `json:"counterparty_nfts"`
+	CounterpartyCoins sdk.Coins      `json:"counterparty_coins"`
+	ExpirationTime    time.Time      `json:"expiration_time"`
+}
+
+func NewMsgProposeSwap(proposer, counterparty sdk.AccAddress, proposerNFTs []string, proposerCoins sdk.Coins,
+	counterpartyNFTs []string, counterpartyCoins sdk.Coins) *MsgProposeSwap {
+	return &MsgProposeSwap{
+		Proposer:          proposer,
+		Counterparty:      counterparty,
+		ProposerNFTs:      proposerNFTs,
+		ProposerCoins:     proposerCoins,
+		CounterpartyNFTs:  counterpartyNFTs,
+		CounterpartyCoins: counterpartyCoins,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgProposeSwap) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgProposeSwap) Type() string { return "propose_swap" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgProposeSwap) ValidateBasic() sdk.Error {
+	if m.Proposer.Empty() {
+		return sdk.ErrInvalidAddress(m.Proposer.String())
+	}
+	if m.Counterparty.Empty() {
+		return sdk.ErrInvalidAddress(m.Counterparty.String())
+	}
+	if m.Proposer.Equals(m.Counterparty) {
+		return sdk.ErrUnknownRequest("proposer and counterparty must differ")
+	}
+	if !m.ProposerCoins.IsValid() {
+		return sdk.ErrInvalidCoins(m.ProposerCoins.String())
+	}
+	if !m.CounterpartyCoins.IsValid() {
+		return sdk.ErrInvalidCoins(m.CounterpartyCoins.String())
+	}
+	if len(m.ProposerNFTs) == 0 && m.ProposerCoins.Empty() {
+		return sdk.ErrUnknownRequest("proposer must give NFTs or coins")
+	}
+	if len(m.CounterpartyNFTs) == 0 && m.CounterpartyCoins.Empty() {
+		return sdk.ErrUnknownRequest("counterparty must give NFTs or coins")
+	}
+	if len(m.ProposerNFTs) == 0 && len(m.CounterpartyNFTs) == 0 {
+		return sdk.ErrUnknownRequest("swap must exchange at least one NFT")
+	}
+	if len(m.ProposerNFTs) > MaxSwapSize || len(m.CounterpartyNFTs) > MaxSwapSize {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("swap cannot exchange more than %d NFTs per side", MaxSwapSize))
+	}
+	seen := make(map[string]bool, len(m.ProposerNFTs)+len(m.CounterpartyNFTs))
+	for _, tokenID := range append(append([]string{}, m.ProposerNFTs...), m.CounterpartyNFTs...) {
+		if len(tokenID) == 0 || len(tokenID) > MaxTokenIDLength {
+			return sdk.ErrUnknownRequest("TokenID has invalid format")
+		}
+		if seen[tokenID] {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("NFT #%s is listed twice", tokenID))
+		}
+		seen[tokenID] = true
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgProposeSwap) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgProposeSwap) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Proposer}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgAcceptSwap
+//
+// --------------------------------------------------------------------------
+
+// MsgAcceptSwap accepts a swap proposed to the counterparty, both sides are exchanged at once.
+type MsgAcceptSwap struct {
+	Counterparty sdk.AccAddress `json:"counterparty"`
+	SwapID       string         `json:"swap_id"`
+}
+
+func NewMsgAcceptSwap(counterparty sdk.AccAddress, swapID string) *MsgAcceptSwap {
+	return &MsgAcceptSwap{
+		Counterparty: counterparty,
+		SwapID:       swapID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgAcceptSwap) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgAcceptSwap) Type() string { return "accept_swap" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgAcceptSwap) ValidateBasic() sdk.Error {
+	if m.Counterparty.Empty() {
+		return sdk.ErrInvalidAddress(m.Counterparty.String())
+	}
+	if len(m.SwapID) == 0 {
+		return sdk.ErrUnknownRequest("SwapID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgAcceptSwap) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgAcceptSwap) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Counterparty}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgCancelSwap
+//
+// --------------------------------------------------------------------------
+
+// MsgCancelSwap cancels a swap of the proposer and returns the NFTs and coins it holds.
+type MsgCancelSwap struct {
+	Proposer sdk.AccAddress `json:"proposer"`
+	SwapID   string         `json:"swap_id"`
+}
+
+func NewMsgCancelSwap(proposer sdk.AccAddress, swapID string) *MsgCancelSwap {
+	return &MsgCancelSwap{
+		Proposer: proposer,
+		SwapID:   swapID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgCancelSwap) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgCancelSwap) Type() string { return "cancel_swap" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgCancelSwap) ValidateBasic() sdk.Error {
+	if m.Proposer.Empty() {
+		return sdk.ErrInvalidAddress(m.Proposer.String())
+	}
+	if len(m.SwapID) == 0 {
+		return sdk.ErrUnknownRequest("SwapID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgCancelSwap) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgCancelSwap) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Proposer}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgHashLockNFT
+//
+// --------------------------------------------------------------------------
+
+// MsgHashLockNFT locks an NFT of the owner for the recipient against a SHA-256 hash lock until the timeout.
+type MsgHashLockNFT struct {
+	Owner     sdk.AccAddress `json:"owner"`
+	Recipient sdk.AccAddress `json:"recipient"`
+	TokenID   string         `json:"token_id"`
+	HashLock  string         `json:"hash_lock"`
+	Timeout   time.Time      `json:"timeout"`
+}
+
+func NewMsgHashLockNFT(owner, recipient sdk.AccAddress, tokenID, hashLock string, timeout time.Time) *MsgHashLockNFT {
+	return &MsgHashLockNFT{
+		Owner:     owner,
+		Recipient: recipient,
+		TokenID:   tokenID,
+		HashLock:  hashLock,
+		Timeout:   timeout,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgHashLockNFT) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgHashLockNFT) Type() string { return "hash_lock_nft" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgHashLockNFT) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if m.Recipient.Empty() {
+		return sdk.ErrInvalidAddress(m.Recipient.String())
+	}
+	if m.Owner.Equals(m.Recipient) {
+		return sdk.ErrUnknownRequest("owner and recipient must differ")
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if hash, err := hex.DecodeString(m.HashLock); err != nil || len(hash) != sha256.Size {
+		return sdk.ErrUnknownRequest("Hash lock must be a hex encoded SHA-256 hash")
+	}
+	if m.Timeout.IsZero() {
+		return sdk.ErrUnknownRequest("Timeout cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgHashLockNFT) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgHashLockNFT) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgClaimHashLockedNFT
+//
+// --------------------------------------------------------------------------
+
+// MsgClaimHashLockedNFT transfers a hash-locked NFT to its recipient, the hex encoded preimage of the hash lock
+// is revealed in the events.
+type MsgClaimHashLockedNFT struct {
+	Recipient sdk.AccAddress `json:"recipient"`
+	TokenID   string         `json:"token_id"`
+	Preimage  string         `json:"preimage"`
+}
+
+func NewMsgClaimHashLockedNFT(recipient sdk.AccAddress, tokenID, preimage string) *MsgClaimHashLockedNFT {
+	return &MsgClaimHashLockedNFT{
+		Recipient: recipient,
+		TokenID:   tokenID,
+		Preimage:  preimage,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgClaimHashLockedNFT) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgClaimHashLockedNFT) Type() string { return "claim_hash_locked_nft" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgClaimHashLockedNFT) ValidateBasic() sdk.Error {
+	if m.Recipient.Empty() {
+		return sdk.ErrInvalidAddress(m.Recipient.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if preimage, err := hex.DecodeString(m.Preimage); err != nil || len(preimage) == 0 || len(preimage) > MaxPreimageSize {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("Preimage must be hex encoded and hold 1 to %d bytes", MaxPreimageSize))
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgClaimHashLockedNFT) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgClaimHashLockedNFT) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Recipient}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgReclaimHashLockedNFT
+//
+// --------------------------------------------------------------------------
+
+// MsgReclaimHashLockedNFT unlocks a hash-locked NFT of the owner after the timeout.
+type MsgReclaimHashLockedNFT struct {
+	Owner   sdk.AccAddress `json:"owner"`
+	TokenID string         `json:"token_id"`
+}
+
+func NewMsgReclaimHashLockedNFT(owner sdk.AccAddress, tokenID string) *MsgReclaimHashLockedNFT {
+	return &MsgReclaimHashLockedNFT{
+		Owner:   owner,
+		TokenID: tokenID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgReclaimHashLockedNFT) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgReclaimHashLockedNFT) Type() string { return "reclaim_hash_locked_nft" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgReclaimHashLockedNFT) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgReclaimHashLockedNFT) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgReclaimHashLockedNFT) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgListNFTForRent
+//
+// --------------------------------------------------------------------------
+
+// MsgListNFTForRent offers the usage rights of an NFT for rent, listing it again replaces the listing.
+type MsgListNFTForRent struct {
+	Owner       sdk.AccAddress `json:"owner"`
+	TokenID     string         `json:"token_id"`
+	Price       sdk.Coins      `json:"price"`
+	Unit        string         `json:"unit"`
+	MaxDuration uint64         `json:"max_duration"`
+}
+
+func NewMsgListNFTForRent(owner sdk.AccAddress, tokenID string, price sdk.Coins, unit string,
+	maxDuration uint64) *MsgListNFTForRent {
+	return &MsgListNFTForRent{
+		Owner:       owner,
+		TokenID:     tokenID,
+		Price:       price,
+		Unit:        unit,
+		MaxDuration: maxDuration,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgListNFTForRent) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgListNFTForRent) Type() string { return "list_nft_for_rent" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgListNFTForRent) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if m.Price.Empty() || !m.Price.IsValid() {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("invalid rental price: %s", m.Price))
+	}
+	if !IsValidRentalUnit(m.Unit) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("rental unit must be %s or %s, is %s",
+			RentalUnitBlock, RentalUnitHour, m.Unit))
+	}
+	if m.MaxDuration == 0 || m.MaxDuration > MaxRentalDuration {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("max rental duration must be between 1 and %d", MaxRentalDuration))
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgListNFTForRent) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgListNFTForRent) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRemoveRentalListing
+//
+// --------------------------------------------------------------------------
+
+// MsgRemoveRentalListing removes the rental listing of an NFT, a current rental is not affected.
+type MsgRemoveRentalListing struct {
+	Owner   sdk.AccAddress `json:"owner"`
+	TokenID string         `json:"token_id"`
+}
+
+func NewMsgRemoveRentalListing(owner sdk.AccAddress, tokenID string) *MsgRemoveRentalListing {
+	return &MsgRemoveRentalListing{
+		Owner:   owner,
+		TokenID: tokenID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRemoveRentalListing) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRemoveRentalListing) Type() string { return "remove_rental_listing" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRemoveRentalListing) ValidateBasic() sdk.Error {
+	if m.Owner.Empty() {
+		return sdk.ErrInvalidAddress(m.Owner.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRemoveRentalListing) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRemoveRentalListing) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Owner}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRentNFT
+//
+// --------------------------------------------------------------------------
+
+// MsgRentNFT rents a listed NFT for the duration given in the unit of the listing.
+type MsgRentNFT struct {
+	Renter   sdk.AccAddress `json:"renter"`
+	TokenID  string         `json:"token_id"`
+	Duration uint64         `json:"duration"`
+}
+
+func NewMsgRentNFT(renter sdk.AccAddress, tokenID string, duration uint64) *MsgRentNFT {
+	return &MsgRentNFT{
+		Renter:   renter,
+		TokenID:  tokenID,
+		Duration: duration,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRentNFT) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRentNFT) Type() string { return "rent_nft" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRentNFT) ValidateBasic() sdk.Error {
+	if m.Renter.Empty() {
+		return sdk.ErrInvalidAddress(m.Renter.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if m.Duration == 0 || m.Duration > MaxRentalDuration {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("rental duration must be between 1 and %d", MaxRentalDuration))
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRentNFT) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRentNFT) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Renter}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRequestLoan
+//
+// --------------------------------------------------------------------------
+
+// MsgRequestLoan puts an NFT of the borrower up as collateral for a loan of the principal for the duration.
+type MsgRequestLoan struct {
+	Borrower  sdk.AccAddress `json:"borrower"`
+	TokenID   string         `json:"token_id"`
+	Principal sdk.Coins      `json:"principal"`
+	Interest  sdk.Coins      `json:"interest"`
+	Duration  time.Duration  `json:"duration"`
+}
+
+func NewMsgRequestLoan(borrower sdk.AccAddress, tokenID string, principal, interest sdk.Coins,
+	duration time.Duration) *MsgRequestLoan {
+	return &MsgRequestLoan{
+		Borrower:  borrower,
+		TokenID:   tokenID,
+		Principal: principal,
+		Interest:  interest,
+		Duration:  duration,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRequestLoan) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRequestLoan) Type() string { return "request_loan" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRequestLoan) ValidateBasic() sdk.Error {
+	if m.Borrower.Empty() {
+		return sdk.ErrInvalidAddress(m.Borrower.String())
+	}
+	if len(m.TokenID) == 0 {
+		return sdk.ErrUnknownRequest("TokenID cannot be empty")
+	}
+	if len(m.TokenID) > MaxTokenIDLength {
+		return sdk.ErrUnknownRequest("TokenID has invalid format")
+	}
+	if m.Principal.Empty() || !m.Principal.IsValid() {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("invalid principal: %s", m.Principal))
+	}
+	if !m.Interest.IsValid() {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("invalid interest: %s", m.Interest))
+	}
+	if m.Duration <= 0 {
+		return sdk.ErrUnknownRequest("loan duration must be positive")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRequestLoan) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRequestLoan) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Borrower}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgCancelLoanRequest
+//
+// --------------------------------------------------------------------------
+
+// MsgCancelLoanRequest returns the collateral of a loan that has not been funded to the borrower.
+type MsgCancelLoanRequest struct {
+	Borrower sdk.AccAddress `json:"borrower"`
+	LoanID   string         `json:"loan_id"`
+}
+
+func NewMsgCancelLoanRequest(borrower sdk.AccAddress, loanID string) *MsgCancelLoanRequest {
+	return &MsgCancelLoanRequest{
+		Borrower: borrower,
+		LoanID:   loanID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgCancelLoanRequest) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgCancelLoanRequest) Type() string { return "cancel_loan_request" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgCancelLoanRequest) ValidateBasic() sdk.Error {
+	if m.Borrower.Empty() {
+		return sdk.ErrInvalidAddress(m.Borrower.String())
+	}
+	if len(m.LoanID) == 0 {
+		return sdk.ErrUnknownRequest("LoanID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgCancelLoanRequest) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgCancelLoanRequest) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Borrower}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgFundLoan
+//
+// --------------------------------------------------------------------------
+
+// MsgFundLoan pays the principal of a requested loan to the borrower, the deadline starts running.
+type MsgFundLoan struct {
+	Lender sdk.AccAddress `json:"lender"`
+	LoanID string         `json:"loan_id"`
+}
+
+func NewMsgFundLoan(lender sdk.AccAddress, loanID string) *MsgFundLoan {
+	return &MsgFundLoan{
+		Lender: lender,
+		LoanID: loanID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgFundLoan) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgFundLoan) Type() string { return "fund_loan" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgFundLoan) ValidateBasic() sdk.Error {
+	if m.Lender.Empty() {
+		return sdk.ErrInvalidAddress(m.Lender.String())
+	}
+	if len(m.LoanID) == 0 {
+		return sdk.ErrUnknownRequest("LoanID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgFundLoan) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgFundLoan) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Lender}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgRepayLoan
+//
+// --------------------------------------------------------------------------
+
+// MsgRepayLoan pays the principal and the interest to the lender before the deadline and returns the collateral.
+type MsgRepayLoan struct {
+	Borrower sdk.AccAddress `json:"borrower"`
+	LoanID   string         `json:"loan_id"`
+}
+
+func NewMsgRepayLoan(borrower sdk.AccAddress, loanID string) *MsgRepayLoan {
+	return &MsgRepayLoan{
+		Borrower: borrower,
+		LoanID:   loanID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgRepayLoan) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgRepayLoan) Type() string { return "repay_loan" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgRepayLoan) ValidateBasic() sdk.Error {
+	if m.Borrower.Empty() {
+		return sdk.ErrInvalidAddress(m.Borrower.String())
+	}
+	if len(m.LoanID) == 0 {
+		return sdk.ErrUnknownRequest("LoanID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgRepayLoan) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgRepayLoan) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Borrower}
+}
+
+// --------------------------------------------------------------------------
+//
+// MsgClaimLoanCollateral
+//
+// --------------------------------------------------------------------------
+
+// MsgClaimLoanCollateral transfers the collateral of a loan that was not repaid by the deadline to the lender.
+type MsgClaimLoanCollateral struct {
+	Lender sdk.AccAddress `json:"lender"`
+	LoanID string         `json:"loan_id"`
+}
+
+func NewMsgClaimLoanCollateral(lender sdk.AccAddress, loanID string) *MsgClaimLoanCollateral {
+	return &MsgClaimLoanCollateral{
+		Lender: lender,
+		LoanID: loanID,
+	}
+}
+
+// Route should return the name of the module
+func (m MsgClaimLoanCollateral) Route() string { return RouterKey }
+
+// Type should return the action
+func (m MsgClaimLoanCollateral) Type() string { return "claim_loan_collateral" }
+
+// ValidateBasic runs stateless checks on the message
+func (m MsgClaimLoanCollateral) ValidateBasic() sdk.Error {
+	if m.Lender.Empty() {
+		return sdk.ErrInvalidAddress(m.Lender.String())
+	}
+	if len(m.LoanID) == 0 {
+		return sdk.ErrUnknownRequest("LoanID cannot be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (m MsgClaimLoanCollateral) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+// GetSigners defines whose signature is required
+func (m MsgClaimLoanCollateral) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Lender}
+}
+
+// validateCommission checks that a beneficiary commission is a share of the price between 0 and 1.
+// The MaxBeneficiaryCommission param is checked by the handlers.
+func validateCommission(commission sdk.Dec) sdk.Error {
+	if commission.IsNil() {
+		return sdk.ErrUnknownRequest("beneficiary commission cannot be empty")
+	}
+	if commission.IsNegative() || commission.GT(sdk.OneDec()) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("beneficiary commission must be between 0 and 1, is %s", commission))
+	}
+	return nil
+}
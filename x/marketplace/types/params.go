@@ -0,0 +1,150 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+)
+
+// DefaultParamspace defines the default marketplace module parameter subspace
+const DefaultParamspace = ModuleName
+
+// Parameter store keys
+var (
+	KeyValidatorsCommission       = []byte("ValidatorsCommission")
+	KeyBeneficiariesCommission    = []byte("BeneficiariesCommission")
+	KeyMaxBeneficiaryCommission   = []byte("MaxBeneficiaryCommission")
+	KeyFungibleTokenCreationPrice = []byte("FungibleTokenCreationPrice")
+	KeyMaxOffersPerNFT            = []byte("MaxOffersPerNFT")
+	KeyMinAuctionDuration         = []byte("MinAuctionDuration")
+	KeyMaxAuctionDuration         = []byte("MaxAuctionDuration")
+	KeyMaxRoyalty                 = []byte("MaxRoyalty")
+	KeyUnrevealedBidForfeit       = []byte("UnrevealedBidForfeit")
+	KeyBidHistoryRetention        = []byte("BidHistoryRetention")
+)
+
+// marketplace parameters, changeable through governance only
+type Params struct {
+	ValidatorsCommission       sdk.Dec       `json:"validators_commission" yaml:"validators_commission"`                 // share of the price paid to validators
+	BeneficiariesCommission    sdk.Dec       `json:"beneficiaries_commission" yaml:"beneficiaries_commission"`           // default share of the price paid to beneficiaries
+	MaxBeneficiaryCommission   sdk.Dec       `json:"max_beneficiary_commission" yaml:"max_beneficiary_commission"`       // maximum share of the price paid to beneficiaries
+	FungibleTokenCreationPrice sdk.Coins     `json:"fungible_token_creation_price" yaml:"fungible_token_creation_price"` // price of creating a fungible token
+	MaxOffersPerNFT            uint64        `json:"max_offers_per_nft" yaml:"max_offers_per_nft"`                       // maximum number of open offers for an NFT
+	MinAuctionDuration         time.Duration `json:"min_auction_duration" yaml:"min_auction_duration"`                   // minimum time between putting an NFT on auction and its end
+	MaxAuctionDuration         time.Duration `json:"max_auction_duration" yaml:"max_auction_duration"`                   // maximum time between putting an NFT on auction and its end
+	MaxRoyalty                 sdk.Dec       `json:"max_royalty" yaml:"max_royalty"`                                     // maximum share of the price paid to the creator of an NFT
+	UnrevealedBidForfeit       sdk.Dec       `json:"unrevealed_bid_forfeit" yaml:"unrevealed_bid_forfeit"`               // share of the deposit of a sealed bid paid to the seller if the bid is not revealed
+	BidHistoryRetention        uint64        `json:"bid_history_retention" yaml:"bid_history_retention"`                 // number of blocks the bid history of a finished auction is kept for
+}
+
+// ParamKeyTable for marketplace module
+func ParamKeyTable() params.KeyTable {
+	return params.NewKeyTable().RegisterParamSet(&Params{})
+}
+
+func NewParams(validatorsCommission, beneficiariesCommission, maxBeneficiaryCommission sdk.Dec,
+	fungibleTokenCreationPrice sdk.Coins, maxOffersPerNFT uint64, minAuctionDuration, maxAuctionDuration time.Duration,
+	maxRoyalty, unrevealedBidForfeit sdk.Dec, bidHistoryRetention uint64) Params {
+
+	return Params{
+		ValidatorsCommission:       validatorsCommission,
+		BeneficiariesCommission:    beneficiariesCommission,
+		MaxBeneficiaryCommission:   maxBeneficiaryCommission,
+		FungibleTokenCreationPrice: fungibleTokenCreationPrice,
+		MaxOffersPerNFT:            maxOffersPerNFT,
+		MinAuctionDuration:         minAuctionDuration,
+		MaxAuctionDuration:         maxAuctionDuration,
+		MaxRoyalty:                 maxRoyalty,
+		UnrevealedBidForfeit:       unrevealedBidForfeit,
+		BidHistoryRetention:        bidHistoryRetention,
+	}
+}
+
+// default marketplace module parameters
+func DefaultParams() Params {
+	return Params{
+		ValidatorsCommission:       sdk.NewDecWithPrec(1, 2),
+		BeneficiariesCommission:    sdk.NewDecWithPrec(15, 3),
+		MaxBeneficiaryCommission:   sdk.NewDecWithPrec(5, 2),
+		FungibleTokenCreationPrice: sdk.NewCoins(sdk.NewCoin(DefaultTokenDenom, sdk.NewInt(10))),
+		MaxOffersPerNFT:            100,
+		MinAuctionDuration:         time.Minute,
+		MaxAuctionDuration:         30 * 24 * time.Hour,
+		MaxRoyalty:                 sdk.NewDecWithPrec(1, 1),
+		UnrevealedBidForfeit:       sdk.NewDecWithPrec(5, 1),
+		BidHistoryRetention:        100800, // a week of 6 second blocks
+	}
+}
+
+// validate params
+func ValidateParams(params Params) error {
+	if params.ValidatorsCommission.IsNegative() || params.ValidatorsCommission.GT(sdk.OneDec()) {
+		return fmt.Errorf("marketplace parameter ValidatorsCommission must be between 0 and 1, is %s", params.ValidatorsCommission)
+	}
+	if params.MaxBeneficiaryCommission.IsNegative() || params.MaxBeneficiaryCommission.GT(sdk.OneDec()) {
+		return fmt.Errorf("marketplace parameter MaxBeneficiaryCommission must be between 0 and 1, is %s", params.MaxBeneficiaryCommission)
+	}
+	if params.BeneficiariesCommission.IsNegative() || params.BeneficiariesCommission.GT(params.MaxBeneficiaryCommission) {
+		return fmt.Errorf("marketplace parameter BeneficiariesCommission must be between 0 and MaxBeneficiaryCommission, is %s",
+			params.BeneficiariesCommission)
+	}
+	if params.MaxRoyalty.IsNegative() || params.MaxRoyalty.GT(sdk.OneDec()) {
+		return fmt.Errorf("marketplace parameter MaxRoyalty must be between 0 and 1, is %s", params.MaxRoyalty)
+	}
+	if params.ValidatorsCommission.Add(params.MaxBeneficiaryCommission).Add(params.MaxRoyalty).GT(sdk.OneDec()) {
+		return fmt.Errorf("marketplace parameters ValidatorsCommission, MaxBeneficiaryCommission and MaxRoyalty must not exceed 1 in total")
+	}
+	if params.UnrevealedBidForfeit.IsNegative() || params.UnrevealedBidForfeit.GT(sdk.OneDec()) {
+		return fmt.Errorf("marketplace parameter UnrevealedBidForfeit must be between 0 and 1, is %s", params.UnrevealedBidForfeit)
+	}
+	if !params.FungibleTokenCreationPrice.IsValid() {
+		return fmt.Errorf("marketplace parameter FungibleTokenCreationPrice is invalid: %s", params.FungibleTokenCreationPrice)
+	}
+	if params.MaxOffersPerNFT == 0 {
+		return fmt.Errorf("marketplace parameter MaxOffersPerNFT must be positive")
+	}
+	if params.MinAuctionDuration < 0 {
+		return fmt.Errorf("marketplace parameter MinAuctionDuration must not be negative, is %s", params.MinAuctionDuration)
+	}
+	if params.MaxAuctionDuration < params.MinAuctionDuration {
+		return fmt.Errorf("marketplace parameter MaxAuctionDuration must be greater than or equal to MinAuctionDuration")
+	}
+	return nil
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf(`Marketplace Params:
+  Validators Commission:         %s
+  Beneficiaries Commission:      %s
+  Max Beneficiary Commission:    %s
+  Fungible Token Creation Price: %s
+  Max Offers Per NFT:            %d
+  Min Auction Duration:          %s
+  Max Auction Duration:          %s
+  Max Royalty:                   %s
+  Unrevealed Bid Forfeit:        %s
+  Bid History Retention:         %d
+`,
+		p.ValidatorsCommission, p.BeneficiariesCommission, p.MaxBeneficiaryCommission,
+		p.FungibleTokenCreationPrice, p.MaxOffersPerNFT, p.MinAuctionDuration, p.MaxAuctionDuration,
+		p.MaxRoyalty, p.UnrevealedBidForfeit, p.BidHistoryRetention,
+	)
+}
+
+// Implements params.ParamSet
+func (p *Params) ParamSetPairs() params.ParamSetPairs {
+	return params.ParamSetPairs{
+		{Key: KeyValidatorsCommission, Value: &p.ValidatorsCommission},
+		{Key: KeyBeneficiariesCommission, Value: &p.BeneficiariesCommission},
+		{Key: KeyMaxBeneficiaryCommission, Value: &p.MaxBeneficiaryCommission},
+		{Key: KeyFungibleTokenCreationPrice, Value: &p.FungibleTokenCreationPrice},
+		{Key: KeyMaxOffersPerNFT, Value: &p.MaxOffersPerNFT},
+		{Key: KeyMinAuctionDuration, Value: &p.MinAuctionDuration},
+		{Key: KeyMaxAuctionDuration, Value: &p.MaxAuctionDuration},
+		{Key: KeyMaxRoyalty, Value: &p.MaxRoyalty},
+		{Key: KeyUnrevealedBidForfeit, Value: &p.UnrevealedBidForfeit},
+		{Key: KeyBidHistoryRetention, Value: &p.BidHistoryRetention},
+	}
+}
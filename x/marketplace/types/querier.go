@@ -1,9 +1,156 @@
 package types
 
-import "strings"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// sort orders of the NFTs query, NFTs are sorted by ID by default
+const (
+	SortByPrice       = "price"
+	SortByTimeCreated = "time_created"
+)
+
+const (
+	DefaultQueryNFTsLimit = 100
+	MaxQueryNFTsLimit     = 1000
+)
+
+const maxInt = int(^uint(0) >> 1)
+
+// QueryNFTsParams selects a page of the NFTs matching the filters. Empty filters match any NFT.
+type QueryNFTsParams struct {
+	Page     int            `json:"page"`  // page number, starting from 1
+	Limit    int            `json:"limit"` // number of NFTs per page
+	Owner    sdk.AccAddress `json:"owner"`
+	Status   string         `json:"status"` // one of "default", "on_market", "on_auction"
+	Denom    string         `json:"denom"`
+	MinPrice sdk.Coins      `json:"min_price"`
+	MaxPrice sdk.Coins      `json:"max_price"`
+	SortBy   string         `json:"sort_by"`
+}
+
+func NewQueryNFTsParams(page, limit int) QueryNFTsParams {
+	return QueryNFTsParams{
+		Page:  page,
+		Limit: limit,
+	}
+}
+
+func (p QueryNFTsParams) Validate() error {
+	if p.Page < 1 {
+		return fmt.Errorf("page must be positive, is %d", p.Page)
+	}
+	if p.Limit < 1 || p.Limit > MaxQueryNFTsLimit {
+		return fmt.Errorf("limit must be between 1 and %d, is %d", MaxQueryNFTsLimit, p.Limit)
+	}
+	if p.Page > maxInt/p.Limit {
+		return fmt.Errorf("page %d is out of range", p.Page)
+	}
+	if _, ok := p.GetStatus(); p.Status != "" && !ok {
+		return fmt.Errorf("unknown status %s", p.Status)
+	}
+	if !p.MinPrice.IsValid() || !p.MaxPrice.IsValid() {
+		return fmt.Errorf("invalid price range %s - %s", p.MinPrice, p.MaxPrice)
+	}
+	switch p.SortBy {
+	case "", SortByPrice, SortByTimeCreated:
+	default:
+		return fmt.Errorf("unknown sort order %s", p.SortBy)
+	}
+	return nil
+}
+
+// queryStatuses are the statuses the NFTs can be filtered by
+var queryStatuses = []NFTStatus{
+	NFTStatusDefault,
+	NFTStatusOnMarket,
+	NFTStatusOnAuction,
+	NFTStatusInBundle,
+	NFTStatusInSwap,
+	NFTStatusHashLocked,
+	NFTStatusRented,
+	NFTStatusCollateral,
+}
+
+// GetStatus returns the status the NFTs are filtered by, false if they are not.
+func (p QueryNFTsParams) GetStatus() (NFTStatus, bool) {
+	for _, status := range queryStatuses {
+		if p.Status == status.String() {
+			return status, true
+		}
+	}
+	return NFTStatusUndefined, false
+}
+
+// Matches returns true if the NFT passes all the filters.
+func (p QueryNFTsParams) Matches(nft *NFT) bool {
+	if !p.Owner.Empty() && !nft.Owner.Equals(p.Owner) {
+		return false
+	}
+	if status, ok := p.GetStatus(); ok && nft.Status != status {
+		return false
+	}
+	if p.Denom != "" && nft.Denom != p.Denom {
+		return false
+	}
+	if !p.MinPrice.Empty() && !nft.Price.IsAllGTE(p.MinPrice) {
+		return false
+	}
+	if !p.MaxPrice.Empty() && !p.MaxPrice.IsAllGTE(nft.Price) {
+		return false
+	}
+	return true
+}
+
+// Sort sorts the NFTs in the requested order, keeping the order of NFTs that are equal in it.
+// Prices are compared coin by coin in the order of denominations.
+func (p QueryNFTsParams) Sort(nfts []*NFT) {
+	switch p.SortBy {
+	case SortByPrice:
+		sort.SliceStable(nfts, func(i, j int) bool {
+			return lessPrice(nfts[i].Price, nfts[j].Price)
+		})
+	case SortByTimeCreated:
+		sort.SliceStable(nfts, func(i, j int) bool {
+			return nfts[i].TimeCreated.Before(nfts[j].TimeCreated)
+		})
+	}
+}
+
+// Paginate returns the requested page of the NFTs.
+func (p QueryNFTsParams) Paginate(nfts []*NFT) []*NFT {
+	// the page is checked against the number of pages before multiplying so that a large page cannot overflow
+	if p.Page-1 >= (len(nfts)+p.Limit-1)/p.Limit {
+		return nil
+	}
+	start := (p.Page - 1) * p.Limit
+	end := start + p.Limit
+	if end > len(nfts) {
+		end = len(nfts)
+	}
+	return nfts[start:end]
+}
+
+func lessPrice(a, b sdk.Coins) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Denom != b[i].Denom {
+			return a[i].Denom < b[i].Denom
+		}
+		if !a[i].Amount.Equal(b[i].Amount) {
+			return a[i].Amount.LT(b[i].Amount)
+		}
+	}
+	return len(a) < len(b)
+}
 
 type QueryResNFTs struct {
-	NFTs []*NFTInfo `json:"nfts"`
+	NFTs  []*NFTInfo `json:"nfts"`
+	Total int        `json:"total"` // number of NFTs matching the filters on all pages
 }
 
 func (r QueryResNFTs) String() string {
@@ -11,6 +158,7 @@ func (r QueryResNFTs) String() string {
 	for _, nft := range r.NFTs {
 		out = append(out, nft.String())
 	}
+	out = append(out, fmt.Sprintf("Total: %d", r.Total))
 
 	return strings.Join(out, "\n")
 }
@@ -40,3 +188,104 @@ func (r QueryResAuctionLots) String() string {
 
 	return strings.Join(out, "\n")
 }
+
+type QueryResAuctionBids struct {
+	Auction uint64        `json:"auction"` // number of the auction of the NFT the bids were made on
+	Bids    []*AuctionBid `json:"bids"`
+}
+
+func (r QueryResAuctionBids) String() string {
+	var out []string
+	for _, bid := range r.Bids {
+		out = append(out, bid.String())
+	}
+
+	return strings.Join(out, "\n")
+}
+
+type QueryResOffers struct {
+	Offers []*Offer `json:"offers"`
+}
+
+func (r QueryResOffers) String() string {
+	var out []string
+	for _, offer := range r.Offers {
+		out = append(out, offer.String())
+	}
+
+	return strings.Join(out, "\n")
+}
+
+type QueryResCollectionOffers struct {
+	Offers []*CollectionOffer `json:"offers"`
+}
+
+func (r QueryResCollectionOffers) String() string {
+	var out []string
+	for _, offer := range r.Offers {
+		out = append(out, offer.String())
+	}
+
+	return strings.Join(out, "\n")
+}
+
+type QueryResSwaps struct {
+	Swaps []*Swap `json:"swaps"`
+}
+
+func (r QueryResSwaps) String() string {
+	var out []string
+	for _, swap := range r.Swaps {
+		out = append(out, swap.String())
+	}
+
+	return strings.Join(out, "\n")
+}
+
+type QueryResRentals struct {
+	Rentals []*Rental `json:"rentals"`
+}
+
+func (r QueryResRentals) String() string {
+	var out []string
+	for _, rental := range r.Rentals {
+		out = append(out, rental.String())
+	}
+
+	return strings.Join(out, "\n")
+}
+
+type QueryResLoans struct {
+	Loans []*Loan `json:"loans"`
+}
+
+func (r QueryResLoans) String() string {
+	var out []string
+	for _, loan := range r.Loans {
+		out = append(out, loan.String())
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// QueryResNFTUser is the current user of an NFT, empty if the NFT is not rented.
+type QueryResNFTUser struct {
+	NFTID            string         `json:"nft_id"`
+	User             sdk.AccAddress `json:"user"`
+	ExpirationTime   time.Time      `json:"expiration_time"`
+	ExpirationHeight int64          `json:"expiration_height"`
+}
+
+func (r QueryResNFTUser) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`NFT: %s
+User: %s
+ExpirationTime: %v
+ExpirationHeight: %d`, r.NFTID, r.User, r.ExpirationTime, r.ExpirationHeight))
+}
+
+// SortCollectionOffers sorts the offers by price, highest first. Offers with equal prices keep their order.
+func SortCollectionOffers(offers []*CollectionOffer) {
+	sort.SliceStable(offers, func(i, j int) bool {
+		return lessPrice(offers[j].Price, offers[i].Price)
+	})
+}
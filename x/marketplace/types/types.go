@@ -1,7 +1,8 @@
 package types
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -30,25 +31,37 @@ type NFT struct {
 	Status            NFTStatus      `json:"status"`
 	SellerBeneficiary sdk.AccAddress `json:"seller_beneficiary"`
 	TimeCreated       time.Time      `json:"time_created"`
-	Offers            []*Offer       `json:"offers"`
+	Creator           sdk.AccAddress `json:"creator"`
+	Royalty           sdk.Dec        `json:"royalty"` // share of the price paid to the creator on every resale
+	// Items are the IDs of the NFTs a bundle holds, a bundle is sold as a single NFT and its items follow its owner.
+	Items []string `json:"items,omitempty"`
+	// User holds the usage rights of a rented NFT until the user expiration time or height, whichever is set.
+	User                 sdk.AccAddress `json:"user,omitempty"`
+	UserExpirationTime   time.Time      `json:"user_expiration_time"`
+	UserExpirationHeight int64          `json:"user_expiration_height,omitempty"`
+	// Offers are only set in records written before offers moved to their own store, see Keeper.MigrateOffers.
+	Offers []*Offer `json:"offers,omitempty"`
 }
 
-func NewNFT(id string, denom string, owner sdk.AccAddress, price sdk.Coins) *NFT {
+func NewNFT(id string, denom string, owner sdk.AccAddress, price sdk.Coins, timeCreated time.Time) *NFT {
 	return &NFT{
 		ID:          id,
 		Owner:       owner,
 		Denom:       denom,
 		Price:       price,
-		TimeCreated: time.Now().UTC(),
+		TimeCreated: timeCreated,
+		Royalty:     sdk.ZeroDec(),
 	}
 }
 
+// NewBundle returns a bundle of the given NFTs. A bundle has no counterpart in the nft module.
+func NewBundle(id string, owner sdk.AccAddress, items []string, timeCreated time.Time) *NFT {
+	bundle := NewNFT(id, "", owner, sdk.Coins{}, timeCreated)
+	bundle.Items = items
+	return bundle
+}
+
 func (m NFT) String() string {
-	var offers []string
-	for _, offer := range m.Offers {
-		offerJSON, _ := json.Marshal(offer)
-		offers = append(offers, string(offerJSON))
-	}
 	return strings.TrimSpace(fmt.Sprintf(`ID: %s
 Owner: %s
 Denom: %s
@@ -56,7 +69,11 @@ Price: %s
 Status: %v
 SellerBeneficiary: %s
 TimeCreated: %v
-Offers: %v`, m.ID, m.Owner, m.Denom, m.Price, m.Status, m.SellerBeneficiary, m.TimeCreated, offers))
+Creator: %s
+Royalty: %s
+Items: %v
+User: %s`, m.ID, m.Owner, m.Denom, m.Price, m.Status, m.SellerBeneficiary, m.TimeCreated,
+		m.Creator, m.GetRoyalty(), m.Items, m.User))
 }
 
 func (m *NFT) GetPrice() sdk.Coins {
@@ -79,10 +96,40 @@ func (m *NFT) IsOnAuction() bool {
 	return m.Status == NFTStatusOnAuction
 }
 
+// IsBundle reports whether the NFT is a bundle of other NFTs.
+func (m *NFT) IsBundle() bool {
+	return len(m.Items) > 0
+}
+
+// IsInBundle reports whether the NFT is an item of a bundle, it can only be traded as a part of the bundle.
+func (m *NFT) IsInBundle() bool {
+	return m.Status == NFTStatusInBundle
+}
+
+// IsLocked reports whether the NFT is held by a bundle, a swap, a hash lock or a loan or is rented,
+// it cannot be sold, transferred or burnt.
+func (m *NFT) IsLocked() bool {
+	return m.Status == NFTStatusInBundle || m.Status == NFTStatusInSwap || m.Status == NFTStatusHashLocked ||
+		m.Status == NFTStatusRented || m.Status == NFTStatusCollateral
+}
+
+// IsRented reports whether the usage rights of the NFT belong to a user other than its owner.
+func (m *NFT) IsRented() bool {
+	return m.Status == NFTStatusRented
+}
+
 func (m *NFT) IsActive() bool {
 	return m.Status == NFTStatusDefault || m.Status == NFTStatusOnMarket || m.Status == NFTStatusOnAuction
 }
 
+// GetRoyalty returns the royalty rate of the NFT, zero if it has no creator.
+func (m *NFT) GetRoyalty() sdk.Dec {
+	if m.Creator.Empty() || m.Royalty.IsNil() {
+		return sdk.ZeroDec()
+	}
+	return m.Royalty
+}
+
 func (m *NFT) SetStatus(status NFTStatus) {
 	m.Status = status
 }
@@ -95,61 +142,288 @@ func (m *NFT) GetTimeCreated() time.Time {
 	return m.TimeCreated
 }
 
-func (m *NFT) AddOffer(offer *Offer) {
-	m.Offers = append(m.Offers, offer)
+type Offer struct {
+	ID                    string         `json:"id"`
+	NFTID                 string         `json:"nft_id"`
+	Buyer                 sdk.AccAddress `json:"buyer"`
+	Price                 sdk.Coins      `json:"price"`
+	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
+	// the offer is refunded at the expiration time or height, if set
+	ExpirationTime   time.Time `json:"expiration_time"`
+	ExpirationHeight int64     `json:"expiration_height"`
 }
 
-func (m *NFT) GetOffer(id string) (*Offer, bool) {
-	for _, offer := range m.Offers {
-		offer := offer
-		if offer.ID == id {
-			return offer, true
-		}
+func (o Offer) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`ID: %s
+NFT: %s
+Buyer: %s
+Price: %v
+BuyerBeneficiary: %s
+BeneficiaryCommission: %s
+ExpirationTime: %v
+ExpirationHeight: %d`, o.ID, o.NFTID, o.Buyer, o.Price, o.BuyerBeneficiary, o.BeneficiaryCommission,
+		o.ExpirationTime, o.ExpirationHeight))
+}
+
+// IsExpired reports whether the offer has expired by the given block time and height.
+func (o *Offer) IsExpired(blockTime time.Time, height int64) bool {
+	return (!o.ExpirationTime.IsZero() && !blockTime.Before(o.ExpirationTime)) ||
+		(o.ExpirationHeight > 0 && height >= o.ExpirationHeight)
+}
+
+// CollectionOffer is an offer of the price for each of Quantity NFTs of the denom, filled by any of their owners.
+type CollectionOffer struct {
+	ID                    string         `json:"id"`
+	Denom                 string         `json:"denom"`
+	Buyer                 sdk.AccAddress `json:"buyer"`
+	Price                 sdk.Coins      `json:"price"`    // price of a single NFT
+	Quantity              uint64         `json:"quantity"` // number of NFTs still wanted
+	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
+	TimeCreated           time.Time      `json:"time_created"`
+}
+
+func (o CollectionOffer) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`ID: %s
+Denom: %s
+Buyer: %s
+Price: %v
+Quantity: %d
+BuyerBeneficiary: %s
+BeneficiaryCommission: %s
+TimeCreated: %v`, o.ID, o.Denom, o.Buyer, o.Price, o.Quantity, o.BuyerBeneficiary, o.BeneficiaryCommission,
+		o.TimeCreated))
+}
+
+// GetLocked returns the coins escrowed for the NFTs still wanted.
+func (o *CollectionOffer) GetLocked() sdk.Coins {
+	return MulCoins(o.Price, o.Quantity)
+}
+
+// MulCoins returns the coins multiplied by n.
+func MulCoins(coins sdk.Coins, n uint64) sdk.Coins {
+	out := sdk.Coins{}
+	for _, coin := range coins {
+		out = out.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, coin.Amount.MulRaw(int64(n)))))
 	}
+	return out
+}
+
+// Swap is a proposal to exchange NFTs and coins of the proposer for NFTs and coins of the counterparty.
+// The NFTs and coins of the proposer are held by the marketplace until the swap is accepted, cancelled or expires.
+type Swap struct {
+	ID                string         `json:"id"`
+	Proposer          sdk.AccAddress `json:"proposer"`
+	Counterparty      sdk.AccAddress `json:"counterparty"`
+	ProposerNFTs      []string       `json:"proposer_nfts"`
+	ProposerCoins     sdk.Coins      `json:"proposer_coins"`
+	CounterpartyNFTs  []string       `json:"counterparty_nfts"`
+	CounterpartyCoins sdk.Coins      `json:"counterparty_coins"`
+	// the swap is cancelled at the expiration time, if set
+	ExpirationTime time.Time `json:"expiration_time"`
+	TimeCreated    time.Time `json:"time_created"`
+}
 
-	return nil, false
+func (s Swap) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`ID: %s
+Proposer: %s
+Counterparty: %s
+ProposerNFTs: %v
+ProposerCoins: %v
+CounterpartyNFTs: %v
+CounterpartyCoins: %v
+ExpirationTime: %v
+TimeCreated: %v`, s.ID, s.Proposer, s.Counterparty, s.ProposerNFTs, s.ProposerCoins, s.CounterpartyNFTs,
+		s.CounterpartyCoins, s.ExpirationTime, s.TimeCreated))
 }
 
-func (m *NFT) RemoveOffer(offerID string, buyer sdk.AccAddress) bool {
-	for k, offer := range m.Offers {
-		k, offer := k, offer
-		if offer.ID == offerID && offer.Buyer.Equals(buyer) {
-			// this is common sliceTrick for slice of pointers
-			// needed for avoid memory leak
-			if k < len(m.Offers)-1 {
-				copy(m.Offers[k:], m.Offers[k+1:])
-			}
-			m.Offers[len(m.Offers)-1] = nil
-			m.Offers = m.Offers[:len(m.Offers)-1]
-			return true
-		}
+// IsExpired reports whether the swap has expired by the given block time.
+func (s *Swap) IsExpired(blockTime time.Time) bool {
+	return !s.ExpirationTime.IsZero() && !blockTime.Before(s.ExpirationTime)
+}
+
+// HashLock locks an NFT for a hash time-locked transfer: the recipient claims the NFT by revealing
+// the preimage of the hash lock before the timeout, after the timeout the owner reclaims it.
+type HashLock struct {
+	NFTID       string         `json:"nft_id"`
+	Owner       sdk.AccAddress `json:"owner"`
+	Recipient   sdk.AccAddress `json:"recipient"`
+	HashLock    string         `json:"hash_lock"` // hex encoded SHA-256 hash of the preimage
+	Timeout     time.Time      `json:"timeout"`
+	TimeCreated time.Time      `json:"time_created"`
+}
+
+func (l HashLock) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`NFT: %s
+Owner: %s
+Recipient: %s
+HashLock: %s
+Timeout: %v
+TimeCreated: %v`, l.NFTID, l.Owner, l.Recipient, l.HashLock, l.Timeout, l.TimeCreated))
+}
+
+// IsTimedOut reports whether the lock has timed out by the given block time.
+func (l *HashLock) IsTimedOut(blockTime time.Time) bool {
+	return !blockTime.Before(l.Timeout)
+}
+
+// PreimageHash returns the hash lock of a hex encoded preimage: the hex encoded SHA-256 hash of its bytes.
+func PreimageHash(preimage string) (string, error) {
+	bz, err := hex.DecodeString(preimage)
+	if err != nil {
+		return "", fmt.Errorf("preimage must be hex encoded: %v", err)
 	}
+	hash := sha256.Sum256(bz)
+	return hex.EncodeToString(hash[:]), nil
+}
 
-	return false
+// rental price units, a rental listing is priced per block or per hour
+const (
+	RentalUnitBlock = "block"
+	RentalUnitHour  = "hour"
+)
+
+// RentalListing offers the usage rights of an NFT for rent, its owner keeps the ownership.
+type RentalListing struct {
+	NFTID       string         `json:"nft_id"`
+	Owner       sdk.AccAddress `json:"owner"`
+	Price       sdk.Coins      `json:"price"` // price per unit of the rental duration
+	Unit        string         `json:"unit"`  // RentalUnitBlock or RentalUnitHour
+	MaxDuration uint64         `json:"max_duration"`
+	TimeCreated time.Time      `json:"time_created"`
 }
 
-type Offer struct {
-	ID                    string         `json:"id"`
-	Buyer                 sdk.AccAddress `json:"buyer"`
-	Price                 sdk.Coins      `json:"price"`
-	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
-	BeneficiaryCommission string         `json:"beneficiary_commission"`
+func (l RentalListing) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`NFT: %s
+Owner: %s
+Price: %s per %s
+MaxDuration: %d
+TimeCreated: %v`, l.NFTID, l.Owner, l.Price, l.Unit, l.MaxDuration, l.TimeCreated))
+}
+
+// Rental is an entry of the rental history of an NFT.
+type Rental struct {
+	NFTID            string         `json:"nft_id"`
+	Owner            sdk.AccAddress `json:"owner"`
+	User             sdk.AccAddress `json:"user"`
+	Price            sdk.Coins      `json:"price"` // total price paid by the user
+	Duration         uint64         `json:"duration"`
+	Unit             string         `json:"unit"`
+	StartTime        time.Time      `json:"start_time"`
+	StartHeight      int64          `json:"start_height"`
+	ExpirationTime   time.Time      `json:"expiration_time"`
+	ExpirationHeight int64          `json:"expiration_height"`
+}
+
+func (r Rental) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`NFT: %s
+Owner: %s
+User: %s
+Price: %s
+Duration: %d %s
+StartTime: %v
+StartHeight: %d
+ExpirationTime: %v
+ExpirationHeight: %d`, r.NFTID, r.Owner, r.User, r.Price, r.Duration, r.Unit, r.StartTime, r.StartHeight,
+		r.ExpirationTime, r.ExpirationHeight))
+}
+
+// IsValidRentalUnit reports whether the unit is a known rental price unit.
+func IsValidRentalUnit(unit string) bool {
+	return unit == RentalUnitBlock || unit == RentalUnitHour
+}
+
+// Loan is a loan backed by an NFT of the borrower held as collateral. A lender funds the principal,
+// the borrower gets the NFT back by repaying the principal and the interest before the deadline,
+// after the deadline the lender can claim the NFT.
+type Loan struct {
+	ID          string         `json:"id"`
+	Borrower    sdk.AccAddress `json:"borrower"`
+	Lender      sdk.AccAddress `json:"lender"` // empty until the loan is funded
+	NFTID       string         `json:"nft_id"`
+	Principal   sdk.Coins      `json:"principal"`
+	Interest    sdk.Coins      `json:"interest"`
+	Duration    time.Duration  `json:"duration"` // the deadline is the duration after the loan is funded
+	Deadline    time.Time      `json:"deadline"`
+	TimeCreated time.Time      `json:"time_created"`
+}
+
+func (l Loan) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`ID: %s
+Borrower: %s
+Lender: %s
+NFT: %s
+Principal: %s
+Interest: %s
+Duration: %v
+Deadline: %v
+TimeCreated: %v`, l.ID, l.Borrower, l.Lender, l.NFTID, l.Principal, l.Interest, l.Duration, l.Deadline,
+		l.TimeCreated))
+}
+
+// IsFunded reports whether a lender has funded the loan.
+func (l *Loan) IsFunded() bool {
+	return !l.Lender.Empty()
+}
+
+// IsDue reports whether the deadline of a funded loan has passed by the given block time.
+func (l *Loan) IsDue(blockTime time.Time) bool {
+	return l.IsFunded() && !blockTime.Before(l.Deadline)
+}
+
+// GetRepayment returns the coins the borrower repays: the principal and the interest.
+func (l *Loan) GetRepayment() sdk.Coins {
+	return l.Principal.Add(l.Interest)
 }
 
 type AuctionBid struct {
 	Bidder                sdk.AccAddress `json:"bidder"`            // account address that made the bid
 	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"` // account address that will be the beneficiary of the purchase
-	BeneficiaryCommission string         `json:"beneficiary_commission"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
 	Bid                   sdk.Coins      `json:"bid"`
 	TimeCreated           time.Time      `json:"time_created"`
 }
 
+// AuctionType is the way the winner of an auction and the price are determined
+type AuctionType string
+
+const (
+	// the highest bid at expiry wins, the lot can be bought out at the buyout price
+	AuctionTypeEnglish AuctionType = "english"
+	// the price falls from the opening price to the floor price, the first buyer pays the current price
+	AuctionTypeDutch AuctionType = "dutch"
+	// bids are committed as hashes and revealed after bidding closes, the highest revealed bid wins
+	AuctionTypeSealed AuctionType = "sealed"
+)
+
 type AuctionLot struct {
 	NFTID          string      `json:"nft_id"`
 	LastBid        *AuctionBid `json:"last_bid"`
 	OpeningPrice   sdk.Coins   `json:"opening_price"`
 	BuyoutPrice    sdk.Coins   `json:"buyout_price"`
 	ExpirationTime time.Time   `json:"expiration_time"`
+	StartTime      time.Time   `json:"start_time"`
+	// English if empty
+	AuctionType AuctionType `json:"auction_type"`
+	// Dutch auction only: the price at expiry, and how often the price falls, continuously if zero
+	FloorPrice sdk.Coins     `json:"floor_price"`
+	PriceStep  time.Duration `json:"price_step"`
+	// sealed-bid auction only: the deposit locked with every commitment, the end of the reveal window
+	// which starts at expiry, and whether the winner pays the second highest bid
+	BidDeposit    sdk.Coins `json:"bid_deposit"`
+	RevealEndTime time.Time `json:"reveal_end_time"`
+	SecondPrice   bool      `json:"second_price"`
+	// English auction only: the minimum raise over the last bid, absolute or as a share of the last bid,
+	// and how close to expiry a bid pushes the expiration time forward to the time of the bid plus the window
+	MinBidIncrement     sdk.Coins     `json:"min_bid_increment"`
+	MinBidIncrementRate sdk.Dec       `json:"min_bid_increment_rate"`
+	ExtensionWindow     time.Duration `json:"extension_window"`
+	// English auction only: ReservePriceHash of the reserve price and a salt chosen by the owner, the owner
+	// reveals the reserve price until RevealEndTime, an unrevealed reserve price counts as met
+	ReserveHash string `json:"reserve_hash"`
+	// number of the auction among the auctions of the NFT, starting from 1, its bid history is kept under it
+	Auction uint64 `json:"auction"`
 }
 
 func NewAuctionLot(id string, openingPrice, buyoutPrice sdk.Coins, expTime time.Time) *AuctionLot {
@@ -158,19 +432,144 @@ func NewAuctionLot(id string, openingPrice, buyoutPrice sdk.Coins, expTime time.
 		OpeningPrice:   openingPrice,
 		BuyoutPrice:    buyoutPrice,
 		ExpirationTime: expTime,
+		AuctionType:    AuctionTypeEnglish,
+	}
+}
+
+func NewDutchAuctionLot(id string, openingPrice, floorPrice sdk.Coins, priceStep time.Duration, expTime time.Time) *AuctionLot {
+	return &AuctionLot{
+		NFTID:          id,
+		OpeningPrice:   openingPrice,
+		ExpirationTime: expTime,
+		AuctionType:    AuctionTypeDutch,
+		FloorPrice:     floorPrice,
+		PriceStep:      priceStep,
+	}
+}
+
+func NewSealedAuctionLot(id string, openingPrice, bidDeposit sdk.Coins, secondPrice bool,
+	expTime, revealEndTime time.Time) *AuctionLot {
+	return &AuctionLot{
+		NFTID:          id,
+		OpeningPrice:   openingPrice,
+		ExpirationTime: expTime,
+		AuctionType:    AuctionTypeSealed,
+		BidDeposit:     bidDeposit,
+		RevealEndTime:  revealEndTime,
+		SecondPrice:    secondPrice,
+	}
+}
+
+func (lot *AuctionLot) IsDutch() bool {
+	return lot.AuctionType == AuctionTypeDutch
+}
+
+func (lot *AuctionLot) IsSealed() bool {
+	return lot.AuctionType == AuctionTypeSealed
+}
+
+func (lot *AuctionLot) HasReserve() bool {
+	return lot.ReserveHash != ""
+}
+
+// SettlementTime returns the time the lot is settled at by the end blocker: the end of the reveal window
+// for sealed-bid auctions and auctions with a reserve price, the expiration time otherwise.
+func (lot *AuctionLot) SettlementTime() time.Time {
+	if lot.IsSealed() || lot.HasReserve() {
+		return lot.RevealEndTime
+	}
+	return lot.ExpirationTime
+}
+
+// CurrentPrice returns the price of a Dutch auction lot at the given time. The price falls linearly
+// from the opening price at the start of the auction to the floor price at expiry, or in steps of
+// PriceStep if it is set. Amounts are rounded up, so the price never falls below the floor price.
+// For other lots it returns the last bid, or the opening price if there are no bids yet.
+func (lot *AuctionLot) CurrentPrice(now time.Time) sdk.Coins {
+	if !lot.IsDutch() {
+		if lot.LastBid != nil {
+			return lot.LastBid.Bid
+		}
+		return lot.OpeningPrice
+	}
+
+	duration := lot.ExpirationTime.Sub(lot.StartTime)
+	elapsed := now.Sub(lot.StartTime)
+	switch {
+	case elapsed <= 0 || duration <= 0:
+		return lot.OpeningPrice
+	case elapsed >= duration:
+		return lot.FloorPrice
+	}
+	if lot.PriceStep > 0 {
+		elapsed -= elapsed % lot.PriceStep
+	}
+
+	price := sdk.NewCoins()
+	for _, coin := range lot.OpeningPrice {
+		fall := coin.Amount.Sub(lot.FloorPrice.AmountOf(coin.Denom)).MulRaw(int64(elapsed)).QuoRaw(int64(duration))
+		price = price.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, coin.Amount.Sub(fall))))
+	}
+	return price
+}
+
+// MinNextBid returns the lowest bid the lot accepts: the opening price if there are no bids yet,
+// otherwise the last bid raised by the minimum increment. A share of the last bid is rounded up.
+func (lot *AuctionLot) MinNextBid() sdk.Coins {
+	if lot.LastBid == nil {
+		return lot.OpeningPrice
+	}
+	return lot.LastBid.Bid.Add(lot.minBidIncrement(lot.LastBid.Bid))
+}
+
+// MinBidOver returns the lowest amount a proxy bid raises to when outbidding the given bid: the bid
+// raised by the minimum increment, and by at least one unit in every denom.
+func (lot *AuctionLot) MinBidOver(bid sdk.Coins) sdk.Coins {
+	minBid := bid.Add(lot.minBidIncrement(bid))
+	for _, coin := range bid {
+		if minBid.AmountOf(coin.Denom).Equal(coin.Amount) {
+			minBid = minBid.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, sdk.OneInt())))
+		}
+	}
+	return minBid
+}
+
+func (lot *AuctionLot) minBidIncrement(bid sdk.Coins) sdk.Coins {
+	increment := lot.MinBidIncrement
+	if !lot.MinBidIncrementRate.IsNil() && lot.MinBidIncrementRate.IsPositive() {
+		for _, coin := range bid {
+			amount := sdk.NewDecFromInt(coin.Amount).Mul(lot.MinBidIncrementRate).Ceil().TruncateInt()
+			increment = increment.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, amount)))
+		}
+	}
+	return increment
+}
+
+// ExtendedExpirationTime returns the expiration time of the lot after a bid at the given time:
+// a bid within the extension window before expiry moves the expiry to the end of the window.
+func (lot *AuctionLot) ExtendedExpirationTime(bidTime time.Time) time.Time {
+	if lot.ExtensionWindow > 0 && bidTime.Add(lot.ExtensionWindow).After(lot.ExpirationTime) {
+		return bidTime.Add(lot.ExtensionWindow)
 	}
+	return lot.ExpirationTime
 }
 
-func NewAuctionBid(bidder, beneficiary sdk.AccAddress, price sdk.Coins, commission string) *AuctionBid {
+func NewAuctionBid(bidder, beneficiary sdk.AccAddress, price sdk.Coins, commission sdk.Dec, timeCreated time.Time) *AuctionBid {
 	return &AuctionBid{
 		Bidder:                bidder,
 		BuyerBeneficiary:      beneficiary,
 		Bid:                   price,
-		TimeCreated:           time.Now().UTC(),
+		TimeCreated:           timeCreated,
 		BeneficiaryCommission: commission,
 	}
 }
 
+func (b AuctionBid) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`Bidder: %s
+Bid: %v
+TimeCreated: %v`, b.Bidder, b.Bid, b.TimeCreated))
+}
+
 func (lot *AuctionLot) SetLastBid(bid *AuctionBid) {
 	lot.LastBid = bid
 }
@@ -181,6 +580,27 @@ OpeningPrice: %v
 ExpirationTime: %v
 `, lot.NFTID, lot.OpeningPrice, lot.ExpirationTime))
 
+	if lot.IsDutch() {
+		base += fmt.Sprintf("\nAuctionType: %s\nFloorPrice: %v\nPriceStep: %v\n", lot.AuctionType, lot.FloorPrice, lot.PriceStep)
+	}
+	if lot.IsSealed() {
+		base += fmt.Sprintf("\nAuctionType: %s\nBidDeposit: %v\nRevealEndTime: %v\nSecondPrice: %t\n",
+			lot.AuctionType, lot.BidDeposit, lot.RevealEndTime, lot.SecondPrice)
+	}
+
+	if !lot.MinBidIncrement.Empty() {
+		base += fmt.Sprintf("\nMinBidIncrement: %v\n", lot.MinBidIncrement)
+	}
+	if !lot.MinBidIncrementRate.IsNil() && lot.MinBidIncrementRate.IsPositive() {
+		base += fmt.Sprintf("\nMinBidIncrementRate: %v\n", lot.MinBidIncrementRate)
+	}
+	if lot.ExtensionWindow > 0 {
+		base += fmt.Sprintf("\nExtensionWindow: %v\n", lot.ExtensionWindow)
+	}
+	if lot.HasReserve() {
+		base += fmt.Sprintf("\nReserveHash: %s\nRevealEndTime: %v\n", lot.ReserveHash, lot.RevealEndTime)
+	}
+
 	if lot.BuyoutPrice.IsZero() {
 		base += strings.TrimSpace(fmt.Sprintf(`
 BuyoutPrice: %v`, nil))
@@ -198,6 +618,58 @@ TimeOfLastBid: %v`, lot.LastBid.Bid, lot.LastBid.TimeCreated))
 	return base
 }
 
+// SealedBid is a commitment to a bid on a sealed-bid auction. The bid is unknown until it is revealed.
+type SealedBid struct {
+	NFTID                 string         `json:"nft_id"`
+	Bidder                sdk.AccAddress `json:"bidder"`
+	BuyerBeneficiary      sdk.AccAddress `json:"buyer_beneficiary"`
+	BeneficiaryCommission sdk.Dec        `json:"beneficiary_commission"`
+	BidHash               string         `json:"bid_hash"`
+	Deposit               sdk.Coins      `json:"deposit"`
+	Bid                   sdk.Coins      `json:"bid"` // empty until revealed
+	TimeCreated           time.Time      `json:"time_created"`
+}
+
+func NewSealedBid(id string, bidder, beneficiary sdk.AccAddress, commission sdk.Dec, bidHash string,
+	deposit sdk.Coins, timeCreated time.Time) *SealedBid {
+	return &SealedBid{
+		NFTID:                 id,
+		Bidder:                bidder,
+		BuyerBeneficiary:      beneficiary,
+		BeneficiaryCommission: commission,
+		BidHash:               bidHash,
+		Deposit:               deposit,
+		TimeCreated:           timeCreated,
+	}
+}
+
+func (b *SealedBid) IsRevealed() bool {
+	return !b.Bid.Empty()
+}
+
+func (b SealedBid) String() string {
+	return strings.TrimSpace(fmt.Sprintf(`NFT: %s
+Bidder: %s
+BidHash: %s
+Deposit: %v
+Bid: %v
+TimeCreated: %v`, b.NFTID, b.Bidder, b.BidHash, b.Deposit, b.Bid, b.TimeCreated))
+}
+
+// SealedBidHash returns the commitment to a sealed bid: the hex encoded SHA-256 hash of the bidder,
+// the bid and a salt chosen by the bidder. The bidder is hashed so that commitments cannot be copied.
+func SealedBidHash(bidder sdk.AccAddress, bid sdk.Coins, salt string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", bidder, bid, salt)))
+	return hex.EncodeToString(hash[:])
+}
+
+// ReservePriceHash returns the commitment to the reserve price of an auction: the hex encoded SHA-256 hash
+// of the reserve price and a salt chosen by the owner.
+func ReservePriceHash(reserve sdk.Coins, salt string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s", reserve, salt)))
+	return hex.EncodeToString(hash[:])
+}
+
 // copy of data got from exported/nft interface
 type NFTMetaData struct {
 	ID       string         `json:"id"`